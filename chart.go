@@ -0,0 +1,420 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ChartType identifies the kind of DrawingML chart created by AddChart.
+type ChartType string
+
+const (
+	ChartTypeBar    ChartType = "bar"    // horizontal bars
+	ChartTypeColumn ChartType = "column" // vertical bars
+	ChartTypeLine   ChartType = "line"
+	ChartTypePie    ChartType = "pie"
+)
+
+const (
+	// default chart extent used by Word for new charts (5.76 x 3.2 inches)
+	defaultChartWidthEMU  = 5486400
+	defaultChartHeightEMU = 3200400
+
+	drawingMLChartNamespace = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+)
+
+// ChartSeries is one named series of values, one value per category.
+type ChartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// ChartData holds the categories and series plotted by a chart. Pie charts plot the
+// first series only.
+type ChartData struct {
+	Title      string
+	Categories []string
+	Series     []ChartSeries
+}
+
+// Chart represents an inline DrawingML chart embedded in a run. The chart part and its
+// embedded workbook are written when the chart is created.
+type Chart struct {
+	docPart   *DocumentPart
+	relID     string
+	target    string
+	chartType ChartType
+	data      ChartData
+	widthEMU  int64
+	heightEMU int64
+	docPrID   int
+	name      string
+}
+
+// Type returns the chart type.
+func (c *Chart) Type() ChartType {
+	return c.chartType
+}
+
+// Data returns the categories and series plotted by the chart.
+func (c *Chart) Data() ChartData {
+	return c.data
+}
+
+// WidthEMU returns the chart width in English Metric Units (EMUs).
+func (c *Chart) WidthEMU() int64 {
+	return c.widthEMU
+}
+
+// HeightEMU returns the chart height in English Metric Units (EMUs).
+func (c *Chart) HeightEMU() int64 {
+	return c.heightEMU
+}
+
+// SetSize updates the chart dimensions in EMUs. Non-positive values are ignored.
+func (c *Chart) SetSize(widthEMU, heightEMU int64) {
+	if widthEMU > 0 {
+		c.widthEMU = widthEMU
+	}
+	if heightEMU > 0 {
+		c.heightEMU = heightEMU
+	}
+}
+
+// RelationshipID returns the relationship ID referencing the chart part.
+func (c *Chart) RelationshipID() string {
+	return c.relID
+}
+
+// Target returns the relationship target (typically charts/chartX.xml).
+func (c *Chart) Target() string {
+	return c.target
+}
+
+// Name returns the docPr name for this chart.
+func (c *Chart) Name() string {
+	return c.name
+}
+
+func validateChartData(chartType ChartType, data ChartData) error {
+	switch chartType {
+	case ChartTypeBar, ChartTypeColumn, ChartTypeLine, ChartTypePie:
+	default:
+		return fmt.Errorf("%w: unsupported chart type %q", ErrInvalidArgument, chartType)
+	}
+	if len(data.Categories) == 0 {
+		return fmt.Errorf("%w: chart data must have at least one category", ErrInvalidArgument)
+	}
+	if len(data.Series) == 0 {
+		return fmt.Errorf("%w: chart data must have at least one series", ErrInvalidArgument)
+	}
+	for i, series := range data.Series {
+		if len(series.Values) != len(data.Categories) {
+			return fmt.Errorf("%w: chart series %d has %d values for %d categories", ErrInvalidArgument, i, len(series.Values), len(data.Categories))
+		}
+	}
+	return nil
+}
+
+func (dp *DocumentPart) addChart(chartType ChartType, data ChartData) (*Chart, error) {
+	if dp == nil || dp.pkg == nil {
+		return nil, fmt.Errorf("%w: paragraph is not attached to a document package", ErrNotAttached)
+	}
+	if err := validateChartData(chartType, data); err != nil {
+		return nil, err
+	}
+
+	workbook, err := chartWorkbook(chartType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build chart workbook: %w", err)
+	}
+
+	chartURI, workbookURI := dp.pkg.nextChartPartNames()
+	dp.pkg.parts[workbookURI] = &Part{URI: workbookURI, ContentType: ContentTypeSpreadsheet, Data: workbook}
+	dp.pkg.contentTypes["/"+workbookURI] = ContentTypeSpreadsheet
+
+	workbookRelID := dp.pkg.ensureRelationship(chartURI, RelTypePackage, "../embeddings/"+path.Base(workbookURI))
+	dp.pkg.parts[chartURI] = &Part{URI: chartURI, ContentType: ContentTypeDrawingMLChart, Data: chartSpaceXML(chartType, data, workbookRelID)}
+	dp.pkg.contentTypes["/"+chartURI] = ContentTypeDrawingMLChart
+
+	target := strings.TrimPrefix(chartURI, "word/")
+	relID := dp.pkg.ensureRelationship(dp.Part.URI, RelTypeChart, target)
+	docPrID := dp.nextDrawingID()
+	return &Chart{
+		docPart:   dp,
+		relID:     relID,
+		target:    target,
+		chartType: chartType,
+		data:      data,
+		widthEMU:  defaultChartWidthEMU,
+		heightEMU: defaultChartHeightEMU,
+		docPrID:   docPrID,
+		name:      fmt.Sprintf("Chart %d", docPrID),
+	}, nil
+}
+
+// nextChartPartNames returns unused URIs for a chart part and its embedded workbook
+func (p *Package) nextChartPartNames() (string, string) {
+	for n := 1; ; n++ {
+		chartURI := fmt.Sprintf("word/charts/chart%d.xml", n)
+		workbookURI := fmt.Sprintf("word/embeddings/Microsoft_Excel_Worksheet%d.xlsx", n)
+		if _, exists := p.parts[chartURI]; exists {
+			continue
+		}
+		if _, exists := p.parts[workbookURI]; exists {
+			continue
+		}
+		return chartURI, workbookURI
+	}
+}
+
+func (c *Chart) toXML() string {
+	if c == nil {
+		return ""
+	}
+	name := c.name
+	if name == "" {
+		name = fmt.Sprintf("Chart %d", c.docPrID)
+	}
+	var builder strings.Builder
+	builder.WriteString(`<w:drawing>`)
+	builder.WriteString(`<wp:inline xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" distT="0" distB="0" distL="0" distR="0">`)
+	builder.WriteString(fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, c.widthEMU, c.heightEMU))
+	builder.WriteString(fmt.Sprintf(`<wp:docPr id="%d" name="%s"/>`, c.docPrID, escapeXML(name)))
+	builder.WriteString(`<wp:cNvGraphicFramePr/>`)
+	builder.WriteString(`<a:graphic>`)
+	builder.WriteString(`<a:graphicData uri="` + drawingMLChartNamespace + `">`)
+	builder.WriteString(`<c:chart r:id="` + escapeXML(c.relID) + `"/>`)
+	builder.WriteString(`</a:graphicData>`)
+	builder.WriteString(`</a:graphic>`)
+	builder.WriteString(`</wp:inline>`)
+	builder.WriteString(`</w:drawing>`)
+	return builder.String()
+}
+
+// chartSpaceXML renders the chart part. Series reference the embedded workbook laid out as
+// categories in column A and one series per following column, names in row 1.
+func chartSpaceXML(chartType ChartType, data ChartData, workbookRelID string) []byte {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	b.WriteString(`<c:roundedCorners val="0"/>`)
+	b.WriteString(`<c:chart>`)
+	if data.Title != "" {
+		b.WriteString(`<c:title><c:tx><c:rich><a:bodyPr/><a:p><a:r><a:t>` + escapeXML(data.Title) + `</a:t></a:r></a:p></c:rich></c:tx><c:overlay val="0"/></c:title>`)
+		b.WriteString(`<c:autoTitleDeleted val="0"/>`)
+	} else {
+		b.WriteString(`<c:autoTitleDeleted val="1"/>`)
+	}
+	b.WriteString(`<c:plotArea><c:layout/>`)
+
+	series := data.Series
+	switch chartType {
+	case ChartTypeBar, ChartTypeColumn:
+		barDir := "col"
+		if chartType == ChartTypeBar {
+			barDir = "bar"
+		}
+		b.WriteString(`<c:barChart><c:barDir val="` + barDir + `"/><c:grouping val="clustered"/><c:varyColors val="0"/>`)
+		writeChartSeries(&b, chartType, data.Categories, series)
+		b.WriteString(`<c:axId val="1"/><c:axId val="2"/></c:barChart>`)
+	case ChartTypeLine:
+		b.WriteString(`<c:lineChart><c:grouping val="standard"/><c:varyColors val="0"/>`)
+		writeChartSeries(&b, chartType, data.Categories, series)
+		b.WriteString(`<c:marker val="1"/><c:axId val="1"/><c:axId val="2"/></c:lineChart>`)
+	case ChartTypePie:
+		b.WriteString(`<c:pieChart><c:varyColors val="1"/>`)
+		writeChartSeries(&b, chartType, data.Categories, series[:1])
+		b.WriteString(`<c:firstSliceAng val="0"/></c:pieChart>`)
+	}
+
+	if chartType != ChartTypePie {
+		catPos, valPos := "b", "l"
+		if chartType == ChartTypeBar {
+			catPos, valPos = "l", "b"
+		}
+		b.WriteString(`<c:catAx><c:axId val="1"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="` + catPos + `"/><c:crossAx val="2"/></c:catAx>`)
+		b.WriteString(`<c:valAx><c:axId val="2"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="` + valPos + `"/><c:majorGridlines/><c:crossAx val="1"/></c:valAx>`)
+	}
+	b.WriteString(`</c:plotArea>`)
+	b.WriteString(`<c:legend><c:legendPos val="r"/><c:overlay val="0"/></c:legend>`)
+	b.WriteString(`<c:plotVisOnly val="1"/>`)
+	b.WriteString(`</c:chart>`)
+	b.WriteString(`<c:externalData r:id="` + workbookRelID + `"><c:autoUpdate val="0"/></c:externalData>`)
+	b.WriteString(`</c:chartSpace>`)
+	return []byte(b.String())
+}
+
+func writeChartSeries(b *strings.Builder, chartType ChartType, categories []string, series []ChartSeries) {
+	lastRow := len(categories) + 1
+	for i, s := range series {
+		column := spreadsheetColumnName(i + 1)
+		b.WriteString(fmt.Sprintf(`<c:ser><c:idx val="%d"/><c:order val="%d"/>`, i, i))
+		b.WriteString(fmt.Sprintf(`<c:tx><c:strRef><c:f>Sheet1!$%s$1</c:f><c:strCache><c:ptCount val="1"/><c:pt idx="0"><c:v>%s</c:v></c:pt></c:strCache></c:strRef></c:tx>`, column, escapeXML(s.Name)))
+		switch chartType {
+		case ChartTypeBar, ChartTypeColumn:
+			b.WriteString(`<c:invertIfNegative val="0"/>`)
+		case ChartTypeLine:
+			b.WriteString(`<c:marker><c:symbol val="none"/></c:marker>`)
+		}
+		b.WriteString(fmt.Sprintf(`<c:cat><c:strRef><c:f>Sheet1!$A$2:$A$%d</c:f><c:strCache><c:ptCount val="%d"/>`, lastRow, len(categories)))
+		for j, category := range categories {
+			b.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%s</c:v></c:pt>`, j, escapeXML(category)))
+		}
+		b.WriteString(`</c:strCache></c:strRef></c:cat>`)
+		b.WriteString(fmt.Sprintf(`<c:val><c:numRef><c:f>Sheet1!$%s$2:$%s$%d</c:f><c:numCache><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, column, column, lastRow, len(s.Values)))
+		for j, value := range s.Values {
+			b.WriteString(fmt.Sprintf(`<c:pt idx="%d"><c:v>%s</c:v></c:pt>`, j, strconv.FormatFloat(value, 'f', -1, 64)))
+		}
+		b.WriteString(`</c:numCache></c:numRef></c:val>`)
+		if chartType == ChartTypeLine {
+			b.WriteString(`<c:smooth val="0"/>`)
+		}
+		b.WriteString(`</c:ser>`)
+	}
+}
+
+// spreadsheetColumnName returns the column letters for a zero-based column index
+func spreadsheetColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// chartWorkbook builds the minimal SpreadsheetML package holding the chart data so the
+// chart stays editable in Word.
+func chartWorkbook(chartType ChartType, data ChartData) ([]byte, error) {
+	series := data.Series
+	if chartType == ChartTypePie {
+		series = series[:1]
+	}
+
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	sheet.WriteString(`<row r="1">`)
+	for i, s := range series {
+		sheet.WriteString(inlineStringCell(spreadsheetColumnName(i+1)+"1", s.Name))
+	}
+	sheet.WriteString(`</row>`)
+	for j, category := range data.Categories {
+		row := strconv.Itoa(j + 2)
+		sheet.WriteString(`<row r="` + row + `">`)
+		sheet.WriteString(inlineStringCell("A"+row, category))
+		for i, s := range series {
+			sheet.WriteString(fmt.Sprintf(`<c r="%s%s"><v>%s</v></c>`, spreadsheetColumnName(i+1), row, strconv.FormatFloat(s.Values[j], 'f', -1, 64)))
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	files := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zipWriter.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(file.data)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func inlineStringCell(ref, value string) string {
+	return `<c r="` + ref + `" t="inlineStr"><is><t>` + escapeXML(value) + `</t></is></c>`
+}
+
+// parseChartPart reads the chart type and cached data back from a chart part written by
+// Word or by AddChart. Unsupported chart kinds yield an empty type.
+func parseChartPart(data []byte) (ChartType, ChartData, error) {
+	type seriesXML struct {
+		Name          string   `xml:"tx>strRef>strCache>pt>v"`
+		Categories    []string `xml:"cat>strRef>strCache>pt>v"`
+		NumCategories []string `xml:"cat>numRef>numCache>pt>v"`
+		Values        []string `xml:"val>numRef>numCache>pt>v"`
+	}
+	type plotXML struct {
+		BarDir struct {
+			Val string `xml:"val,attr"`
+		} `xml:"barDir"`
+		Series []seriesXML `xml:"ser"`
+	}
+	var space struct {
+		Chart struct {
+			Title    []string `xml:"title>tx>rich>p>r>t"`
+			PlotArea struct {
+				Bar  *plotXML `xml:"barChart"`
+				Line *plotXML `xml:"lineChart"`
+				Pie  *plotXML `xml:"pieChart"`
+			} `xml:"plotArea"`
+		} `xml:"chart"`
+	}
+	if err := xml.Unmarshal(data, &space); err != nil {
+		return "", ChartData{}, err
+	}
+
+	var (
+		chartType ChartType
+		plot      *plotXML
+	)
+	plotArea := space.Chart.PlotArea
+	switch {
+	case plotArea.Bar != nil:
+		plot = plotArea.Bar
+		chartType = ChartTypeColumn
+		if plot.BarDir.Val == "bar" {
+			chartType = ChartTypeBar
+		}
+	case plotArea.Line != nil:
+		plot, chartType = plotArea.Line, ChartTypeLine
+	case plotArea.Pie != nil:
+		plot, chartType = plotArea.Pie, ChartTypePie
+	default:
+		return "", ChartData{}, nil
+	}
+
+	chartData := ChartData{Title: strings.Join(space.Chart.Title, "")}
+	for i, ser := range plot.Series {
+		if i == 0 {
+			chartData.Categories = ser.Categories
+			if len(chartData.Categories) == 0 {
+				chartData.Categories = ser.NumCategories
+			}
+		}
+		series := ChartSeries{Name: ser.Name, Values: make([]float64, 0, len(ser.Values))}
+		for _, v := range ser.Values {
+			value, _ := strconv.ParseFloat(v, 64)
+			series.Values = append(series.Values, value)
+		}
+		chartData.Series = append(chartData.Series, series)
+	}
+	return chartType, chartData, nil
+}
@@ -9,7 +9,14 @@ const (
 	ContentTypeWMLNumbering    = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
 	ContentTypeWMLHeader       = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
 	ContentTypeWMLFooter       = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+	ContentTypeWMLFontTable    = "application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml"
+	ContentTypeObfuscatedFont  = "application/vnd.openxmlformats-officedocument.obfuscatedFont"
+	ContentTypeTheme           = "application/vnd.openxmlformats-officedocument.theme+xml"
+	ContentTypeWMLGlossary     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.glossary+xml"
+	ContentTypeDrawingMLChart  = "application/vnd.openxmlformats-officedocument.drawingml.chart+xml"
+	ContentTypeSpreadsheet     = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	ContentTypeOPCCoreProps    = "application/vnd.openxmlformats-package.core-properties+xml"
+	ContentTypeCustomProps     = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
 	ContentTypeRels            = "application/vnd.openxmlformats-package.relationships+xml"
 )
 
@@ -24,7 +31,14 @@ const (
 	RelTypeNumbering      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
 	RelTypeHeader         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
 	RelTypeFooter         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+	RelTypeFontTable      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable"
+	RelTypeFont           = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/font"
+	RelTypeTheme          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme"
+	RelTypeGlossary       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/glossaryDocument"
+	RelTypeChart          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	RelTypePackage        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/package"
 	RelTypeCoreProps      = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+	RelTypeCustomProps    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
 )
 
 // BreakType represents different types of breaks
@@ -63,7 +77,10 @@ const (
 type WDColorIndex string
 
 const (
+	// WDColorIndexAuto means no highlight is set on the run; WDColorIndexNone explicitly
+	// turns off a highlight inherited from a style
 	WDColorIndexAuto        WDColorIndex = "auto"
+	WDColorIndexNone        WDColorIndex = "none"
 	WDColorIndexBlack       WDColorIndex = "black"
 	WDColorIndexBlue        WDColorIndex = "blue"
 	WDColorIndexBrightGreen WDColorIndex = "brightGreen"
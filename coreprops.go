@@ -2,29 +2,38 @@ package docx
 
 import (
 	"encoding/xml"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // CoreProperties represents the core properties (metadata) of a document
 type CoreProperties struct {
-	Title       string
-	Subject     string
-	Creator     string
-	Keywords    string
-	Description string
-	Category    string
-	Created     time.Time
-	Modified    time.Time
-	Revision    string
-}
-
-// NewCoreProperties creates a new CoreProperties instance with default values
+	Title          string
+	Subject        string
+	Creator        string
+	LastModifiedBy string
+	Keywords       string
+	Description    string
+	Category       string
+	ContentStatus  string // e.g. "Draft", "Final" or "Approved"
+	Language       string // e.g. "en-US"
+	Created        time.Time
+	Modified       time.Time
+	Revision       string
+
+	// DisableAutoUpdate keeps Modified and Revision unchanged on save. By default every
+	// save stamps Modified with the current time and increments Revision.
+	DisableAutoUpdate bool
+}
+
+// NewCoreProperties creates a new CoreProperties instance with default values. Revision
+// starts out empty so that the first save records revision 1.
 func NewCoreProperties() *CoreProperties {
 	now := time.Now()
 	return &CoreProperties{
 		Created:  now,
 		Modified: now,
-		Revision: "1",
 	}
 }
 
@@ -43,6 +52,11 @@ func (cp *CoreProperties) SetCreator(creator string) {
 	cp.Creator = creator
 }
 
+// SetLastModifiedBy sets the name of the user who last modified the document
+func (cp *CoreProperties) SetLastModifiedBy(lastModifiedBy string) {
+	cp.LastModifiedBy = lastModifiedBy
+}
+
 // SetKeywords sets the document keywords
 func (cp *CoreProperties) SetKeywords(keywords string) {
 	cp.Keywords = keywords
@@ -58,6 +72,42 @@ func (cp *CoreProperties) SetCategory(category string) {
 	cp.Category = category
 }
 
+// SetContentStatus sets the document content status (e.g. "Draft", "Final")
+func (cp *CoreProperties) SetContentStatus(status string) {
+	cp.ContentStatus = status
+}
+
+// SetLanguage sets the primary language of the document content (e.g. "en-US")
+func (cp *CoreProperties) SetLanguage(language string) {
+	cp.Language = language
+}
+
+// touch records a save at the given time unless auto-updating is disabled
+func (cp *CoreProperties) touch(now time.Time) {
+	if cp.DisableAutoUpdate {
+		return
+	}
+	cp.Modified = now
+	if revision, err := strconv.Atoi(strings.TrimSpace(cp.Revision)); err == nil {
+		cp.Revision = strconv.Itoa(revision + 1)
+	} else if strings.TrimSpace(cp.Revision) == "" {
+		cp.Revision = "1"
+	}
+}
+
+// coreDateXML is a dcterms date element carrying the W3CDTF type Word expects
+type coreDateXML struct {
+	Type  string `xml:"xsi:type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func newCoreDate(t time.Time) *coreDateXML {
+	if t.IsZero() {
+		return nil
+	}
+	return &coreDateXML{Type: "dcterms:W3CDTF", Value: t.UTC().Format(time.RFC3339)}
+}
+
 // ToXML converts the core properties to XML format
 func (cp *CoreProperties) ToXML() ([]byte, error) {
 	type CorePropsXML struct {
@@ -67,32 +117,85 @@ func (cp *CoreProperties) ToXML() ([]byte, error) {
 		XmlnsDCTerms string   `xml:"xmlns:dcterms,attr"`
 		XmlnsXsi     string   `xml:"xmlns:xsi,attr"`
 
-		Title       string    `xml:"dc:title,omitempty"`
-		Subject     string    `xml:"dc:subject,omitempty"`
-		Creator     string    `xml:"dc:creator,omitempty"`
-		Keywords    string    `xml:"cp:keywords,omitempty"`
-		Description string    `xml:"dc:description,omitempty"`
-		Category    string    `xml:"cp:category,omitempty"`
-		Created     time.Time `xml:"dcterms:created,omitempty"`
-		Modified    time.Time `xml:"dcterms:modified,omitempty"`
-		Revision    string    `xml:"cp:revision,omitempty"`
+		Title          string       `xml:"dc:title,omitempty"`
+		Subject        string       `xml:"dc:subject,omitempty"`
+		Creator        string       `xml:"dc:creator,omitempty"`
+		Keywords       string       `xml:"cp:keywords,omitempty"`
+		Description    string       `xml:"dc:description,omitempty"`
+		Category       string       `xml:"cp:category,omitempty"`
+		ContentStatus  string       `xml:"cp:contentStatus,omitempty"`
+		Language       string       `xml:"dc:language,omitempty"`
+		LastModifiedBy string       `xml:"cp:lastModifiedBy,omitempty"`
+		Revision       string       `xml:"cp:revision,omitempty"`
+		Created        *coreDateXML `xml:"dcterms:created,omitempty"`
+		Modified       *coreDateXML `xml:"dcterms:modified,omitempty"`
 	}
 
 	props := CorePropsXML{
-		Xmlns:        "http://schemas.openxmlformats.org/package/2006/metadata/core-properties",
-		XmlnsDC:      "http://purl.org/dc/elements/1.1/",
-		XmlnsDCTerms: "http://purl.org/dc/terms/",
-		XmlnsXsi:     "http://www.w3.org/2001/XMLSchema-instance",
-		Title:        cp.Title,
-		Subject:      cp.Subject,
-		Creator:      cp.Creator,
-		Keywords:     cp.Keywords,
-		Description:  cp.Description,
-		Category:     cp.Category,
-		Created:      cp.Created,
-		Modified:     cp.Modified,
-		Revision:     cp.Revision,
+		Xmlns:          "http://schemas.openxmlformats.org/package/2006/metadata/core-properties",
+		XmlnsDC:        "http://purl.org/dc/elements/1.1/",
+		XmlnsDCTerms:   "http://purl.org/dc/terms/",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		Title:          cp.Title,
+		Subject:        cp.Subject,
+		Creator:        cp.Creator,
+		Keywords:       cp.Keywords,
+		Description:    cp.Description,
+		Category:       cp.Category,
+		ContentStatus:  cp.ContentStatus,
+		Language:       cp.Language,
+		LastModifiedBy: cp.LastModifiedBy,
+		Revision:       cp.Revision,
+		Created:        newCoreDate(cp.Created),
+		Modified:       newCoreDate(cp.Modified),
+	}
+
+	data, err := xml.MarshalIndent(props, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// parseCoreProperties reads a docProps/core.xml part
+func parseCoreProperties(data []byte) (*CoreProperties, error) {
+	type corePropsXML struct {
+		Title          string `xml:"title"`
+		Subject        string `xml:"subject"`
+		Creator        string `xml:"creator"`
+		Keywords       string `xml:"keywords"`
+		Description    string `xml:"description"`
+		Category       string `xml:"category"`
+		ContentStatus  string `xml:"contentStatus"`
+		Language       string `xml:"language"`
+		LastModifiedBy string `xml:"lastModifiedBy"`
+		Revision       string `xml:"revision"`
+		Created        string `xml:"created"`
+		Modified       string `xml:"modified"`
+	}
+
+	var parsed corePropsXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
 	}
 
-	return xml.MarshalIndent(props, "", "  ")
+	cp := &CoreProperties{
+		Title:          parsed.Title,
+		Subject:        parsed.Subject,
+		Creator:        parsed.Creator,
+		Keywords:       parsed.Keywords,
+		Description:    parsed.Description,
+		Category:       parsed.Category,
+		ContentStatus:  parsed.ContentStatus,
+		Language:       parsed.Language,
+		LastModifiedBy: parsed.LastModifiedBy,
+		Revision:       parsed.Revision,
+	}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(parsed.Created)); err == nil {
+		cp.Created = t
+	}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(parsed.Modified)); err == nil {
+		cp.Modified = t
+	}
+	return cp, nil
 }
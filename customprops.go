@@ -0,0 +1,202 @@
+package docx
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// customPropertyFormatID is the format ID Office assigns to user-defined custom properties
+const customPropertyFormatID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+
+// CustomProperties holds the typed user-defined properties stored in docProps/custom.xml.
+// Properties keep their definition order; values of types without a dedicated getter
+// (e.g. vt:r8, vt:vector or vt:blob) read from an opened document are written back verbatim
+// on save.
+type CustomProperties struct {
+	properties []*customProperty
+}
+
+type customProperty struct {
+	name      string
+	valueType string // local name of the vt: element, e.g. "lpwstr", "i4", "bool", "filetime"
+	value     string
+	raw       string // inner XML of a value without a dedicated getter, written back verbatim
+}
+
+// NewCustomProperties creates an empty custom properties collection
+func NewCustomProperties() *CustomProperties {
+	return &CustomProperties{}
+}
+
+// SetString sets a text property, replacing any existing property with the same name
+func (cp *CustomProperties) SetString(name, value string) {
+	cp.set(name, "lpwstr", value)
+}
+
+// SetInt sets an integer property, stored as vt:i4 when it fits in 32 bits and vt:i8 otherwise
+func (cp *CustomProperties) SetInt(name string, value int) {
+	valueType := "i4"
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		valueType = "i8"
+	}
+	cp.set(name, valueType, strconv.Itoa(value))
+}
+
+// SetBool sets a yes/no property
+func (cp *CustomProperties) SetBool(name string, value bool) {
+	cp.set(name, "bool", strconv.FormatBool(value))
+}
+
+// SetDate sets a date property, stored in UTC
+func (cp *CustomProperties) SetDate(name string, value time.Time) {
+	cp.set(name, "filetime", value.UTC().Format(time.RFC3339))
+}
+
+func (cp *CustomProperties) set(name, valueType, value string) {
+	if property := cp.find(name); property != nil {
+		property.valueType = valueType
+		property.value = value
+		property.raw = ""
+		return
+	}
+	cp.properties = append(cp.properties, &customProperty{name: name, valueType: valueType, value: value})
+}
+
+func (cp *CustomProperties) find(name string) *customProperty {
+	for _, property := range cp.properties {
+		if property.name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+// String returns the value of a text property. ok is false when the property is missing or
+// not text.
+func (cp *CustomProperties) String(name string) (string, bool) {
+	property := cp.find(name)
+	if property == nil {
+		return "", false
+	}
+	switch property.valueType {
+	case "lpwstr", "lpstr", "bstr":
+		return property.value, true
+	}
+	return "", false
+}
+
+// Int returns the value of an integer property
+func (cp *CustomProperties) Int(name string) (int, bool) {
+	property := cp.find(name)
+	if property == nil {
+		return 0, false
+	}
+	switch property.valueType {
+	case "i1", "i2", "i4", "i8", "int", "ui1", "ui2", "ui4", "ui8", "uint":
+		value, err := strconv.Atoi(strings.TrimSpace(property.value))
+		return value, err == nil
+	}
+	return 0, false
+}
+
+// Bool returns the value of a yes/no property
+func (cp *CustomProperties) Bool(name string) (bool, bool) {
+	property := cp.find(name)
+	if property == nil || property.valueType != "bool" {
+		return false, false
+	}
+	return parseBinaryFlag(strings.TrimSpace(property.value)), true
+}
+
+// Date returns the value of a date property
+func (cp *CustomProperties) Date(name string) (time.Time, bool) {
+	property := cp.find(name)
+	if property == nil || (property.valueType != "filetime" && property.valueType != "date") {
+		return time.Time{}, false
+	}
+	value, err := time.Parse(time.RFC3339, strings.TrimSpace(property.value))
+	return value, err == nil
+}
+
+// Names returns the property names in definition order
+func (cp *CustomProperties) Names() []string {
+	names := make([]string, 0, len(cp.properties))
+	for _, property := range cp.properties {
+		names = append(names, property.name)
+	}
+	return names
+}
+
+// Remove deletes the named property and reports whether it existed
+func (cp *CustomProperties) Remove(name string) bool {
+	for i, property := range cp.properties {
+		if property.name == name {
+			cp.properties = append(cp.properties[:i], cp.properties[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ToXML converts the custom properties to XML format
+func (cp *CustomProperties) ToXML() []byte {
+	var builder strings.Builder
+	builder.WriteString(xml.Header)
+	builder.WriteString(`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">`)
+	for i, property := range cp.properties {
+		// pid 0 and 1 are reserved, user-defined properties start at 2
+		builder.WriteString(`<property fmtid="` + customPropertyFormatID + `" pid="` + strconv.Itoa(i+2) + `" name="` + escapeXML(property.name) + `">`)
+		value := escapeXML(property.value)
+		if property.raw != "" {
+			value = property.raw
+		}
+		builder.WriteString(`<vt:` + property.valueType + `>` + value + `</vt:` + property.valueType + `>`)
+		builder.WriteString(`</property>`)
+	}
+	builder.WriteString(`</Properties>`)
+	return []byte(builder.String())
+}
+
+// parseCustomProperties reads a docProps/custom.xml part
+func parseCustomProperties(data []byte) (*CustomProperties, error) {
+	var parsed struct {
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value struct {
+				XMLName xml.Name
+				Text    string `xml:",chardata"`
+				Inner   string `xml:",innerxml"`
+			} `xml:",any"`
+		} `xml:"property"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+
+	cp := NewCustomProperties()
+	for _, property := range parsed.Properties {
+		if property.Name == "" || property.Value.XMLName.Local == "" {
+			continue
+		}
+		cp.set(property.Name, property.Value.XMLName.Local, property.Value.Text)
+		if !modeledCustomPropertyType(property.Value.XMLName.Local) {
+			cp.find(property.Name).raw = property.Value.Inner
+		}
+	}
+	return cp, nil
+}
+
+// modeledCustomPropertyType reports whether values of the vt: type are read through one of
+// the typed getters; other values keep their inner XML so structured types such as
+// vt:vector survive a round trip.
+func modeledCustomPropertyType(valueType string) bool {
+	switch valueType {
+	case "lpwstr", "lpstr", "bstr", "bool", "filetime", "date",
+		"i1", "i2", "i4", "i8", "int", "ui1", "ui2", "ui4", "ui8", "uint":
+		return true
+	}
+	return false
+}
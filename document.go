@@ -30,6 +30,8 @@ package docx
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 // Document represents a Word document and provides methods to manipulate its content
@@ -63,18 +65,38 @@ func OpenDocument(path string) (*Document, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open package: %w", err)
 	}
+	doc, err := openPackageDocument(pkg, fmt.Sprintf("file '%s'", path))
+	if err != nil {
+		pkg.Close()
+		return nil, err
+	}
+	return doc, nil
+}
 
+// OpenDocumentBytes opens a Word document held in memory. The document has no file path,
+// so it is written with SaveAs or Bytes.
+func OpenDocumentBytes(data []byte) (*Document, error) {
+	pkg, err := OpenPackageBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	return openPackageDocument(pkg, "data")
+}
+
+// openPackageDocument returns the document of an opened package; source names the package
+// in errors
+func openPackageDocument(pkg *Package, source string) (*Document, error) {
 	docPart := pkg.MainDocumentPart()
 	if docPart.ContentType() != ContentTypeWMLDocumentMain {
-		return nil, fmt.Errorf("file '%s' is not a Word file, content type is '%s'",
-			path, docPart.ContentType())
+		return nil, fmt.Errorf("%w: %s is not a Word file, content type is '%s'",
+			ErrNotADocx, source, docPart.ContentType())
 	}
 
 	return &Document{
 		pkg:       pkg,
 		docPart:   docPart,
 		comments:  NewComments(),
-		settings:  NewSettings(),
+		settings:  loadSettings(pkg, docPart),
 		styles:    NewStyles(),
 		numbering: NewNumbering(pkg),
 	}, nil
@@ -83,7 +105,7 @@ func OpenDocument(path string) (*Document, error) {
 // Get XML content of the document as string
 func (d *Document) GetXML() (string, error) {
 	if d.docPart == nil {
-		return "", fmt.Errorf("document has no main document part")
+		return "", fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	return string(d.docPart.Data), nil
 }
@@ -93,16 +115,95 @@ func (d *Document) AddParagraph(text ...string) *Paragraph {
 	return d.docPart.AddParagraph(text...)
 }
 
+// AddParagraphLines adds a single paragraph in which every line (and every newline within
+// a line) is separated by a line break rather than starting a new paragraph.
+func (d *Document) AddParagraphLines(lines ...string) *Paragraph {
+	paragraph := d.docPart.AddParagraph()
+	paragraph.AddRun("").SetMultilineText(strings.Join(lines, "\n"))
+	d.docPart.updateXMLData()
+	return paragraph
+}
+
+// AddCodeBlock adds a shaded paragraph holding text in a monospaced run with proofing
+// disabled. Newlines in text become line breaks so the block stays a single paragraph.
+func (d *Document) AddCodeBlock(text string) *Paragraph {
+	paragraph := d.docPart.AddParagraph()
+	paragraph.SetShading("clear", codeBlockShading, "auto")
+	run := paragraph.AddRun("")
+	run.SetMultilineText(text)
+	run.SetMonospace()
+	run.SetNoProof(true)
+	d.docPart.updateXMLData()
+	return paragraph
+}
+
+// AddBlockQuote adds a quotation paragraph indented on both sides with its text in italics.
+// The Quote style is applied when the document defines it. Newlines in text become line
+// breaks so the quote stays a single paragraph.
+func (d *Document) AddBlockQuote(text string) *Paragraph {
+	return d.AddBlockQuoteWithBorder(text, "")
+}
+
+// AddBlockQuoteWithBorder adds a block quote like AddBlockQuote with a vertical rule of the
+// given hex color along its left edge. An empty color adds no rule.
+func (d *Document) AddBlockQuoteWithBorder(text, color string) *Paragraph {
+	paragraph := d.docPart.AddParagraph()
+	if d.HasStyle("Quote") {
+		paragraph.SetStyle("Quote")
+	}
+	paragraph.SetIndentation(blockQuoteIndent, blockQuoteIndent, 0, 0)
+	if color = strings.TrimPrefix(color, "#"); color != "" {
+		paragraph.SetBorder(ParagraphBorderLeft, ParagraphBorder{
+			Style: "single",
+			Color: color,
+			Size:  blockQuoteBorderSize,
+			Space: blockQuoteBorderSpace,
+		})
+	}
+	run := paragraph.AddRun("")
+	run.SetMultilineText(text)
+	run.SetItalic(true)
+	d.docPart.updateXMLData()
+	return paragraph
+}
+
 // AddPicture adds a new paragraph containing the specified image. Width and height are specified in EMUs.
 // Passing zero for either dimension will keep the aspect ratio using the source image dimensions.
 func (d *Document) AddPicture(path string, widthEMU, heightEMU int64) (*Paragraph, *Picture, error) {
+	return d.AddPictureWithOptions(path, widthEMU, heightEMU, PictureOptions{})
+}
+
+// AddPictureWithOptions adds a new paragraph containing the specified image like AddPicture.
+// A dimension passed as zero is derived from the source image at the resolution set in
+// opts, so a 300 DPI screenshot gets its intended physical size.
+func (d *Document) AddPictureWithOptions(path string, widthEMU, heightEMU int64, opts PictureOptions) (*Paragraph, *Picture, error) {
+	if d.docPart == nil {
+		return nil, nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	picture, err := d.docPart.addPictureFromFile(path, widthEMU, heightEMU, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.addPictureParagraph(picture), picture, nil
+}
+
+// AddPictureFromBytes adds a new paragraph containing an image held in memory, e.g. one
+// generated or downloaded by the caller. name is a file name whose extension gives the
+// image format (such as "chart.png") and becomes the picture description. Sizes work as
+// for AddPictureWithOptions.
+func (d *Document) AddPictureFromBytes(data []byte, name string, widthEMU, heightEMU int64, opts PictureOptions) (*Paragraph, *Picture, error) {
 	if d.docPart == nil {
-		return nil, nil, fmt.Errorf("document has no main document part")
+		return nil, nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
-	picture, err := d.docPart.addPictureFromFile(path, widthEMU, heightEMU)
+	picture, err := d.docPart.addPictureFromBytes(data, name, widthEMU, heightEMU, opts)
 	if err != nil {
 		return nil, nil, err
 	}
+	return d.addPictureParagraph(picture), picture, nil
+}
+
+// addPictureParagraph appends a paragraph holding picture to the body
+func (d *Document) addPictureParagraph(picture *Picture) *Paragraph {
 	paragraph := NewParagraph()
 	paragraph.owner = d.docPart
 	run := NewRun("")
@@ -113,15 +214,69 @@ func (d *Document) AddPicture(path string, widthEMU, heightEMU int64) (*Paragrap
 	d.docPart.paragraphs = append(d.docPart.paragraphs, paragraph)
 	d.docPart.bodyElements = append(d.docPart.bodyElements, documentElement{paragraph: paragraph})
 	d.docPart.updateXMLData()
+	return paragraph
+}
+
+// AddCenteredPicture adds a new centered paragraph containing the specified image, e.g. a logo.
+// Width and height are specified in EMUs as for AddPicture.
+func (d *Document) AddCenteredPicture(path string, widthEMU, heightEMU int64) (*Paragraph, *Picture, error) {
+	paragraph, picture, err := d.AddPicture(path, widthEMU, heightEMU)
+	if err != nil {
+		return nil, nil, err
+	}
+	paragraph.SetAlignment(WDAlignParagraphCenter)
+	d.docPart.updateXMLData()
+	return paragraph, picture, nil
+}
+
+// AddChart adds a new paragraph containing an inline chart of the given type. The chart
+// part and an embedded workbook holding data are added to the package, so the chart can be
+// edited in Word. The chart uses Word's default size; change it with Chart.SetSize.
+func (d *Document) AddChart(chartType ChartType, data ChartData) (*Chart, error) {
+	if d.docPart == nil {
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	chart, err := d.docPart.addChart(chartType, data)
+	if err != nil {
+		return nil, err
+	}
+	paragraph := d.docPart.AddParagraph()
+	paragraph.AddRun("").chart = chart
+	d.docPart.updateXMLData()
+	return chart, nil
+}
+
+// AddLinkedPicture adds a new paragraph containing a picture that links to an external image
+// (r:link with an External relationship) rather than embedding it. Width and height are in EMUs.
+func (d *Document) AddLinkedPicture(url string, widthEMU, heightEMU int64) (*Paragraph, *Picture, error) {
+	if d.docPart == nil {
+		return nil, nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	picture, err := d.docPart.addLinkedPicture(url, widthEMU, heightEMU)
+	if err != nil {
+		return nil, nil, err
+	}
+	paragraph := d.docPart.AddParagraph()
+	run := paragraph.AddRun("")
+	run.picture = picture
+	d.docPart.updateXMLData()
 
 	return paragraph, picture, nil
 }
 
 // AddHeading adds a heading paragraph with the specified text and level
-// Level 0 creates a Title style, levels 1-9 create Heading styles
+// Level 0 creates a Title style, levels 1-9 create Heading styles. Like Word's built-in
+// heading styles, the heading keeps with the next paragraph and keeps its lines together;
+// use AddHeadingWithKeep to opt out.
 func (d *Document) AddHeading(text string, level int) (*Paragraph, error) {
+	return d.AddHeadingWithKeep(text, level, true)
+}
+
+// AddHeadingWithKeep adds a heading like AddHeading. When keep is false the keep-with-next
+// and keep-lines settings are left unset, so the heading may end a page.
+func (d *Document) AddHeadingWithKeep(text string, level int, keep bool) (*Paragraph, error) {
 	if level < 0 || level > 9 {
-		return nil, fmt.Errorf("level must be in range 0-9, got %d", level)
+		return nil, fmt.Errorf("%w: level must be in range 0-9, got %d", ErrIndexOutOfRange, level)
 	}
 
 	var style string
@@ -133,6 +288,11 @@ func (d *Document) AddHeading(text string, level int) (*Paragraph, error) {
 
 	paragraph := d.AddParagraph(text)
 	paragraph.SetStyle(style)
+	if keep {
+		paragraph.SetKeepWithNext(true)
+		paragraph.SetKeepLines(true)
+	}
+	d.docPart.updateXMLData()
 	return paragraph, nil
 }
 
@@ -177,11 +337,113 @@ func (d *Document) AddSection(startType SectionStartType) *Section {
 	return d.docPart.AddSection(startType)
 }
 
+// AddTwoColumnSection ends the current section after the content added so far with a
+// continuous break and lays out everything added afterwards in two columns separated by
+// spacing twentieths of a point, e.g. for newsletter-style pages. Call EndColumns to
+// return to a single column. It returns the two-column section.
+func (d *Document) AddTwoColumnSection(spacing int) (*Section, error) {
+	if d.docPart == nil {
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	section, err := d.breakBeforeFinalSection()
+	if err != nil {
+		return nil, err
+	}
+	section.SetColumns(2, spacing)
+	d.docPart.updateXMLData()
+	return section, nil
+}
+
+// EndColumns closes a multi-column section started by AddTwoColumnSection: content added
+// afterwards continues on the same page in a single column. It returns ErrNotFound when the
+// current section has a single column.
+func (d *Document) EndColumns() error {
+	if d.docPart == nil {
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	if count, _ := d.docPart.finalSection().Columns(); count <= 1 {
+		return fmt.Errorf("%w: the current section has no columns to end", ErrNotFound)
+	}
+	section, err := d.breakBeforeFinalSection()
+	if err != nil {
+		return err
+	}
+	section.SetColumns(1, 0)
+	d.docPart.updateXMLData()
+	return nil
+}
+
+// breakBeforeFinalSection ends the section running up to the current end of the body with
+// a copy of the final section's layout, and makes the final section start continuously
+// after it. The final section is returned. An empty paragraph carries the break when the
+// body does not end with a paragraph of its own.
+func (d *Document) breakBeforeFinalSection() (*Section, error) {
+	dp := d.docPart
+	final := dp.finalSection()
+
+	var last *Paragraph
+	hasContent := false
+	for i := len(dp.bodyElements) - 1; i >= 0; i-- {
+		element := dp.bodyElements[i]
+		if element.section != nil {
+			continue
+		}
+		hasContent = true
+		last = element.paragraph
+		break
+	}
+	if !hasContent {
+		final.SetStartType(SectionStartContinuous)
+		return final, nil
+	}
+	if last == nil || last.section != nil {
+		last = dp.AddParagraph()
+	}
+	if _, err := dp.SplitSectionAt(last, SectionStartContinuous); err != nil {
+		return nil, err
+	}
+	return final, nil
+}
+
 // Paragraphs returns all paragraphs in the document
 func (d *Document) Paragraphs() []*Paragraph {
 	return d.docPart.Paragraphs()
 }
 
+// AllParagraphs returns body paragraphs, table cell paragraphs (recursively) and the
+// paragraphs of all section headers and footers, for document-wide transformations.
+func (d *Document) AllParagraphs() []*Paragraph {
+	if d.docPart == nil {
+		return nil
+	}
+	return d.docPart.AllParagraphs()
+}
+
+// ApplyToAllRuns calls fn for every run of the document, in the order of AllParagraphs:
+// body and table runs first, then the runs of every header and footer. The affected parts
+// are regenerated afterwards, so fn may freely change formatting or text.
+func (d *Document) ApplyToAllRuns(fn func(*Run)) {
+	if d.docPart == nil || fn == nil {
+		return
+	}
+	for _, paragraph := range d.docPart.AllParagraphs() {
+		for _, run := range paragraph.runs {
+			fn(run)
+		}
+	}
+	d.updateStories()
+}
+
+// updateStories regenerates the XML of the main document part, its headers and footers
+func (d *Document) updateStories() {
+	d.docPart.updateXMLData()
+	d.docPart.visitHeadersAndFooters(func(header *Header) {
+		header.updateXMLData()
+	}, func(footer *Footer) {
+		footer.updateXMLData()
+	})
+}
+
 // Tables returns all tables in the document
 func (d *Document) Tables() []*Table {
 	return d.docPart.Tables()
@@ -192,26 +454,97 @@ func (d *Document) Sections() []*Section {
 	return d.docPart.Sections()
 }
 
+// SectionOf returns the section the paragraph belongs to, or nil when it is not part of the
+// document body
+func (d *Document) SectionOf(paragraph *Paragraph) *Section {
+	if d.docPart == nil {
+		return nil
+	}
+	return d.docPart.SectionOf(paragraph)
+}
+
+// SetMargins sets the page margins of every section, including those ended by section
+// breaks, in twentieths of a point
+func (d *Document) SetMargins(top, right, bottom, left int) {
+	if d.docPart == nil {
+		return
+	}
+	for _, section := range d.docPart.allSections() {
+		section.SetMargins(top, right, bottom, left)
+	}
+	d.docPart.updateXMLData()
+}
+
+// SetMarginsInches sets the page margins of every section in inches
+func (d *Document) SetMarginsInches(top, right, bottom, left float64) {
+	d.SetMargins(inchesToTwips(top), inchesToTwips(right), inchesToTwips(bottom), inchesToTwips(left))
+}
+
+// InsertParagraphAtIndex inserts a paragraph at the given position among the body
+// elements (paragraphs, tables and section properties)
+func (d *Document) InsertParagraphAtIndex(bodyIndex int, text string) (*Paragraph, error) {
+	if d.docPart == nil {
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	return d.docPart.InsertParagraphAtIndex(bodyIndex, text)
+}
+
 // InsertTableAfterParagraph inserts a table immediately after the specified paragraph
 func (d *Document) InsertTableAfterParagraph(paragraph *Paragraph, rows, cols int) (*Table, error) {
 	if d.docPart == nil {
-		return nil, fmt.Errorf("document has no main document part")
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	return d.docPart.InsertTableAfterParagraph(paragraph, rows, cols)
 }
 
+// SplitSectionAt inserts a section break after the specified paragraph and returns the
+// section that now ends there, ready for configuration (e.g. SetPageSize for landscape).
+// startType controls how the content after the break begins.
+func (d *Document) SplitSectionAt(paragraph *Paragraph, startType SectionStartType) (*Section, error) {
+	if d.docPart == nil {
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	return d.docPart.SplitSectionAt(paragraph, startType)
+}
+
 // RemoveParagraph removes the specified paragraph from the document
 func (d *Document) RemoveParagraph(paragraph *Paragraph) error {
 	if d.docPart == nil {
-		return fmt.Errorf("document has no main document part")
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	return d.docPart.RemoveParagraph(paragraph)
 }
 
+// MergeParagraphs appends the runs of src to dst and removes src from the document body.
+// A section break carried by src moves to dst when dst has none, so the layout that
+// followed src is kept.
+func (d *Document) MergeParagraphs(dst, src *Paragraph) error {
+	if d.docPart == nil {
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	if dst == nil || src == nil {
+		return fmt.Errorf("%w: paragraphs cannot be nil", ErrInvalidArgument)
+	}
+	if dst == src {
+		return fmt.Errorf("%w: cannot merge a paragraph into itself", ErrInvalidArgument)
+	}
+	if err := d.docPart.RemoveParagraph(src); err != nil {
+		return err
+	}
+	dst.AppendRuns(src.runs...)
+	src.runs = nil
+	if dst.section == nil && src.section != nil {
+		dst.section = src.section
+		src.section = nil
+	}
+	d.docPart.updateXMLData()
+	return nil
+}
+
 // RemoveTable removes the specified table from the document
 func (d *Document) RemoveTable(table *Table) error {
 	if d.docPart == nil {
-		return fmt.Errorf("document has no main document part")
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	return d.docPart.RemoveTable(table)
 }
@@ -219,11 +552,81 @@ func (d *Document) RemoveTable(table *Table) error {
 // RemoveSection removes the specified section from the document
 func (d *Document) RemoveSection(section *Section) error {
 	if d.docPart == nil {
-		return fmt.Errorf("document has no main document part")
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	return d.docPart.RemoveSection(section)
 }
 
+// PruneUnusedMedia deletes image parts that no drawing or picture in the document, its
+// headers or footers references any more, along with their relationships. It returns the
+// number of image parts removed.
+func (d *Document) PruneUnusedMedia() (int, error) {
+	if d.docPart == nil {
+		return 0, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	d.updateStories()
+	return d.pkg.pruneUnusedImages(), nil
+}
+
+// FlattenHyperlinks turns every hyperlink and cross-reference in the document, its headers
+// and footers into plain text, keeping the text and its formatting. Relationships to
+// external targets that no longer have a link are removed. It returns the number of runs
+// unlinked.
+func (d *Document) FlattenHyperlinks() (int, error) {
+	return d.unlinkRuns(func(*Run) {})
+}
+
+// RemoveHyperlinks removes every hyperlink like FlattenHyperlinks and also drops the
+// hyperlink look (the blue text color and single underline) from the unlinked runs. It
+// returns the number of runs unlinked.
+func (d *Document) RemoveHyperlinks() (int, error) {
+	return d.unlinkRuns(func(run *Run) {
+		if run.colorSet && strings.EqualFold(run.color, hyperlinkColor) {
+			run.ClearColor()
+		}
+		if run.underline == WDUnderlineSingle {
+			run.underline = WDUnderlineNone
+		}
+	})
+}
+
+// unlinkRuns clears the hyperlink of every linked run, calling fn on each, and saves the
+// changed parts
+func (d *Document) unlinkRuns(fn func(*Run)) (int, error) {
+	if d.docPart == nil {
+		return 0, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	count := 0
+	d.ApplyToAllRuns(func(run *Run) {
+		if !run.HasHyperlink() {
+			return
+		}
+		run.hyperlinkURL = ""
+		run.hyperlinkAnchor = ""
+		run.hyperlinkHistory = false
+		fn(run)
+		count++
+	})
+	d.pkg.pruneUnusedHyperlinks()
+	return count, nil
+}
+
+// RemoveProofingMarks deletes the spelling and grammar markers (w:proofErr) left by Word in
+// the document, its headers, footers, notes and comments, and returns how many were
+// removed. The regenerated body never carries them, so this matters mostly for parts the
+// library preserves as read and for body elements copied back by surgical editing.
+func (d *Document) RemoveProofingMarks() (int, error) {
+	if d.docPart == nil {
+		return 0, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	d.docPart.updateXMLData()
+	removed := d.pkg.removeProofingMarks()
+	if err := d.docPart.removeOriginalProofingMarks(); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
 // Header returns the default header for the first section, creating both if necessary.
 func (d *Document) Header() (*Header, error) {
 	return d.HeaderOfType(HeaderTypeDefault)
@@ -254,14 +657,14 @@ func (d *Document) FooterOfType(footerType FooterType) (*Footer, error) {
 
 func (d *Document) firstOrNewSection() (*Section, error) {
 	if d.docPart == nil {
-		return nil, fmt.Errorf("document has no main document part")
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
 	}
 	sections := d.docPart.Sections()
 	if len(sections) == 0 {
 		section := d.docPart.AddSection(SectionStartContinuous)
 		sections = d.docPart.Sections()
 		if len(sections) == 0 {
-			return nil, fmt.Errorf("failed to create section")
+			return nil, fmt.Errorf("%w: failed to create section", ErrPartNotFound)
 		}
 		return section, nil
 	}
@@ -278,6 +681,37 @@ func (d *Document) CoreProperties() *CoreProperties {
 	return d.pkg.CoreProperties()
 }
 
+// CustomProperties returns the document's custom (user-defined) properties
+func (d *Document) CustomProperties() *CustomProperties {
+	return d.pkg.CustomProperties()
+}
+
+// SetIndentOutput pretty-prints the XML parts when the document is saved.
+// See Package.SetIndentOutput.
+func (d *Document) SetIndentOutput(indent bool) {
+	d.pkg.SetIndentOutput(indent)
+}
+
+// SetTitle sets the document title in the core properties
+func (d *Document) SetTitle(title string) {
+	d.CoreProperties().SetTitle(title)
+}
+
+// SetAuthor sets the document author (dc:creator) in the core properties
+func (d *Document) SetAuthor(author string) {
+	d.CoreProperties().SetCreator(author)
+}
+
+// SetSubject sets the document subject in the core properties
+func (d *Document) SetSubject(subject string) {
+	d.CoreProperties().SetSubject(subject)
+}
+
+// SetKeywords sets the document keywords in the core properties
+func (d *Document) SetKeywords(keywords string) {
+	d.CoreProperties().SetKeywords(keywords)
+}
+
 // Comments returns the document's comments collection
 func (d *Document) Comments() *Comments {
 	return d.comments
@@ -288,6 +722,39 @@ func (d *Document) Settings() *Settings {
 	return d.settings
 }
 
+// pageColorPattern matches the values w:background/@w:color accepts
+var pageColorPattern = regexp.MustCompile(`^([0-9A-Fa-f]{6}|auto)$`)
+
+// SetPageColor sets the page background color to a hex value such as "FFF2CC" (or "auto")
+// and turns on the displayBackgroundShape setting Word needs to show it. Word shows page
+// colors on screen and prints them only when background printing is enabled. An empty hex
+// removes the color; any other value returns ErrInvalidArgument.
+func (d *Document) SetPageColor(hex string) error {
+	if d.docPart == nil {
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	hex = strings.TrimPrefix(hex, "#")
+	if hex == "" {
+		d.docPart.background = nil
+		d.settings.SetDisplayBackgroundShape(false)
+		return nil
+	}
+	if !pageColorPattern.MatchString(hex) {
+		return fmt.Errorf("%w: page color %q is not a 6-digit hex value", ErrInvalidArgument, hex)
+	}
+	d.docPart.background = &pageBackground{color: hex}
+	d.settings.SetDisplayBackgroundShape(true)
+	return nil
+}
+
+// PageColor returns the page background color, or "" when the document has none
+func (d *Document) PageColor() string {
+	if d.docPart == nil || d.docPart.background == nil {
+		return ""
+	}
+	return d.docPart.background.color
+}
+
 // Styles returns the document's styles collection
 func (d *Document) Styles() *Styles {
 	return d.styles
@@ -295,18 +762,57 @@ func (d *Document) Styles() *Styles {
 
 // SaveAs saves the document to the specified file path
 func (d *Document) SaveAs(path string) error {
-	if d != nil && d.docPart != nil {
-		d.docPart.updateXMLData()
+	overrides, err := d.prepareSave()
+	if err != nil {
+		return err
+	}
+	return d.pkg.saveAs(path, overrides)
+}
+
+// Bytes returns the document serialized as a .docx archive without writing a file
+func (d *Document) Bytes() ([]byte, error) {
+	overrides, err := d.prepareSave()
+	if err != nil {
+		return nil, err
+	}
+	return d.pkg.bytes(overrides)
+}
+
+// EstimatedSize returns the size in bytes the document would have if saved now. It
+// serializes the document in memory, so it costs about as much as a save.
+func (d *Document) EstimatedSize() (int64, error) {
+	data, err := d.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// prepareSave regenerates the parts backed by the document model before serializing. It
+// returns the part data that is only resolved for the output, such as per-section list
+// restarts, keyed by part URI.
+func (d *Document) prepareSave() (map[string][]byte, error) {
+	if d.docPart == nil {
+		return nil, d.updateSettingsPart()
 	}
-	return d.pkg.SaveAs(path)
+	d.docPart.updateXMLData()
+	if err := d.updateSettingsPart(); err != nil {
+		return nil, err
+	}
+	document, numbering, err := d.numbering.sectionRestarts(d.docPart.Part.Data)
+	if err != nil || document == nil {
+		return nil, err
+	}
+	return map[string][]byte{d.docPart.Part.URI: document, d.numbering.part.URI: numbering}, nil
 }
 
 // Save saves the document to its original location (if opened from file)
 func (d *Document) Save() error {
-	if d.docPart != nil {
-		d.docPart.updateXMLData()
+	overrides, err := d.prepareSave()
+	if err != nil {
+		return err
 	}
-	return d.pkg.Save()
+	return d.pkg.save(overrides)
 }
 
 // Close closes the document and releases any resources
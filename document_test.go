@@ -1,13 +1,22 @@
 package docx
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/png"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDocumentCreation(t *testing.T) {
@@ -1350,11 +1359,15 @@ func TestGetRowGetCell(t *testing.T) {
 	table.GetRow(0).GetCell(1).AddParagraph().AddRun("Test Value").SetBold(true)
 	table.GetRow(1).GetCell(0).AddParagraph().AddRun("Another Value").SetItalic(true)
 
-	// Verify content was added (trim whitespace because cells have default empty paragraph)
-	cellText := strings.TrimSpace(table.GetRow(0).GetCell(1).Text())
-	if cellText != "Test Value" {
+	// Verify content was added; the default empty paragraph adds no separator
+	cell := table.GetRow(0).GetCell(1)
+	if cellText := cell.Text(); cellText != "Test Value" {
 		t.Errorf("Expected 'Test Value', got '%s'", cellText)
 	}
+	cell.AddParagraph("Second")
+	if joined := cell.TextJoined(" | "); joined != "Test Value | Second" {
+		t.Errorf("Expected joined text 'Test Value | Second', got '%s'", joined)
+	}
 
 	// Test out of bounds
 	if table.GetRow(10) != nil {
@@ -1460,7 +1473,7 @@ func TestTemplateReplacement(t *testing.T) {
 		t.Errorf("Expected 1 table, got %d", len(doc.Tables()))
 	}
 
-	cellText := strings.TrimSpace(table.GetRow(0).GetCell(0).Text())
+	cellText := table.GetRow(0).GetCell(0).Text()
 	if cellText != "Name" {
 		t.Errorf("Expected 'Name' in first cell, got '%s'", cellText)
 	}
@@ -1483,3 +1496,4808 @@ func TestTemplateReplacement(t *testing.T) {
 		t.Errorf("Expected 1 table after reopening, got %d", len(reopened.Tables()))
 	}
 }
+
+func TestTableTranspose(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 3)
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 3; c++ {
+			table.Row(r).Cell(c).SetText(string(rune('A'+r)) + string(rune('1'+c)))
+		}
+	}
+	table.Row(0).Cell(0).SetWidth(2500)
+	table.Row(1).Cell(0).SetWidth(1500)
+	table.Row(0).SetRepeatHeader(true)
+
+	if err := table.Transpose(); err != nil {
+		t.Fatalf("Transpose failed: %v", err)
+	}
+	if len(table.Rows()) != 3 {
+		t.Fatalf("expected 3 rows after transpose, got %d", len(table.Rows()))
+	}
+	for r := 0; r < 3; r++ {
+		if len(table.Row(r).Cells()) != 2 {
+			t.Fatalf("expected 2 cells in row %d, got %d", r, len(table.Row(r).Cells()))
+		}
+		for c := 0; c < 2; c++ {
+			want := string(rune('A'+c)) + string(rune('1'+r))
+			if got := table.Row(r).Cell(c).Text(); got != want {
+				t.Errorf("cell (%d,%d): expected %q, got %q", r, c, want, got)
+			}
+		}
+	}
+	if w := table.Row(0).Cell(1).Width(); w != 1500 {
+		t.Fatalf("expected transposed cell to keep its width 1500, got %d", w)
+	}
+	if widths := table.columnWidths(); len(widths) != 2 || widths[0] != 2500 || widths[1] != 1500 {
+		t.Fatalf("expected grid rebuilt from cell widths, got %v", widths)
+	}
+	if table.Row(0).RepeatHeader() {
+		t.Fatal("expected row properties to be reset by Transpose")
+	}
+
+	indented := doc.AddTable(2, 2)
+	indented.Row(1).SetGridBefore(1, 500)
+	if err := indented.Transpose(); err == nil {
+		t.Fatalf("expected error when transposing a table with grid offsets")
+	}
+
+	merged := doc.AddTable(2, 2)
+	if err := merged.MergeCellsHorizontally(0, 0, 1); err != nil {
+		t.Fatalf("MergeCellsHorizontally failed: %v", err)
+	}
+	if err := merged.Transpose(); err == nil {
+		t.Fatalf("expected error when transposing a table with merged cells")
+	}
+}
+
+func TestEmbeddedFonts(t *testing.T) {
+	doc := NewDocument()
+	if fonts := doc.EmbeddedFonts(); len(fonts) != 0 {
+		t.Fatalf("expected no embedded fonts in new document, got %d", len(fonts))
+	}
+
+	doc.pkg.parts["word/fontTable.xml"] = &Part{
+		URI:         "word/fontTable.xml",
+		ContentType: ContentTypeWMLFontTable,
+		Data: []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:fonts xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <w:font w:name="Calibri"/>
+  <w:font w:name="Fancy"><w:embedRegular r:id="rId1" w:fontKey="{00000000-0000-0000-0000-000000000001}"/><w:embedBold r:id="rId2" w:fontKey="{00000000-0000-0000-0000-000000000002}"/></w:font>
+</w:fonts>`),
+	}
+	doc.pkg.contentTypes["/word/fontTable.xml"] = ContentTypeWMLFontTable
+	doc.pkg.ensureRelationship("word/document.xml", RelTypeFontTable, "fontTable.xml")
+	doc.pkg.parts["word/fonts/font1.odttf"] = &Part{URI: "word/fonts/font1.odttf", ContentType: ContentTypeObfuscatedFont, Data: []byte("regular")}
+	doc.pkg.parts["word/fonts/font2.odttf"] = &Part{URI: "word/fonts/font2.odttf", ContentType: ContentTypeObfuscatedFont, Data: []byte("bold")}
+	doc.pkg.defaultContentTypes["odttf"] = ContentTypeObfuscatedFont
+	doc.pkg.relations["word/fontTable.xml"] = []*Relationship{
+		{ID: "rId1", Type: RelTypeFont, Target: "fonts/font1.odttf"},
+		{ID: "rId2", Type: RelTypeFont, Target: "fonts/font2.odttf"},
+	}
+
+	output := filepath.Join(t.TempDir(), "fonts.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	fonts := reopened.EmbeddedFonts()
+	if len(fonts) != 2 {
+		t.Fatalf("expected 2 embedded fonts, got %d", len(fonts))
+	}
+	if fonts[0].Name != "Fancy" || fonts[0].Style != EmbeddedFontRegular || string(fonts[0].Data) != "regular" {
+		t.Errorf("unexpected regular font: %+v", fonts[0])
+	}
+	if fonts[1].Style != EmbeddedFontBold || string(fonts[1].Data) != "bold" || fonts[1].URI != "word/fonts/font2.odttf" {
+		t.Errorf("unexpected bold font: %+v", fonts[1])
+	}
+	if fonts[1].FontKey != "{00000000-0000-0000-0000-000000000002}" {
+		t.Errorf("unexpected font key %q", fonts[1].FontKey)
+	}
+}
+
+func TestSplitSectionAt(t *testing.T) {
+	doc := NewDocument()
+	doc.Sections()[0].SetMargins(720, 720, 720, 720)
+	original := doc.Sections()[0].startType
+	first := doc.AddParagraph("Portrait page")
+	doc.AddParagraph("Landscape page")
+
+	section, err := doc.SplitSectionAt(first, SectionStartOddPage)
+	if err != nil {
+		t.Fatalf("SplitSectionAt failed: %v", err)
+	}
+	if section.startType != original || doc.Sections()[0].startType != SectionStartOddPage {
+		t.Errorf("expected start type on the following section, got split=%s following=%s", section.startType, doc.Sections()[0].startType)
+	}
+	if section.marginTop != 720 {
+		t.Errorf("expected split section to inherit margins, got top=%d", section.marginTop)
+	}
+	doc.Sections()[0].SetPageSize(16838, 11906)
+
+	if _, err := doc.SplitSectionAt(first, SectionStartNewPage); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument when splitting at a paragraph that already ends a section, got %v", err)
+	}
+	if _, err := doc.SplitSectionAt(NewParagraph(), SectionStartNewPage); err == nil {
+		t.Errorf("expected error for paragraph outside the document")
+	}
+
+	output := filepath.Join(t.TempDir(), "split.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 2 {
+		t.Fatalf("expected 2 paragraphs, got %d", len(paragraphs))
+	}
+	split := paragraphs[0].section
+	if split == nil {
+		t.Fatalf("expected first paragraph to carry a section break")
+	}
+	if split.startType != original || split.pageWidth != 11906 {
+		t.Errorf("unexpected split section: start=%s width=%d", split.startType, split.pageWidth)
+	}
+	sections := reopened.Sections()
+	if len(sections) != 1 || sections[0].pageWidth != 16838 || sections[0].startType != SectionStartOddPage {
+		t.Errorf("expected trailing odd-page landscape section, got %+v", sections)
+	}
+}
+
+func TestParagraphSectionBreakRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph("Landscape content")
+	doc.AddParagraph("Portrait content")
+
+	if _, ok := paragraph.SectionBreak(); ok {
+		t.Fatalf("expected no section break on new paragraph")
+	}
+	landscape := NewSection(SectionStartNewPage)
+	landscape.SetPageSize(16838, 11906)
+	paragraph.SetSectionBreak(landscape)
+	if got, ok := paragraph.SectionBreak(); !ok || got != landscape {
+		t.Fatalf("expected SectionBreak to return the assigned section")
+	}
+
+	output := filepath.Join(t.TempDir(), "section-break.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	section, ok := reopened.Paragraphs()[0].SectionBreak()
+	if !ok {
+		t.Fatalf("expected section break after reopen")
+	}
+	if section.pageWidth != 16838 || section.pageHeight != 11906 || section.startType != SectionStartNewPage {
+		t.Errorf("unexpected section after reopen: %dx%d start=%s", section.pageWidth, section.pageHeight, section.startType)
+	}
+	if _, ok := reopened.Paragraphs()[1].SectionBreak(); ok {
+		t.Errorf("expected second paragraph to have no section break")
+	}
+
+	reopened.Paragraphs()[0].ClearSectionBreak()
+	if _, ok := reopened.Paragraphs()[0].SectionBreak(); ok {
+		t.Errorf("expected section break to be cleared")
+	}
+}
+
+func TestTrackedChangesRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.docPart.Part.Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p>
+      <w:r><w:t xml:space="preserve">Keep </w:t></w:r>
+      <w:ins w:id="3" w:author="Alice" w:date="2024-05-01T10:00:00Z"><w:r><w:t>added</w:t></w:r></w:ins>
+      <w:del w:id="7" w:author="Bob"><w:r><w:delText>removed</w:delText></w:r></w:del>
+    </w:p>
+  </w:body>
+</w:document>`)
+	if err := doc.docPart.loadFromXML(); err != nil {
+		t.Fatalf("loadFromXML failed: %v", err)
+	}
+
+	paragraph := doc.Paragraphs()[0]
+	runs := paragraph.Runs()
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	if !runs[1].IsInsertion() || runs[1].Text() != "added" {
+		t.Errorf("expected inserted run 'added', got %q (insertion=%v)", runs[1].Text(), runs[1].IsInsertion())
+	}
+	if rev, ok := runs[1].Revision(); !ok || rev.Author != "Alice" || rev.ID != 3 || rev.Date.Year() != 2024 {
+		t.Errorf("unexpected insertion revision: %+v", rev)
+	}
+	if !runs[2].IsDeletion() || runs[2].Text() != "removed" {
+		t.Errorf("expected deleted run 'removed', got %q (deletion=%v)", runs[2].Text(), runs[2].IsDeletion())
+	}
+	if paragraph.Text() != "Keep added" {
+		t.Errorf("expected visible text 'Keep added', got %q", paragraph.Text())
+	}
+
+	extra := paragraph.AddRun("later")
+	extra.SetRevision(RevisionInsertion, "Carol", time.Time{})
+	if rev, _ := extra.Revision(); rev.ID != 8 {
+		t.Errorf("expected new revision id 8, got %d", rev.ID)
+	}
+
+	output := filepath.Join(t.TempDir(), "revisions.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xmlData := string(doc.docPart.Part.Data)
+	if !strings.Contains(xmlData, `<w:del w:id="7" w:author="Bob"><w:r><w:delText>removed</w:delText></w:r></w:del>`) {
+		t.Errorf("expected deletion to be re-emitted, got %s", xmlData)
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs = reopened.Paragraphs()[0].Runs()
+	if len(runs) != 4 || !runs[1].IsInsertion() || !runs[2].IsDeletion() || !runs[3].IsInsertion() {
+		t.Fatalf("expected tracked changes to survive round trip")
+	}
+	if rev, _ := runs[3].Revision(); rev.Author != "Carol" {
+		t.Errorf("expected author Carol, got %q", rev.Author)
+	}
+}
+
+func TestOpenInvalidPackages(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain.docx")
+	if err := os.WriteFile(plain, []byte("not a zip file"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := OpenDocument(plain); !errors.Is(err, ErrNotADocx) {
+		t.Errorf("expected ErrNotADocx for plain file, got %v", err)
+	}
+
+	encrypted := filepath.Join(dir, "encrypted.docx")
+	data := append([]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, make([]byte, 504)...)
+	if err := os.WriteFile(encrypted, data, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := OpenDocument(encrypted); !errors.Is(err, ErrEncryptedDocument) {
+		t.Errorf("expected ErrEncryptedDocument, got %v", err)
+	}
+
+	emptyZip := filepath.Join(dir, "empty.docx")
+	file, err := os.Create(emptyZip)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	zw := zip.NewWriter(file)
+	w, _ := zw.Create("readme.txt")
+	w.Write([]byte("hello"))
+	zw.Close()
+	file.Close()
+	if _, err := OpenDocument(emptyZip); !errors.Is(err, ErrNotADocx) {
+		t.Errorf("expected ErrNotADocx for zip without content types, got %v", err)
+	}
+
+	if _, err := OpenDocument(filepath.Join(dir, "missing.docx")); err == nil || errors.Is(err, ErrNotADocx) {
+		t.Errorf("expected plain open error for missing file, got %v", err)
+	}
+}
+
+func TestPictureResize(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "resize.png")
+	createTestImage(t, imgPath, 4, 2)
+
+	doc := NewDocument()
+	_, pic, err := doc.AddPicture(imgPath, InchesToEMU(2), InchesToEMU(1))
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+
+	pic.SetSizeInches(4, 0)
+	if pic.WidthEMU() != InchesToEMU(4) || pic.HeightEMU() != InchesToEMU(2) {
+		t.Errorf("expected 4x2 inches keeping aspect ratio, got %dx%d", pic.WidthEMU(), pic.HeightEMU())
+	}
+	pic.ScaleBy(0.5)
+	if pic.WidthEMU() != InchesToEMU(2) || pic.HeightEMU() != InchesToEMU(1) {
+		t.Errorf("expected 2x1 inches after scaling, got %dx%d", pic.WidthEMU(), pic.HeightEMU())
+	}
+	pic.SetSize(PointsToEMU(300), PointsToEMU(100))
+
+	output := filepath.Join(t.TempDir(), "resized.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xml := string(doc.docPart.Part.Data); !strings.Contains(xml, `<wp:extent cx="3810000" cy="1270000"/>`) {
+		t.Errorf("expected resized extent in document XML")
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedPic := reopened.Paragraphs()[0].Runs()[0].Picture()
+	if reopenedPic == nil || reopenedPic.WidthEMU() != PointsToEMU(300) || reopenedPic.HeightEMU() != PointsToEMU(100) {
+		t.Errorf("expected resized picture after reopen, got %+v", reopenedPic)
+	}
+}
+
+func TestCaptionFieldsRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	first := doc.AddParagraph()
+	first.AddCaption("Figure", "Architecture overview")
+	second := doc.AddParagraph()
+	seq := second.AddCaption("Figure", "Deployment")
+
+	if first.Style() != "Caption" {
+		t.Errorf("expected Caption style, got %q", first.Style())
+	}
+	if second.Text() != "Figure 2: Deployment" {
+		t.Errorf("expected 'Figure 2: Deployment', got %q", second.Text())
+	}
+	if instr, ok := seq.FieldInstruction(); !ok || instr != `SEQ Figure \* ARABIC` {
+		t.Errorf("unexpected field instruction %q", instr)
+	}
+
+	output := filepath.Join(t.TempDir(), "captions.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xml := string(doc.docPart.Part.Data); !strings.Contains(xml, `<w:instrText xml:space="preserve"> SEQ Figure \* ARABIC </w:instrText>`) {
+		t.Errorf("expected SEQ instruction in document XML")
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraph := reopened.Paragraphs()[1]
+	if paragraph.Text() != "Figure 2: Deployment" {
+		t.Errorf("expected caption text after reopen, got %q", paragraph.Text())
+	}
+	runs := paragraph.Runs()
+	if len(runs) != 3 || !runs[1].IsField() || runs[1].Text() != "2" {
+		t.Fatalf("expected field run with cached result 2, got %d runs", len(runs))
+	}
+	third := reopened.AddParagraph()
+	third.AddCaption("Figure", "")
+	if third.Text() != "Figure 3" {
+		t.Errorf("expected numbering to continue after reopen, got %q", third.Text())
+	}
+
+	reopened.docPart.Part.Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:fldSimple w:instr=" PAGE "><w:r><w:t>4</w:t></w:r></w:fldSimple></w:p>
+    <w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText> TOC \o "1-3" </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>Entry</w:t></w:r></w:p>
+    <w:p><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>
+  </w:body>
+</w:document>`)
+	if err := reopened.docPart.loadFromXML(); err != nil {
+		t.Fatalf("loadFromXML failed: %v", err)
+	}
+	paragraphs := reopened.Paragraphs()
+	if instr, _ := paragraphs[0].Runs()[0].FieldInstruction(); instr != "PAGE" || paragraphs[0].Text() != "4" {
+		t.Errorf("expected simple PAGE field, got %q / %q", instr, paragraphs[0].Text())
+	}
+	if paragraphs[1].Text() != "Entry" {
+		t.Errorf("expected text of unterminated field to be kept, got %q", paragraphs[1].Text())
+	}
+}
+
+func TestCapsToggleOffRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	off := paragraph.AddRun("not caps")
+	off.SetAllCaps(false)
+	off.SetSmallCaps(false)
+	on := paragraph.AddRun("caps")
+	on.SetAllCaps(true)
+	plain := paragraph.AddRun("plain")
+	plain.SetSmallCaps(true)
+	plain.ClearSmallCaps()
+
+	output := filepath.Join(t.TempDir(), "caps.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xml := string(doc.docPart.Part.Data)
+	if !strings.Contains(xml, `<w:smallCaps w:val="0"/><w:caps w:val="0"/>`) {
+		t.Errorf("expected explicit caps overrides in XML, got %s", xml)
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs := reopened.Paragraphs()[0].Runs()
+	if runs[0].IsAllCaps() || runs[0].IsSmallCaps() || runs[0].allCaps == nil || runs[0].smallCaps == nil {
+		t.Errorf("expected explicit caps off to survive round trip")
+	}
+	if !runs[1].IsAllCaps() {
+		t.Errorf("expected all caps on second run")
+	}
+	if runs[2].smallCaps != nil || runs[2].allCaps != nil {
+		t.Errorf("expected no caps settings on cleared run")
+	}
+}
+
+func TestCorePropertiesPersist(t *testing.T) {
+	doc := NewDocument()
+	doc.SetTitle("Quarterly Report")
+	doc.SetAuthor("Jane Doe")
+	doc.SetSubject("Finance")
+	doc.SetKeywords("q3, revenue")
+	doc.CoreProperties().SetCategory("Reports")
+	doc.CoreProperties().SetLastModifiedBy("John Roe")
+	doc.CoreProperties().SetContentStatus("Final")
+	doc.CoreProperties().SetLanguage("en-GB")
+	doc.AddParagraph().AddRun("body")
+
+	output := filepath.Join(t.TempDir(), "core.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	props := reopened.CoreProperties()
+	if props.Title != "Quarterly Report" || props.Creator != "Jane Doe" || props.Subject != "Finance" ||
+		props.Keywords != "q3, revenue" || props.Category != "Reports" || props.LastModifiedBy != "John Roe" {
+		t.Errorf("unexpected core properties after round trip: %+v", props)
+	}
+	if props.ContentStatus != "Final" || props.Language != "en-GB" {
+		t.Errorf("expected content status and language to persist, got %q and %q", props.ContentStatus, props.Language)
+	}
+	if props.Created.IsZero() {
+		t.Errorf("expected created timestamp to persist")
+	}
+	if ct := reopened.pkg.lookupContentType("docProps/core.xml"); ct != ContentTypeOPCCoreProps {
+		t.Errorf("expected core properties content type, got %q", ct)
+	}
+}
+
+func TestSaveUpdatesModifiedAndRevision(t *testing.T) {
+	fresh := NewDocument()
+	if err := fresh.SaveAs(filepath.Join(t.TempDir(), "fresh.docx")); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if got := fresh.CoreProperties().Revision; got != "1" {
+		t.Errorf("expected first save of a new document to write revision 1, got %q", got)
+	}
+
+	doc := NewDocument()
+	props := doc.CoreProperties()
+	props.Modified = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	props.Revision = "4"
+
+	output := filepath.Join(t.TempDir(), "touch.docx")
+	before := time.Now().Add(-time.Second)
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if props.Revision != "5" || props.Modified.Before(before) {
+		t.Errorf("expected save to bump revision and modified time, got %s at %v", props.Revision, props.Modified)
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	reopened.CoreProperties().DisableAutoUpdate = true
+	if err := reopened.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reopened.Close()
+
+	final, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer final.Close()
+	if got := final.CoreProperties(); got.Revision != "5" || got.Modified.Before(before) {
+		t.Errorf("expected opt-out save to keep revision 5, got %s at %v", got.Revision, got.Modified)
+	}
+}
+
+func TestTableBorderHelpers(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.ClearBorders()
+
+	outside := TableBorder{Style: "double", Color: "000000", Size: 8}
+	inside := TableBorder{Style: "dotted", Color: "999999", Size: 4}
+	table.SetOutsideBorders(outside)
+	table.SetInsideBorders(inside)
+
+	for _, side := range []TableBorderSide{TableBorderTop, TableBorderLeft, TableBorderBottom, TableBorderRight} {
+		if border, ok := table.Border(side); !ok || border.Style != "double" {
+			t.Errorf("expected double outside border on %s, got %+v", side, border)
+		}
+	}
+	for _, side := range []TableBorderSide{TableBorderInsideH, TableBorderInsideV} {
+		if border, ok := table.Border(side); !ok || border.Style != "dotted" {
+			t.Errorf("expected dotted inside border on %s, got %+v", side, border)
+		}
+	}
+
+	table.SetAllBorders(TableBorder{Style: "single", Color: "FF0000", Size: 12})
+	if border, _ := table.Border(TableBorderInsideV); border.Color != "FF0000" {
+		t.Errorf("expected SetAllBorders to replace inside borders, got %+v", border)
+	}
+
+	table.ClearAllBorders()
+	output := filepath.Join(t.TempDir(), "borders.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedTable := reopened.Tables()[0]
+	for _, side := range []TableBorderSide{TableBorderTop, TableBorderLeft, TableBorderBottom, TableBorderRight, TableBorderInsideH, TableBorderInsideV} {
+		if border, ok := reopenedTable.Border(side); !ok || border.Style != "none" {
+			t.Errorf("expected none border on %s after ClearAllBorders, got %+v", side, border)
+		}
+	}
+}
+
+func TestParagraphBoxBorders(t *testing.T) {
+	doc := NewDocument()
+	note := doc.AddParagraph("Warning: boxed")
+	note.SetBox(ParagraphBorder{Style: "single", Color: "C00000", Size: 8, Space: 4})
+	all := doc.AddParagraph("All borders")
+	all.SetAllBorders(ParagraphBorder{Style: "dashed", Size: 4})
+
+	outputPath := filepath.Join(t.TempDir(), "paragraph-box.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paras := reopened.Paragraphs()
+	box := []ParagraphBorderSide{ParagraphBorderTop, ParagraphBorderLeft, ParagraphBorderBottom, ParagraphBorderRight}
+	for _, side := range box {
+		if border, ok := paras[0].Border(side); !ok || border.Style != "single" || border.Color != "C00000" {
+			t.Errorf("expected boxed border on %s, got %+v", side, border)
+		}
+		if border, ok := paras[1].Border(side); !ok || border.Style != "dashed" {
+			t.Errorf("expected dashed border on %s, got %+v", side, border)
+		}
+	}
+	if _, ok := paras[0].Border(ParagraphBorderBetween); ok {
+		t.Errorf("SetBox should not set the between border")
+	}
+	if border, ok := paras[1].Border(ParagraphBorderBetween); !ok || border.Style != "dashed" {
+		t.Errorf("expected between border from SetAllBorders, got %+v", border)
+	}
+}
+
+func TestTableCellContentSpacing(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.SetContentSpacing(0, 0)
+
+	cell := table.Row(0).Cell(0)
+	cell.SetText("dense")
+	extra := cell.AddParagraph("second line")
+	if before, after, _, _ := extra.Spacing(); before != 0 || after != 0 || !extra.spacingAfterSet {
+		t.Fatalf("expected new paragraph to pick up cell spacing, got %d/%d", before, after)
+	}
+	for _, row := range []*TableRow{table.AddRow(), table.InsertRowAt(0)} {
+		added := row.Cell(0).Paragraphs()[0]
+		if before, after, _, _ := added.Spacing(); before != 0 || after != 0 || !added.spacingAfterSet {
+			t.Fatalf("expected cells of added rows to pick up table spacing, got %d/%d", before, after)
+		}
+	}
+	if before, after, ok := table.ContentSpacing(); !ok || before != 0 || after != 0 {
+		t.Fatalf("unexpected table content spacing %d/%d", before, after)
+	}
+	other := table.Row(2).Cell(1)
+	other.SetContentSpacing(40, 80)
+	if before, after, ok := other.ContentSpacing(); !ok || before != 40 || after != 80 {
+		t.Fatalf("unexpected content spacing %d/%d", before, after)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "cell-spacing.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if !strings.Contains(string(doc.docPart.Part.Data), `<w:spacing w:before="0" w:after="0"/>`) {
+		t.Errorf("expected explicit zero spacing in cell paragraphs")
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paras := reopened.Tables()[0].Row(2).Cell(1).Paragraphs()
+	if before, after, _, _ := paras[0].Spacing(); before != 40 || after != 80 {
+		t.Errorf("expected spacing 40/80 after round trip, got %d/%d", before, after)
+	}
+}
+
+func TestTableCellSpacingRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.SetCellSpacing(72, "")
+	table.SetCellMargins(10, 10, 10, 10)
+	table.SetAlignment(TableAlignmentCenter)
+	table.SetIndent(144, "dxa")
+
+	outputPath := filepath.Join(t.TempDir(), "tbl-cell-spacing.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if !strings.Contains(string(doc.docPart.Part.Data), `<w:jc w:val="center"/><w:tblCellSpacing w:w="72" w:type="dxa"/><w:tblInd w:w="144" w:type="dxa"/>`) {
+		t.Errorf("expected tblCellSpacing between jc and tblInd in table properties")
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedTable := reopened.Tables()[0]
+	if value, typ, ok := reopenedTable.CellSpacing(); !ok || value != 72 || typ != "dxa" {
+		t.Errorf("unexpected cell spacing after round trip: %d %q %v", value, typ, ok)
+	}
+	if margins, ok := reopenedTable.CellMargins(); !ok || *margins.Top != 10 {
+		t.Errorf("expected cell margins to be preserved alongside cell spacing")
+	}
+
+	reopenedTable.ClearCellSpacing()
+	if _, _, ok := reopenedTable.CellSpacing(); ok {
+		t.Errorf("expected ClearCellSpacing to remove the setting")
+	}
+}
+
+func TestPictureImageDataResolvesTargets(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "target.png")
+	createTestImage(t, imgPath, 2, 2)
+
+	cases := []struct {
+		name    string
+		partURI string
+		target  string
+	}{
+		{"absolute", "word/media/image1.png", "/word/media/image1.png"},
+		{"outside word", "media/shared.png", "../media/shared.png"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewDocument()
+			_, pic, err := doc.AddPicture(imgPath, 0, 0)
+			if err != nil {
+				t.Fatalf("AddPicture failed: %v", err)
+			}
+			original := resolveRelationshipTarget(doc.docPart.Part.URI, pic.target)
+			part := doc.pkg.parts[original]
+			delete(doc.pkg.parts, original)
+			part.URI = tc.partURI
+			doc.pkg.parts[tc.partURI] = part
+			for _, rel := range doc.pkg.relations[doc.docPart.Part.URI] {
+				if rel.ID == pic.relID {
+					rel.Target = tc.target
+				}
+			}
+
+			output := filepath.Join(t.TempDir(), "targets.docx")
+			if err := doc.SaveAs(output); err != nil {
+				t.Fatalf("SaveAs failed: %v", err)
+			}
+			reopened, err := OpenDocument(output)
+			if err != nil {
+				t.Fatalf("OpenDocument failed: %v", err)
+			}
+			defer reopened.Close()
+
+			reopenedPic := reopened.Paragraphs()[0].Runs()[0].Picture()
+			if reopenedPic == nil {
+				t.Fatalf("expected picture after reopening")
+			}
+			data, err := reopenedPic.ImageData()
+			if err != nil {
+				t.Fatalf("ImageData failed: %v", err)
+			}
+			if len(data) == 0 {
+				t.Errorf("expected image bytes for target %s", tc.target)
+			}
+		})
+	}
+}
+
+func TestLinkedPictureRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	url := "https://example.com/images/logo.png"
+	_, pic, err := doc.AddLinkedPicture(url, InchesToEMU(1), InchesToEMU(0.5))
+	if err != nil {
+		t.Fatalf("AddLinkedPicture failed: %v", err)
+	}
+	if !pic.IsLinked() || pic.LinkTarget() != url {
+		t.Fatalf("expected linked picture targeting %s", url)
+	}
+	if _, err := pic.ImageData(); err == nil {
+		t.Errorf("expected ImageData to fail for a linked-only picture")
+	}
+	if _, _, err := doc.AddLinkedPicture(url, 0, 0); err == nil {
+		t.Errorf("expected error when linked picture size is missing")
+	}
+
+	output := filepath.Join(t.TempDir(), "linked.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if !strings.Contains(string(doc.docPart.Part.Data), `r:link="`+pic.linkRelID+`"`) {
+		t.Errorf("expected r:link in blip")
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	var found *Picture
+	for _, paragraph := range reopened.Paragraphs() {
+		for _, run := range paragraph.Runs() {
+			if run.Picture() != nil {
+				found = run.Picture()
+			}
+		}
+	}
+	if found == nil || !found.IsLinked() || found.LinkTarget() != url {
+		t.Fatalf("expected linked picture after round trip, got %+v", found)
+	}
+	if found.WidthEMU() != InchesToEMU(1) {
+		t.Errorf("unexpected linked picture width %d", found.WidthEMU())
+	}
+	_, mode, ok := reopened.docPart.relationshipTarget(found.linkRelID)
+	if !ok || mode != "External" {
+		t.Errorf("expected External target mode, got %q", mode)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	detached := NewParagraph()
+	if _, _, err := detached.AddPicture("missing.png", 0, 0); !errors.Is(err, ErrNotAttached) {
+		t.Errorf("expected ErrNotAttached for detached paragraph, got %v", err)
+	}
+	if _, err := NewRun("x").AddPicture("missing.png", 0, 0); !errors.Is(err, ErrNotAttached) {
+		t.Errorf("expected ErrNotAttached for detached run, got %v", err)
+	}
+
+	doc := NewDocument()
+	unsupported := filepath.Join(t.TempDir(), "image.xyz")
+	if err := os.WriteFile(unsupported, []byte("data"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, _, err := doc.AddPicture(unsupported, 0, 0); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("expected ErrUnsupportedImage, got %v", err)
+	}
+
+	if _, err := doc.AddHeading("too deep", 12); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for heading level, got %v", err)
+	}
+	table := doc.AddTable(2, 2)
+	if err := table.MergeCellsHorizontally(5, 0, 1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for merge, got %v", err)
+	}
+	if err := table.MergeCellsHorizontally(0, 1, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for reversed horizontal merge, got %v", err)
+	}
+	if err := table.MergeCellsVertically(0, 0, 5); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for vertical merge, got %v", err)
+	}
+
+	if err := doc.docPart.RemoveParagraph(NewParagraph()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a foreign paragraph, got %v", err)
+	}
+	if err := doc.docPart.RemoveTable(NewTable(1, 1)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a foreign table, got %v", err)
+	}
+	if err := doc.docPart.RemoveParagraph(nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for a nil paragraph, got %v", err)
+	}
+	if _, _, err := doc.AddLinkedPicture("", 1, 1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for an empty link target, got %v", err)
+	}
+
+	if _, err := doc.docPart.headerFromRelationship("rId999"); !errors.Is(err, ErrRelationshipNotFound) {
+		t.Errorf("expected ErrRelationshipNotFound, got %v", err)
+	}
+
+	var nilSection *Section
+	if _, err := nilSection.HeaderOfType(HeaderTypeDefault); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for a nil section, got %v", err)
+	}
+	if err := doc.Save(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument when saving without a path, got %v", err)
+	}
+
+	empty := &Document{}
+	if _, err := empty.GetXML(); !errors.Is(err, ErrPartNotFound) {
+		t.Errorf("expected ErrPartNotFound, got %v", err)
+	}
+}
+
+func TestRunThemeColorRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	run := paragraph.AddRun("brand")
+	run.SetColor("4472C4")
+	run.SetThemeColor("accent1", "", "BF")
+	paragraph.AddRun("plain")
+
+	output := filepath.Join(t.TempDir(), "theme-color.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if !strings.Contains(string(doc.docPart.Part.Data), `<w:color w:val="4472C4" w:themeColor="accent1" w:themeShade="BF"/>`) {
+		t.Errorf("expected theme color attributes in run properties")
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs := reopened.Paragraphs()[0].Runs()
+	if name, tint, shade := runs[0].ThemeColor(); name != "accent1" || tint != "" || shade != "BF" {
+		t.Errorf("unexpected theme color after round trip: %q %q %q", name, tint, shade)
+	}
+	if runs[0].Color() != "4472C4" {
+		t.Errorf("expected explicit color to be kept, got %s", runs[0].Color())
+	}
+	if name, _, _ := runs[1].ThemeColor(); name != "" {
+		t.Errorf("expected no theme color on plain run, got %q", name)
+	}
+}
+
+func TestDocumentTheme(t *testing.T) {
+	doc := NewDocument()
+	if _, ok := doc.Theme(); ok {
+		t.Fatalf("expected no theme in new document")
+	}
+
+	doc.pkg.parts["word/theme/theme1.xml"] = &Part{
+		URI:         "word/theme/theme1.xml",
+		ContentType: ContentTypeTheme,
+		Data: []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<a:theme xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" name="Office Theme">
+  <a:themeElements>
+    <a:clrScheme name="Office">
+      <a:dk1><a:sysClr val="windowText" lastClr="000000"/></a:dk1>
+      <a:lt1><a:sysClr val="window" lastClr="FFFFFF"/></a:lt1>
+      <a:dk2><a:srgbClr val="44546A"/></a:dk2>
+      <a:lt2><a:srgbClr val="E7E6E6"/></a:lt2>
+      <a:accent1><a:srgbClr val="4472c4"/></a:accent1>
+      <a:hlink><a:srgbClr val="0563C1"/></a:hlink>
+    </a:clrScheme>
+    <a:fontScheme name="Office">
+      <a:majorFont><a:latin typeface="Calibri Light"/><a:ea typeface=""/><a:cs typeface=""/></a:majorFont>
+      <a:minorFont><a:latin typeface="Calibri"/><a:ea typeface="MS Mincho"/><a:cs typeface=""/></a:minorFont>
+    </a:fontScheme>
+  </a:themeElements>
+</a:theme>`),
+	}
+	doc.pkg.contentTypes["/word/theme/theme1.xml"] = ContentTypeTheme
+	doc.pkg.ensureRelationship("word/document.xml", RelTypeTheme, "theme/theme1.xml")
+
+	output := filepath.Join(t.TempDir(), "theme.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	theme, ok := reopened.Theme()
+	if !ok {
+		t.Fatalf("expected theme to be parsed")
+	}
+	if theme.Name != "Office Theme" || theme.ColorSchemeName != "Office" {
+		t.Errorf("unexpected theme names: %q %q", theme.Name, theme.ColorSchemeName)
+	}
+	if color, ok := theme.Color("accent1"); !ok || color != "4472C4" {
+		t.Errorf("expected accent1 4472C4, got %q", color)
+	}
+	if color, ok := theme.Color("text1"); !ok || color != "000000" {
+		t.Errorf("expected text1 to resolve to dk1 system color, got %q", color)
+	}
+	if color, _ := theme.Color("hyperlink"); color != "0563C1" {
+		t.Errorf("expected hyperlink color, got %q", color)
+	}
+	if theme.MajorFont.Latin != "Calibri Light" || theme.MinorFont.Latin != "Calibri" || theme.MinorFont.EastAsian != "MS Mincho" {
+		t.Errorf("unexpected theme fonts: %+v %+v", theme.MajorFont, theme.MinorFont)
+	}
+}
+
+func TestAllParagraphs(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("body one")
+	table := doc.AddTable(1, 1)
+	table.Row(0).Cell(0).SetText("cell")
+	nested := table.Row(0).Cell(0).AddTable(1, 1)
+	nested.Row(0).Cell(0).SetText("nested")
+	doc.AddParagraph("body two")
+
+	header, err := doc.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	header.AddParagraph("header text")
+	footer, err := doc.Footer()
+	if err != nil {
+		t.Fatalf("Footer failed: %v", err)
+	}
+	footer.AddParagraph("footer text")
+
+	output := filepath.Join(t.TempDir(), "all-paragraphs.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	var texts []string
+	for _, paragraph := range reopened.AllParagraphs() {
+		if text := paragraph.Text(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	expected := []string{"body one", "cell", "nested", "body two", "header text", "footer text"}
+	if strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected AllParagraphs order: %v", texts)
+	}
+	if len(reopened.Paragraphs()) >= len(reopened.AllParagraphs()) {
+		t.Errorf("expected AllParagraphs to include more than body paragraphs")
+	}
+}
+
+func TestSectionWatermarks(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("body")
+	section := doc.Sections()[0]
+	if err := section.SetTextWatermark("", WatermarkOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for empty watermark text, got %v", err)
+	}
+	if err := section.SetTextWatermark("CONFIDENTIAL", WatermarkOptions{Color: "#FF0000", Opacity: 0.3}); err != nil {
+		t.Fatalf("SetTextWatermark failed: %v", err)
+	}
+	if err := section.SetTextWatermark("DRAFT", WatermarkOptions{}); err != nil {
+		t.Fatalf("SetTextWatermark failed: %v", err)
+	}
+	header, _ := section.Header()
+	header.AddParagraph("header text")
+
+	output := filepath.Join(t.TempDir(), "watermark.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	headerXML := string(header.part.Data)
+	if strings.Count(headerXML, textWatermarkID) != 1 || !strings.Contains(headerXML, `string="DRAFT"`) {
+		t.Errorf("expected a single DRAFT watermark shape in header XML, got %s", headerXML)
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	reopenedSection := reopened.Sections()[0]
+	watermark, ok := reopenedSection.Watermark()
+	if !ok || watermark.Text != "DRAFT" {
+		t.Fatalf("expected DRAFT watermark after round trip, got %+v", watermark)
+	}
+
+	imgPath := filepath.Join(t.TempDir(), "logo.png")
+	createTestImage(t, imgPath, 20, 10)
+	if err := reopenedSection.SetImageWatermark(imgPath); err != nil {
+		t.Fatalf("SetImageWatermark failed: %v", err)
+	}
+	second := filepath.Join(t.TempDir(), "watermark-image.docx")
+	if err := reopened.SaveAs(second); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened.Close()
+
+	final, err := OpenDocument(second)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer final.Close()
+	finalSection := final.Sections()[0]
+	watermark, ok = finalSection.Watermark()
+	if !ok || watermark.Text != "" || !strings.HasPrefix(watermark.ImageTarget, "media/") {
+		t.Fatalf("expected picture watermark, got %+v", watermark)
+	}
+	finalHeader, _ := finalSection.Header()
+	if text := finalHeader.Paragraphs()[1].Text(); text != "header text" {
+		t.Errorf("expected header content to be preserved, got %q", text)
+	}
+
+	finalSection.ClearWatermark()
+	if _, ok := finalSection.Watermark(); ok {
+		t.Errorf("expected ClearWatermark to remove the watermark")
+	}
+}
+
+func TestMultilineTextRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	run := paragraph.AddRun("")
+	run.SetMultilineText("first line\r\nsecond line\n\nafter blank")
+	trailing := paragraph.AddRun("ends with break")
+	trailing.AddBreak(BreakTypeText)
+	lines := doc.AddParagraphLines("Name:", "Address line 1\nAddress line 2")
+
+	output := filepath.Join(t.TempDir(), "multiline.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xml := string(doc.docPart.Part.Data)
+	if !strings.Contains(xml, `<w:t>first line</w:t><w:br/><w:t>second line</w:t><w:br/><w:br/><w:t>after blank</w:t>`) {
+		t.Errorf("expected line breaks between text segments, got %s", xml)
+	}
+	if len(lines.Runs()) != 1 {
+		t.Errorf("expected AddParagraphLines to create a single run, got %d", len(lines.Runs()))
+	}
+
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paras := reopened.Paragraphs()
+	runs := paras[0].Runs()
+	if runs[0].Text() != "first line\nsecond line\n\nafter blank" {
+		t.Errorf("unexpected multiline text after round trip: %q", runs[0].Text())
+	}
+	if runs[1].Text() != "ends with break" || !runs[1].HasBreak() {
+		t.Errorf("expected trailing break to remain a run break, got %q break=%v", runs[1].Text(), runs[1].HasBreak())
+	}
+	if got := paras[1].Text(); got != "Name:\nAddress line 1\nAddress line 2" {
+		t.Errorf("unexpected AddParagraphLines text: %q", got)
+	}
+
+	literal := NewRun("")
+	literal.SetText("keep\nas is")
+	if got := literal.ToXML(); strings.Contains(got, "<w:br/>") || !strings.Contains(got, "keep\nas is") {
+		t.Errorf("expected SetText to write newlines literally, got %s", got)
+	}
+	runs[0].SetText("single line")
+	if got := runs[0].ToXML(); strings.Contains(got, "<w:br/>") {
+		t.Errorf("expected SetText to reset multiline text, got %s", got)
+	}
+}
+
+func TestDocumentRootNamespacesPreserved(t *testing.T) {
+	dir := t.TempDir()
+	doc := NewDocument()
+	doc.docPart.Part.Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" xmlns:custom="urn:example:custom" mc:Ignorable="w14">
+  <w:body>
+    <w:p w14:paraId="1A2B3C4D"><w:r><w:t>kept</w:t></w:r></w:p>
+    <w:sectPr/>
+  </w:body>
+</w:document>`)
+	if err := doc.docPart.loadFromXML(); err != nil {
+		t.Fatalf("loadFromXML failed: %v", err)
+	}
+	doc.AddParagraph("added")
+
+	output := filepath.Join(dir, "namespaces.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xmlData := string(doc.docPart.Part.Data)
+	for _, expected := range []string{
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"`,
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"`,
+		`xmlns:custom="urn:example:custom"`,
+		`mc:Ignorable="w14"`,
+	} {
+		if !strings.Contains(xmlData, expected) {
+			t.Errorf("expected root element to contain %s", expected)
+		}
+	}
+	if strings.Count(xmlData, `xmlns:w="`) != 1 {
+		t.Errorf("expected w namespace to be declared once")
+	}
+	decoder := xml.NewDecoder(strings.NewReader(xmlData))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("regenerated document.xml is not well-formed: %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestNumberingSetBulletCharacter(t *testing.T) {
+	doc := NewDocument()
+	numbering := doc.Numbering()
+	bulletID := numbering.BulletedListID()
+	doc.AddBulletedParagraph("Dash", 0)
+	doc.AddBulletedParagraph("Check", 1)
+
+	if err := numbering.SetBulletCharacter(bulletID, 0, "–", ""); err != nil {
+		t.Fatalf("SetBulletCharacter level 0 failed: %v", err)
+	}
+	if err := numbering.SetBulletCharacter(bulletID, 1, "✓", "Segoe UI Symbol"); err != nil {
+		t.Fatalf("SetBulletCharacter level 1 failed: %v", err)
+	}
+	if err := numbering.SetBulletCharacter(99, 0, "•", ""); !errors.Is(err, ErrNumberingNotFound) {
+		t.Fatalf("expected ErrNumberingNotFound, got %v", err)
+	}
+	if err := numbering.SetBulletCharacter(bulletID, 9, "•", ""); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := numbering.SetBulletCharacter(bulletID, 0, "", ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "bullets.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	data := string(reopened.Numbering().Part().Data)
+	if strings.Contains(data, `<w:lvlText w:val="•"/>`) {
+		t.Fatalf("expected default bullet glyph to be replaced:\n%s", data)
+	}
+	if !strings.Contains(data, `<w:lvlText w:val="–"/>`) || !strings.Contains(data, `<w:lvlText w:val="✓"/>`) {
+		t.Fatalf("expected custom bullet glyphs in numbering part:\n%s", data)
+	}
+	if !strings.Contains(data, `w:ascii="Segoe UI Symbol"`) {
+		t.Fatalf("expected level 1 bullet font in numbering part:\n%s", data)
+	}
+	if strings.Contains(data, `w:ascii="Symbol"`) {
+		t.Fatalf("expected Symbol font to be removed from level 0:\n%s", data)
+	}
+	if !strings.Contains(data, `<w:multiLevelType w:val="hybridMultilevel"/>`) {
+		t.Fatalf("expected bullet definition to become multi-level:\n%s", data)
+	}
+	if !strings.Contains(data, `<w:lvlText w:val="%1."/>`) {
+		t.Fatalf("expected decimal list definition to be untouched:\n%s", data)
+	}
+	if err := xml.Unmarshal([]byte(data), new(struct{})); err != nil {
+		t.Fatalf("numbering part is not well-formed: %v", err)
+	}
+}
+
+func TestOutlineAndLetteredLists(t *testing.T) {
+	doc := NewDocument()
+	numbering := doc.Numbering()
+	outlineID := numbering.OutlineListID()
+	letteredID := numbering.LetteredListID()
+	if outlineID == 0 || letteredID == 0 || outlineID == letteredID {
+		t.Fatalf("expected distinct list IDs, got outline=%d lettered=%d", outlineID, letteredID)
+	}
+	if outlineID == numbering.DecimalListID() || outlineID == numbering.BulletedListID() {
+		t.Fatalf("expected outline list to use a new numId, got %d", outlineID)
+	}
+	if again := numbering.OutlineListID(); again != outlineID {
+		t.Fatalf("expected OutlineListID to be stable, got %d then %d", outlineID, again)
+	}
+
+	doc.AddParagraph("Scope").SetNumbering(outlineID, 0)
+	doc.AddParagraph("Definitions").SetNumbering(outlineID, 1)
+	doc.AddParagraph("Option").SetNumbering(letteredID, 0)
+
+	outputPath := filepath.Join(t.TempDir(), "lists.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if id := reopened.Numbering().OutlineListID(); id != outlineID {
+		t.Fatalf("expected reopened outline list ID %d, got %d", outlineID, id)
+	}
+	if id := reopened.Numbering().LetteredListID(); id != letteredID {
+		t.Fatalf("expected reopened lettered list ID %d, got %d", letteredID, id)
+	}
+	data := string(reopened.Numbering().Part().Data)
+	for _, fragment := range []string{
+		`<w:lvlText w:val="%1.%2.%3."/>`,
+		`<w:lvl w:ilvl="8">`,
+		`<w:numFmt w:val="upperLetter"/>`,
+		`<w:numFmt w:val="lowerRoman"/>`,
+	} {
+		if !strings.Contains(data, fragment) {
+			t.Fatalf("expected numbering part to contain %s:\n%s", fragment, data)
+		}
+	}
+	if strings.Index(data, `w:abstractNumId="3"`) > strings.Index(data, `<w:num w:numId="1">`) {
+		t.Fatalf("expected abstract definitions to precede num instances:\n%s", data)
+	}
+	if numID, level, ok := reopened.Paragraphs()[1].Numbering(); !ok || numID != outlineID || level != 1 {
+		t.Fatalf("expected outline numbering on second paragraph, got (ok=%v, id=%d, level=%d)", ok, numID, level)
+	}
+}
+
+func TestTableCellAtHonorsMerges(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(3, 3)
+	if err := table.MergeCellsVertically(2, 0, 2); err != nil {
+		t.Fatalf("MergeCellsVertically failed: %v", err)
+	}
+	if err := table.MergeCellsHorizontally(0, 0, 1); err != nil {
+		t.Fatalf("MergeCellsHorizontally failed: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "cellat.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	parsed := reopened.Tables()[0]
+	cases := []struct {
+		row, column int
+		want        *TableCell
+	}{
+		{0, 0, parsed.Row(0).Cell(0)},
+		{0, 1, parsed.Row(0).Cell(0)},
+		{0, 2, parsed.Row(0).Cell(1)},
+		{1, 0, parsed.Row(1).Cell(0)},
+		{1, 2, parsed.Row(0).Cell(1)},
+		{2, 2, parsed.Row(0).Cell(1)},
+		{2, 1, parsed.Row(2).Cell(1)},
+	}
+	for _, tc := range cases {
+		if got := parsed.CellAt(tc.row, tc.column); got != tc.want {
+			t.Fatalf("CellAt(%d, %d) returned the wrong cell", tc.row, tc.column)
+		}
+	}
+	if got := parsed.CellAt(0, 3); got != nil {
+		t.Fatal("expected nil for a column beyond the grid")
+	}
+	if got := parsed.CellAt(3, 0); got != nil {
+		t.Fatal("expected nil for a row beyond the table")
+	}
+}
+
+func TestParagraphCrossReferenceRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph("See ")
+	paragraph.AddCrossReference("_Toc_Introduction", "Introduction")
+
+	outputPath := filepath.Join(t.TempDir(), "crossref.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	runs := reopened.Paragraphs()[0].Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	link := runs[1]
+	if link.HyperlinkAnchor() != "_Toc_Introduction" || link.HyperlinkURL() != "" {
+		t.Fatalf("expected anchor hyperlink, got anchor=%q url=%q", link.HyperlinkAnchor(), link.HyperlinkURL())
+	}
+	if link.Text() != "Introduction" {
+		t.Fatalf("expected display text Introduction, got %q", link.Text())
+	}
+	if link.Color() != hyperlinkColor || link.Underline() != WDUnderlineSingle {
+		t.Fatalf("expected hyperlink styling, got color=%q underline=%q", link.Color(), link.Underline())
+	}
+}
+
+func TestParagraphRightToLeftRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	arabic := doc.AddParagraph("مرحبا بالعالم")
+	arabic.SetRightToLeft(true)
+	arabic.SetIndentation(720, 0, 0, 0)
+	arabic.AddTabStop(2880, WDTabAlignmentLeft, WDTabLeaderNone)
+	ltr := doc.AddParagraph("Left to right")
+	ltr.SetRightToLeft(false)
+	doc.AddParagraph("Inherited")
+
+	xmlData := string(doc.docPart.Data)
+	if !strings.Contains(xmlData, `<w:bidi/>`) || !strings.Contains(xmlData, `<w:bidi w:val="0"/>`) {
+		t.Fatalf("expected bidi elements in document XML:\n%s", xmlData)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "rtl.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if !paragraphs[0].RightToLeft() || paragraphs[0].Alignment() != WDAlignParagraphLeft {
+		t.Fatalf("expected first paragraph right-to-left with default alignment, got rtl=%v align=%q", paragraphs[0].RightToLeft(), paragraphs[0].Alignment())
+	}
+	if paragraphs[1].RightToLeft() || paragraphs[1].rightToLeft == nil {
+		t.Fatal("expected explicit left-to-right override on second paragraph")
+	}
+	if paragraphs[2].rightToLeft != nil {
+		t.Fatal("expected third paragraph to inherit reading direction")
+	}
+}
+
+func TestNumberedParagraphIndentOverride(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddNumberedParagraph("Indented item", 0)
+	paragraph.SetIndentation(1440, 0, 0, 360)
+	paragraph.SetAlignment(WDAlignParagraphJustify)
+
+	doc.docPart.updateXMLData()
+	xmlData := string(doc.docPart.Data)
+	numPr := strings.Index(xmlData, "<w:numPr>")
+	ind := strings.Index(xmlData, "<w:ind ")
+	jc := strings.Index(xmlData, "<w:jc ")
+	if numPr < 0 || ind < 0 || jc < 0 || !(numPr < ind && ind < jc) {
+		t.Fatalf("expected numPr, ind and jc in schema order:\n%s", xmlData)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "numbered-indent.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	parsed := reopened.Paragraphs()[0]
+	if numID, level, ok := parsed.Numbering(); !ok || numID != defaultDecimalNumID || level != 0 {
+		t.Fatalf("expected decimal numbering to survive, got (ok=%v, id=%d, level=%d)", ok, numID, level)
+	}
+	if left, right, firstLine, hanging := parsed.Indentation(); left != 1440 || right != 0 || firstLine != 0 || hanging != 360 {
+		t.Fatalf("expected indentation override to survive, got (%d, %d, %d, %d)", left, right, firstLine, hanging)
+	}
+}
+
+func TestGlossaryDocumentPreserved(t *testing.T) {
+	const glossaryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:glossaryDocument xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:docParts><w:docPart><w:docPartPr><w:name w:val="Disclaimer"/><w:category><w:name w:val="General"/><w:gallery w:val="autoTxt"/></w:category><w:guid w:val="{5B2C4C5E-6D0B-4E0E-9C3E-1F0B2B3A4C5D}"/></w:docPartPr><w:docPartBody><w:p><w:r><w:t>Confidential draft</w:t></w:r></w:p></w:docPartBody></w:docPart></w:docParts></w:glossaryDocument>`
+
+	doc := NewDocument()
+	doc.AddParagraph("Body")
+	if paragraphs := doc.GlossaryParagraphs(); paragraphs != nil {
+		t.Fatalf("expected no glossary paragraphs before adding a glossary, got %d", len(paragraphs))
+	}
+	doc.pkg.parts["word/glossary/document.xml"] = &Part{
+		URI:         "word/glossary/document.xml",
+		ContentType: ContentTypeWMLGlossary,
+		Data:        []byte(glossaryXML),
+	}
+	doc.pkg.contentTypes["/word/glossary/document.xml"] = ContentTypeWMLGlossary
+	doc.pkg.ensureRelationship("word/document.xml", RelTypeGlossary, "glossary/document.xml")
+
+	firstPath := filepath.Join(t.TempDir(), "glossary.docx")
+	if err := doc.SaveAs(firstPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(firstPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+
+	paragraphs := reopened.GlossaryParagraphs()
+	if len(paragraphs) != 1 || paragraphs[0].Text() != "Confidential draft" {
+		t.Fatalf("expected glossary paragraph, got %d paragraphs", len(paragraphs))
+	}
+	if len(reopened.Paragraphs()) != 1 {
+		t.Fatalf("expected glossary content to stay out of the body, got %d paragraphs", len(reopened.Paragraphs()))
+	}
+	paragraphs[0].AddRun(" (edited)")
+
+	secondPath := filepath.Join(t.TempDir(), "glossary-resaved.docx")
+	if err := reopened.SaveAs(secondPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened.Close()
+
+	archive, err := zip.OpenReader(secondPath)
+	if err != nil {
+		t.Fatalf("failed to open saved package: %v", err)
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if file.Name != "word/glossary/document.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open glossary part: %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read glossary part: %v", err)
+		}
+		if string(data) != glossaryXML {
+			t.Fatalf("expected glossary part to be preserved byte-for-byte, got:\n%s", data)
+		}
+		return
+	}
+	t.Fatal("expected glossary part in saved package")
+}
+
+func TestExtractText(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("Title")
+	table := doc.AddTable(2, 2)
+	table.Row(0).Cell(0).SetText("a1")
+	table.Row(0).Cell(1).SetText("b1")
+	table.Row(1).Cell(0).SetText("a2")
+	table.Row(1).Cell(1).SetText("b2")
+	lines := doc.AddParagraph("")
+	lines.AddRun("").SetMultilineText("first\nsecond")
+	tracked := doc.AddParagraph("kept")
+	tracked.AddRun(" removed").SetRevision(RevisionDeletion, "Reviewer", time.Time{})
+
+	outputPath := filepath.Join(t.TempDir(), "extract.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	text, err := ExtractText(outputPath)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	want := "Title\na1\tb1\na2\tb2\nfirst\nsecond\nkept"
+	if text != want {
+		t.Fatalf("expected %q, got %q", want, text)
+	}
+
+	if _, err := ExtractText(filepath.Join(t.TempDir(), "missing.docx")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func BenchmarkExtractText(b *testing.B) {
+	path := writeBenchmarkDocument(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ExtractText(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOpenDocumentText(b *testing.B) {
+	path := writeBenchmarkDocument(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc, err := OpenDocument(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var text strings.Builder
+		for _, paragraph := range doc.AllParagraphs() {
+			text.WriteString(paragraph.Text())
+			text.WriteString("\n")
+		}
+		doc.Close()
+	}
+}
+
+func writeBenchmarkDocument(b *testing.B) string {
+	b.Helper()
+	doc := NewDocument()
+	for i := 0; i < 500; i++ {
+		paragraph := doc.AddParagraph("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ")
+		paragraph.AddRun("Sed do eiusmod tempor incididunt.").SetBold(true)
+	}
+	table := doc.AddTable(50, 4)
+	for _, row := range table.Rows() {
+		for _, cell := range row.Cells() {
+			cell.SetText("cell")
+		}
+	}
+	path := filepath.Join(b.TempDir(), "benchmark.docx")
+	if err := doc.SaveAs(path); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func TestTableCaptionAndDescriptionRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.SetCaption("Quarterly revenue")
+	table.SetDescription(`Revenue by region & quarter, in "USD"`)
+
+	outputPath := filepath.Join(t.TempDir(), "table-accessibility.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	parsed := reopened.Tables()[0]
+	if parsed.Caption() != "Quarterly revenue" {
+		t.Fatalf("expected caption to round-trip, got %q", parsed.Caption())
+	}
+	if parsed.Description() != `Revenue by region & quarter, in "USD"` {
+		t.Fatalf("expected description to round-trip, got %q", parsed.Description())
+	}
+	xmlData := string(reopened.docPart.Data)
+	if !strings.Contains(xmlData, `<w:tblCaption w:val="Quarterly revenue"/><w:tblDescription`) {
+		t.Fatalf("expected caption followed by description in tblPr:\n%s", xmlData)
+	}
+}
+
+func TestDateFieldRoundTrip(t *testing.T) {
+	now := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	cases := map[string]string{
+		"MMMM d, yyyy":        "March 5, 2024",
+		"dd.MM.yy HH:mm:ss":   "05.03.24 14:07:09",
+		"dddd 'the' d":        "Tuesday the 5",
+		"h:mm AM/PM":          "2:07 PM",
+		"":                    "3/5/2024",
+		"ddd, d MMM yyyy H:m": "Tue, 5 Mar 2024 14:7",
+	}
+
+	doc := NewDocument()
+	formats := make([]string, 0, len(cases))
+	for format, want := range cases {
+		run := doc.AddParagraph("Date: ").addDateField(format, now)
+		if run.Text() != want {
+			t.Fatalf("format %q: expected cached value %q, got %q", format, want, run.Text())
+		}
+		formats = append(formats, format)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "date-field.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	for i, paragraph := range reopened.Paragraphs() {
+		runs := paragraph.Runs()
+		if len(runs) != 2 {
+			t.Fatalf("paragraph %d: expected 2 runs, got %d", i, len(runs))
+		}
+		format, ok := runs[1].DateFieldFormat()
+		if !ok || format != formats[i] {
+			t.Fatalf("paragraph %d: expected date format %q, got %q (ok=%v)", i, formats[i], format, ok)
+		}
+		if runs[1].Text() != cases[formats[i]] {
+			t.Fatalf("paragraph %d: expected cached value %q, got %q", i, cases[formats[i]], runs[1].Text())
+		}
+	}
+	if _, ok := reopened.Paragraphs()[0].Runs()[0].DateFieldFormat(); ok {
+		t.Fatal("expected plain text run not to report a date format")
+	}
+}
+
+func TestTableCellMarginsRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(1, 2)
+	table.SetCellMargins(0, 108, 0, 108)
+	header := table.Row(0).Cell(0)
+	header.SetMargins(120, 240, 120, 240)
+	header.SetVerticalAlignment(WDVerticalAlignmentCenter)
+	header.SetShading("clear", "D9D9D9", "auto")
+
+	outputPath := filepath.Join(t.TempDir(), "cell-margins.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	parsed := reopened.Tables()[0]
+	margins, ok := parsed.Row(0).Cell(0).Margins()
+	if !ok {
+		t.Fatal("expected cell margins to round-trip")
+	}
+	if *margins.Top != 120 || *margins.Left != 240 || *margins.Bottom != 120 || *margins.Right != 240 {
+		t.Fatalf("unexpected cell margins: top=%d left=%d bottom=%d right=%d", *margins.Top, *margins.Left, *margins.Bottom, *margins.Right)
+	}
+	if _, ok := parsed.Row(0).Cell(1).Margins(); ok {
+		t.Fatal("expected second cell to use table margins")
+	}
+	if parsed.Row(0).Cell(0).VerticalAlignment() != WDVerticalAlignmentCenter {
+		t.Fatal("expected vertical alignment to survive alongside cell margins")
+	}
+	xmlData := string(reopened.docPart.Data)
+	if !strings.Contains(xmlData, `<w:shd w:val="clear" w:color="auto" w:fill="D9D9D9"/><w:tcMar>`) || !strings.Contains(xmlData, `</w:tcMar><w:vAlign w:val="center"/>`) {
+		t.Fatalf("expected tcMar between shd and vAlign:\n%s", xmlData)
+	}
+}
+
+func TestDocGridAndSnapToGridRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.Sections()[0].SetDocGrid("linesAndChars", 360, -1541)
+	free := doc.AddParagraph("不对齐网格")
+	free.SetSnapToGrid(false)
+	doc.AddParagraph("对齐网格")
+
+	outputPath := filepath.Join(t.TempDir(), "docgrid.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	gridType, linePitch, charSpace, ok := reopened.Sections()[0].DocGrid()
+	if !ok || gridType != "linesAndChars" || linePitch != 360 || charSpace != -1541 {
+		t.Fatalf("unexpected doc grid: ok=%v type=%q linePitch=%d charSpace=%d", ok, gridType, linePitch, charSpace)
+	}
+	paragraphs := reopened.Paragraphs()
+	if paragraphs[0].SnapToGrid() || paragraphs[0].snapToGrid == nil {
+		t.Fatal("expected snap-to-grid to be disabled on first paragraph")
+	}
+	if !paragraphs[1].SnapToGrid() || paragraphs[1].snapToGrid != nil {
+		t.Fatal("expected second paragraph to use the default snap-to-grid behavior")
+	}
+}
+
+func TestDocumentStyleDiscovery(t *testing.T) {
+	doc := NewDocument()
+	if names, err := doc.StyleNames(); err != nil || len(names) != 0 {
+		t.Fatalf("expected no paragraph styles in a blank document, got %v", names)
+	}
+
+	stylesPart := doc.pkg.parts["word/styles.xml"]
+	stylesPart.Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/></w:style>
+  <w:style w:type="character" w:styleId="Strong"><w:name w:val="Strong"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Quote"/>
+</w:styles>`)
+
+	outputPath := filepath.Join(t.TempDir(), "styles.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	names, err := reopened.StyleNames()
+	if err != nil {
+		t.Fatalf("StyleNames failed: %v", err)
+	}
+	want := []string{"Normal", "Heading1", "Quote"}
+	if len(names) != len(want) {
+		t.Fatalf("expected paragraph styles %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected paragraph styles %v, got %v", want, names)
+		}
+	}
+	if !reopened.HasStyle("Heading1") || !reopened.HasStyle("Strong") {
+		t.Fatal("expected HasStyle to find defined paragraph and character styles")
+	}
+	if reopened.HasStyle("Heading 1") || reopened.HasStyle("Title") {
+		t.Fatal("expected HasStyle to match style IDs only")
+	}
+
+	reopened.pkg.parts["word/styles.xml"].Data = []byte(`<w:styles><w:style>`)
+	if _, err := reopened.StyleNames(); err == nil {
+		t.Fatal("expected StyleNames to report a malformed styles part")
+	}
+}
+
+func TestCenteredPictures(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "logo.png")
+	createTestImage(t, imgPath, 8, 4)
+
+	doc := NewDocument()
+	if _, _, err := doc.AddCenteredPicture(imgPath, 0, 0); err != nil {
+		t.Fatalf("Document.AddCenteredPicture failed: %v", err)
+	}
+	paragraph := doc.AddParagraph()
+	if _, _, err := paragraph.AddCenteredPicture(imgPath, 914400, 0); err != nil {
+		t.Fatalf("Paragraph.AddCenteredPicture failed: %v", err)
+	}
+	failed := doc.AddParagraph()
+	if _, _, err := failed.AddCenteredPicture(filepath.Join(t.TempDir(), "missing.png"), 0, 0); err == nil {
+		t.Fatal("expected error for missing image")
+	}
+	if failed.Alignment() != WDAlignParagraphLeft {
+		t.Fatal("expected alignment to stay unchanged when the picture cannot be added")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "centered.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	for i, parsed := range reopened.Paragraphs()[:2] {
+		if parsed.Alignment() != WDAlignParagraphCenter {
+			t.Fatalf("paragraph %d: expected center alignment, got %q", i, parsed.Alignment())
+		}
+		if runs := parsed.Runs(); len(runs) != 1 || runs[0].Picture() == nil {
+			t.Fatalf("paragraph %d: expected a single picture run", i)
+		}
+	}
+}
+
+func TestHeadingsKeepWithNext(t *testing.T) {
+	doc := NewDocument()
+	if _, err := doc.AddHeading("Kept", 1); err != nil {
+		t.Fatalf("AddHeading failed: %v", err)
+	}
+	if _, err := doc.AddHeadingWithKeep("Loose", 2, false); err != nil {
+		t.Fatalf("AddHeadingWithKeep failed: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "headings.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if !paragraphs[0].KeepWithNext() || !paragraphs[0].KeepLines() {
+		t.Fatal("expected AddHeading to keep with next and keep lines together")
+	}
+	if paragraphs[1].keepWithNext != nil || paragraphs[1].keepLines != nil {
+		t.Fatal("expected opted-out heading to leave keep settings unset")
+	}
+}
+
+func TestCodeBlockRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.AddCodeBlock("func main() {\n\tfmt.Println(\"hi\")\n}")
+
+	outputPath := filepath.Join(t.TempDir(), "code.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 1 {
+		t.Fatalf("expected a single code paragraph, got %d", len(paragraphs))
+	}
+	if shading, ok := paragraphs[0].Shading(); !ok || shading.Fill != codeBlockShading {
+		t.Fatalf("expected code block shading, got %+v", shading)
+	}
+	runs := paragraphs[0].Runs()
+	if len(runs) == 0 {
+		t.Fatal("expected code block runs")
+	}
+	for _, run := range runs {
+		if run.Font() != monospaceFont || !run.NoProof() {
+			t.Fatalf("expected monospaced no-proof runs, got font %q noProof %v", run.Font(), run.NoProof())
+		}
+	}
+}
+
+func TestHeaderFooterContentTypeOverrides(t *testing.T) {
+	doc := NewDocument()
+	section := doc.Sections()[0]
+	header, err := section.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	header.AddParagraph("Header text")
+	footer, err := section.Footer()
+	if err != nil {
+		t.Fatalf("Footer failed: %v", err)
+	}
+	footer.AddParagraph("Footer text")
+
+	outputPath := filepath.Join(t.TempDir(), "header-content-types.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	archive, err := zip.OpenReader(outputPath)
+	if err != nil {
+		t.Fatalf("failed to open saved package: %v", err)
+	}
+	defer archive.Close()
+	var contentTypes string
+	for _, file := range archive.File {
+		if file.Name != "[Content_Types].xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open content types: %v", err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read content types: %v", err)
+		}
+		contentTypes = string(data)
+	}
+	for partName, contentType := range map[string]string{
+		"/word/header1.xml": ContentTypeWMLHeader,
+		"/word/footer1.xml": ContentTypeWMLFooter,
+	} {
+		override := `PartName="` + partName + `" ContentType="` + contentType + `"`
+		if !strings.Contains(contentTypes, override) {
+			t.Fatalf("expected override %s in [Content_Types].xml, got %s", override, contentTypes)
+		}
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedHeader, err := reopened.Sections()[0].Header()
+	if err != nil {
+		t.Fatalf("Header after reopen failed: %v", err)
+	}
+	if paragraphs := reopenedHeader.Paragraphs(); len(paragraphs) != 1 || paragraphs[0].Text() != "Header text" {
+		t.Fatal("expected header content to survive reopening")
+	}
+}
+
+func TestPruneUnusedMediaAndRemovePart(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "image.png")
+	createTestImage(t, imagePath, 8, 8)
+
+	doc := NewDocument()
+	kept, _, err := doc.AddPicture(imagePath, 914400, 914400)
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	removed, _, err := doc.AddPicture(imagePath, 914400, 914400)
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	if err := doc.RemoveParagraph(removed); err != nil {
+		t.Fatalf("RemoveParagraph failed: %v", err)
+	}
+
+	count, err := doc.PruneUnusedMedia()
+	if err != nil {
+		t.Fatalf("PruneUnusedMedia failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one unused image to be pruned, got %d", count)
+	}
+	if _, ok := doc.pkg.parts["word/media/image2.png"]; ok {
+		t.Fatal("expected unreferenced image part to be removed")
+	}
+	if _, ok := doc.pkg.contentTypes["/word/media/image2.png"]; ok {
+		t.Fatal("expected content type override of the pruned image to be removed")
+	}
+	if len(kept.Runs()) == 0 || kept.Runs()[0].Picture() == nil {
+		t.Fatal("expected remaining picture to be untouched")
+	}
+
+	outputPath := filepath.Join(dir, "pruned.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if _, ok := reopened.pkg.parts["word/media/image1.png"]; !ok {
+		t.Fatal("expected referenced image to be saved")
+	}
+	if _, ok := reopened.pkg.parts["word/media/image2.png"]; ok {
+		t.Fatal("expected pruned image to be absent from the saved package")
+	}
+
+	if err := reopened.pkg.RemovePart("/word/media/image1.png"); err != nil {
+		t.Fatalf("RemovePart failed: %v", err)
+	}
+	for _, rel := range reopened.pkg.relations[reopened.docPart.URI] {
+		if rel.Type == RelTypeImage {
+			t.Fatalf("expected relationship %s targeting the removed part to be dropped", rel.ID)
+		}
+	}
+	if err := reopened.pkg.RemovePart("word/media/image1.png"); !errors.Is(err, ErrPartNotFound) {
+		t.Fatalf("expected ErrPartNotFound for a missing part, got %v", err)
+	}
+}
+
+func TestPruneUnusedMediaKeepsHeaderImages(t *testing.T) {
+	imagePath := filepath.Join(t.TempDir(), "logo.png")
+	createTestImage(t, imagePath, 8, 8)
+
+	doc := NewDocument()
+	header, err := doc.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	picture, err := header.AddParagraph().AddRun("").AddPicture(imagePath, InchesToEMU(1), InchesToEMU(1))
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	// reference the image from the header part only; the header XML is not regenerated yet
+	picture.relID = doc.pkg.ensureRelationship(header.part.URI, RelTypeImage, picture.target)
+
+	count, err := doc.PruneUnusedMedia()
+	if err != nil {
+		t.Fatalf("PruneUnusedMedia failed: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the header image to be kept, pruned %d", count)
+	}
+	if _, ok := doc.pkg.parts["word/media/image1.png"]; !ok {
+		t.Fatal("expected the header image part to remain")
+	}
+	if !strings.Contains(string(header.part.Data), picture.relID) {
+		t.Fatal("expected the header XML to reference the image")
+	}
+}
+
+func TestEvenPageHeadersRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	section := doc.Sections()[0]
+	section.SetStartType(SectionStartOddPage)
+	defaultHeader, err := section.HeaderOfType(HeaderTypeDefault)
+	if err != nil {
+		t.Fatalf("HeaderOfType(default) failed: %v", err)
+	}
+	defaultHeader.AddParagraph("Odd pages")
+	evenHeader, err := section.HeaderOfType(HeaderTypeEven)
+	if err != nil {
+		t.Fatalf("HeaderOfType(even) failed: %v", err)
+	}
+	evenHeader.AddParagraph("Even pages")
+
+	outputPath := filepath.Join(t.TempDir(), "even-headers.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	xmlContent, err := reopened.GetXML()
+	if err != nil {
+		t.Fatalf("GetXML failed: %v", err)
+	}
+	defaultIndex := strings.Index(xmlContent, `<w:headerReference w:type="default"`)
+	evenIndex := strings.Index(xmlContent, `<w:headerReference w:type="even"`)
+	typeIndex := strings.Index(xmlContent, `<w:type w:val="oddPage"/>`)
+	if defaultIndex < 0 || evenIndex < 0 || typeIndex < 0 {
+		t.Fatalf("expected default and even header references and a section type, got %s", xmlContent)
+	}
+	if !(defaultIndex < evenIndex && evenIndex < typeIndex) {
+		t.Fatal("expected header references in default, even order ahead of the section type")
+	}
+
+	reopenedSection := reopened.Sections()[0]
+	for headerType, want := range map[HeaderType]string{
+		HeaderTypeDefault: "Odd pages",
+		HeaderTypeEven:    "Even pages",
+	} {
+		header, err := reopenedSection.HeaderOfType(headerType)
+		if err != nil {
+			t.Fatalf("HeaderOfType(%s) after reopen failed: %v", headerType, err)
+		}
+		paragraphs := header.Paragraphs()
+		if len(paragraphs) != 1 || paragraphs[0].Text() != want {
+			t.Fatalf("expected %s header text %q after reopen", headerType, want)
+		}
+	}
+}
+
+func TestDocumentMarginsApplyToAllSections(t *testing.T) {
+	doc := NewDocument()
+	first := doc.AddParagraph("First section")
+	doc.AddParagraph("Second section")
+	if _, err := doc.SplitSectionAt(first, SectionStartNewPage); err != nil {
+		t.Fatalf("SplitSectionAt failed: %v", err)
+	}
+	doc.SetMarginsInches(0.5, 0.75, 1, 1.25)
+
+	outputPath := filepath.Join(t.TempDir(), "margins.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	sections := reopened.docPart.allSections()
+	if len(sections) != 2 {
+		t.Fatalf("expected two sections, got %d", len(sections))
+	}
+	for i, section := range sections {
+		if section.marginTop != 720 || section.marginRight != 1080 || section.marginBottom != 1440 || section.marginLeft != 1800 {
+			t.Fatalf("section %d: unexpected margins %d/%d/%d/%d", i, section.marginTop, section.marginRight, section.marginBottom, section.marginLeft)
+		}
+	}
+}
+
+func TestFinalParagraphSectionNotDuplicated(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body><w:p><w:r><w:t>Intro</w:t></w:r></w:p><w:p><w:pPr><w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/><w:pgMar w:top="720" w:right="720" w:bottom="720" w:left="720"/></w:sectPr></w:pPr><w:r><w:t>Last</w:t></w:r></w:p></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "final-section.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	resavedPath := filepath.Join(dir, "final-section-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xmlContent := string(doc.docPart.Part.Data)
+	if count := strings.Count(xmlContent, "<w:sectPr"); count != 1 {
+		t.Fatalf("expected a single sectPr, got %d in %s", count, xmlContent)
+	}
+
+	reopened, err := OpenDocument(resavedPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 2 || paragraphs[1].section == nil {
+		t.Fatal("expected the final paragraph to keep its section break")
+	}
+	if section := paragraphs[1].section; section.pageWidth != 16838 || section.marginTop != 720 {
+		t.Fatalf("expected final section layout to survive, got width %d top margin %d", section.pageWidth, section.marginTop)
+	}
+}
+
+func TestRunFormatRoundTrip(t *testing.T) {
+	underline := WDUnderlineSingle
+	highlight := WDColorIndexYellow
+	color := "1F4E79"
+	font := "Georgia"
+	format := RunFormat{
+		Bold:      boolPtr(true),
+		Italic:    boolPtr(true),
+		Underline: &underline,
+		AllCaps:   boolPtr(true),
+		Size:      intPtr(14),
+		Color:     &color,
+		Font:      &font,
+		Highlight: &highlight,
+	}
+
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	paragraph.AddRunWithFormat("Formatted", format)
+	plain := paragraph.AddRun("Plain")
+	plain.SetBold(true)
+	plain.ApplyFormat(RunFormat{Italic: boolPtr(true)})
+
+	outputPath := filepath.Join(t.TempDir(), "run-format.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	runs := reopened.Paragraphs()[0].Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected two runs, got %d", len(runs))
+	}
+	run := runs[0]
+	if !run.IsBold() || !run.IsItalic() || !run.IsAllCaps() || run.Underline() != WDUnderlineSingle {
+		t.Fatal("expected boolean and underline formatting to survive")
+	}
+	if run.Size() != 14 || run.Color() != color || run.Font() != font || run.Highlight() != WDColorIndexYellow {
+		t.Fatalf("unexpected formatting: size %d color %q font %q highlight %q", run.Size(), run.Color(), run.Font(), run.Highlight())
+	}
+	if !runs[1].IsBold() || !runs[1].IsItalic() {
+		t.Fatal("expected ApplyFormat to leave unset fields unchanged")
+	}
+}
+
+func TestHyperlinkHistoryRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	paragraph.AddHyperlink("Tracked", "https://example.com/tracked").SetHyperlinkHistory(true)
+	paragraph.AddHyperlink("Untracked", "https://example.com/untracked")
+
+	outputPath := filepath.Join(t.TempDir(), "hyperlink-history.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xml := string(doc.docPart.Part.Data); strings.Count(xml, `w:history="1"`) != 1 {
+		t.Fatalf("expected exactly one w:history attribute, got %s", xml)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	runs := reopened.Paragraphs()[0].Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected two hyperlink runs, got %d", len(runs))
+	}
+	if !runs[0].HyperlinkHistory() || runs[1].HyperlinkHistory() {
+		t.Fatalf("expected history on the first hyperlink only, got %v and %v", runs[0].HyperlinkHistory(), runs[1].HyperlinkHistory())
+	}
+}
+
+func TestAddChartRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	data := ChartData{
+		Title:      "Quarterly revenue",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []ChartSeries{
+			{Name: "2023", Values: []float64{10, 12.5, 9}},
+			{Name: "2024", Values: []float64{11, 14, 15.25}},
+		},
+	}
+	column, err := doc.AddChart(ChartTypeColumn, data)
+	if err != nil {
+		t.Fatalf("AddChart(column) failed: %v", err)
+	}
+	column.SetSize(InchesToEMU(6), InchesToEMU(3))
+	if _, err := doc.AddChart(ChartTypePie, ChartData{Categories: []string{"A", "B"}, Series: []ChartSeries{{Name: "Share", Values: []float64{60, 40}}}}); err != nil {
+		t.Fatalf("AddChart(pie) failed: %v", err)
+	}
+	if _, err := doc.AddChart(ChartTypeLine, ChartData{Categories: []string{"A"}, Series: []ChartSeries{{Name: "Bad", Values: []float64{1, 2}}}}); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected mismatched series length to be rejected with ErrInvalidArgument, got %v", err)
+	}
+	if _, err := doc.AddChart(ChartType("scatter"), data); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected unsupported chart type to be rejected with ErrInvalidArgument, got %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "charts.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	archive, err := zip.OpenReader(outputPath)
+	if err != nil {
+		t.Fatalf("failed to open saved package: %v", err)
+	}
+	names := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		names[file.Name] = file
+	}
+	for _, name := range []string{"word/charts/chart1.xml", "word/charts/_rels/chart1.xml.rels", "word/charts/chart2.xml", "word/embeddings/Microsoft_Excel_Worksheet1.xlsx"} {
+		if names[name] == nil {
+			t.Fatalf("expected %s in saved package", name)
+		}
+	}
+	rc, err := names["word/embeddings/Microsoft_Excel_Worksheet1.xlsx"].Open()
+	if err != nil {
+		t.Fatalf("failed to open embedded workbook: %v", err)
+	}
+	workbook, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("failed to read embedded workbook: %v", err)
+	}
+	archive.Close()
+	workbookReader, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatalf("embedded workbook is not a valid package: %v", err)
+	}
+	if len(workbookReader.File) != 5 {
+		t.Fatalf("expected a five-part workbook, got %d parts", len(workbookReader.File))
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	var charts []*Chart
+	for _, paragraph := range reopened.Paragraphs() {
+		for _, run := range paragraph.Runs() {
+			if run.HasChart() {
+				charts = append(charts, run.Chart())
+			}
+		}
+	}
+	if len(charts) != 2 {
+		t.Fatalf("expected two charts after reopening, got %d", len(charts))
+	}
+	first := charts[0]
+	if first.Type() != ChartTypeColumn || first.WidthEMU() != InchesToEMU(6) || first.Target() != "charts/chart1.xml" {
+		t.Fatalf("unexpected first chart: type %q width %d target %q", first.Type(), first.WidthEMU(), first.Target())
+	}
+	got := first.Data()
+	if got.Title != data.Title || strings.Join(got.Categories, ",") != "Q1,Q2,Q3" || len(got.Series) != 2 {
+		t.Fatalf("unexpected chart data: %+v", got)
+	}
+	if got.Series[1].Name != "2024" || got.Series[1].Values[2] != 15.25 {
+		t.Fatalf("unexpected second series: %+v", got.Series[1])
+	}
+	if charts[1].Type() != ChartTypePie {
+		t.Fatalf("expected pie chart, got %q", charts[1].Type())
+	}
+}
+
+func TestEffectiveAlignmentAndSpacing(t *testing.T) {
+	const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:pPrDefault><w:pPr><w:spacing w:after="160" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:pPr><w:spacing w:after="200"/></w:pPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Centered"><w:name w:val="Centered"/><w:basedOn w:val="Normal"/><w:pPr><w:jc w:val="center"/><w:spacing w:before="120"/></w:pPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Centered"/><w:pPr><w:spacing w:line="360" w:lineRule="exact"/></w:pPr></w:style>
+</w:styles>`
+
+	doc := NewDocument()
+	doc.pkg.parts["word/styles.xml"].Data = []byte(stylesXML)
+	doc.AddParagraph("Plain")
+	doc.AddParagraph("Centered").SetStyle("Quote")
+	overridden := doc.AddParagraph("Left")
+	overridden.SetStyle("Centered")
+	overridden.SetAlignment(WDAlignParagraphLeft)
+
+	outputPath := filepath.Join(t.TempDir(), "effective.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if got := paragraphs[0].EffectiveAlignment(); got != WDAlignParagraphLeft {
+		t.Fatalf("expected plain paragraph to be left aligned, got %q", got)
+	}
+	if before, after, line, rule := paragraphs[0].EffectiveSpacing(); before != 0 || after != 200 || line != 259 || rule != "auto" {
+		t.Fatalf("unexpected plain spacing %d/%d/%d/%s", before, after, line, rule)
+	}
+	if got := paragraphs[1].EffectiveAlignment(); got != WDAlignParagraphCenter {
+		t.Fatalf("expected alignment inherited through basedOn, got %q", got)
+	}
+	if before, after, line, rule := paragraphs[1].EffectiveSpacing(); before != 120 || after != 200 || line != 360 || rule != "exact" {
+		t.Fatalf("unexpected inherited spacing %d/%d/%d/%s", before, after, line, rule)
+	}
+	if got := paragraphs[2].EffectiveAlignment(); got != WDAlignParagraphLeft {
+		t.Fatalf("expected direct left alignment to override the style, got %q", got)
+	}
+}
+
+func TestTableSortRows(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(5, 2)
+	values := [][2]string{{"Name", "Score"}, {"Carol", "9"}, {"alice", "n/a"}, {"Bob", "10"}, {"Dave", "9"}}
+	for i, row := range values {
+		table.Row(i).GetCell(0).SetText(row[0])
+		table.Row(i).GetCell(1).SetText(row[1])
+	}
+
+	if err := table.SortRows(1, SortOptions{Numeric: true, Descending: true, SkipHeader: true}); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "sorted.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	sorted := reopened.Tables()[0]
+	var names []string
+	for _, row := range sorted.Rows() {
+		names = append(names, row.GetCell(0).Text())
+	}
+	if got := strings.Join(names, ","); got != "Name,Bob,Carol,Dave,alice" {
+		t.Fatalf("unexpected numeric descending order %s", got)
+	}
+
+	if err := sorted.SortRows(0, SortOptions{SkipHeader: true}); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+	if first := sorted.Row(1).GetCell(0).Text(); first != "Bob" {
+		t.Fatalf("expected string sort to start with Bob, got %s", first)
+	}
+	if err := sorted.SortRows(2, SortOptions{}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange for a missing column, got %v", err)
+	}
+}
+
+func TestTableMergeCellsRectangle(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(4, 4)
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			table.Row(r).GetCell(c).SetText(fmt.Sprintf("%d,%d", r, c))
+		}
+	}
+
+	if err := table.MergeCells(1, 1, 2, 2); err != nil {
+		t.Fatalf("MergeCells failed: %v", err)
+	}
+	if err := table.MergeCells(0, 1, 1, 1); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument for a block that cuts through a merged cell, got %v", err)
+	}
+	if err := table.MergeCells(1, 2, 0, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange for a reversed block, got %v", err)
+	}
+	if err := table.MergeCells(2, 0, 4, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if len(table.Row(0).Cells()) != 4 {
+		t.Fatal("expected a failed merge to leave the table untouched")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "merge-block.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	merged := reopened.Tables()[0]
+	anchor := merged.CellAt(1, 1)
+	if anchor.GridSpan() != 2 || anchor.VerticalMerge() != TableVerticalMergeRestart {
+		t.Fatalf("unexpected anchor span %d merge %q", anchor.GridSpan(), anchor.VerticalMerge())
+	}
+	if text := anchor.Text(); text != "1,1\n1,2\n2,1\n2,2" {
+		t.Fatalf("unexpected merged text %q", text)
+	}
+	for _, position := range [][2]int{{1, 2}, {2, 1}, {2, 2}} {
+		if merged.CellAt(position[0], position[1]) != anchor {
+			t.Fatalf("expected %v to resolve to the top-left cell", position)
+		}
+	}
+	if cells := merged.Row(2).Cells(); len(cells) != 3 || cells[1].VerticalMerge() != TableVerticalMergeContinue {
+		t.Fatal("expected the second row of the block to continue the merge")
+	}
+	if text := merged.CellAt(2, 3).Text(); text != "2,3" {
+		t.Fatalf("expected cells outside the block to keep their text, got %q", text)
+	}
+}
+
+func TestParagraphInsertRun(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	paragraph.AddRun("Hello ")
+	paragraph.AddRun("world")
+	token := paragraph.InsertRun(1, "big ")
+	token.SetHighlight(WDColorIndexYellow)
+	paragraph.InsertRun(-5, ">> ")
+	paragraph.InsertRun(99, "!")
+
+	outputPath := filepath.Join(t.TempDir(), "insert-run.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	reopenedParagraph := reopened.Paragraphs()[0]
+	if text := reopenedParagraph.Text(); text != ">> Hello big world!" {
+		t.Fatalf("unexpected paragraph text %q", text)
+	}
+	if runs := reopenedParagraph.Runs(); len(runs) != 5 || runs[2].Highlight() != WDColorIndexYellow {
+		t.Fatal("expected the inserted run to keep its position and formatting")
+	}
+}
+
+func TestCustomPropertiesRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	props := doc.CustomProperties()
+	props.SetString("Department", "Finance & Ops")
+	props.SetInt("Retention", 7)
+	props.SetBool("Approved", true)
+	reviewed := time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)
+	props.SetDate("Reviewed", reviewed)
+	props.SetString("Obsolete", "x")
+	props.Remove("Obsolete")
+
+	outputPath := filepath.Join(t.TempDir(), "custom.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	got := reopened.CustomProperties()
+	if names := strings.Join(got.Names(), ","); names != "Department,Retention,Approved,Reviewed" {
+		t.Fatalf("unexpected property names %s", names)
+	}
+	if value, ok := got.String("Department"); !ok || value != "Finance & Ops" {
+		t.Fatalf("unexpected Department %q", value)
+	}
+	if value, ok := got.Int("Retention"); !ok || value != 7 {
+		t.Fatalf("unexpected Retention %d", value)
+	}
+	if value, ok := got.Bool("Approved"); !ok || !value {
+		t.Fatal("expected Approved to be true")
+	}
+	if value, ok := got.Date("Reviewed"); !ok || !value.Equal(reviewed) {
+		t.Fatalf("unexpected Reviewed %v", value)
+	}
+	if _, ok := got.Int("Department"); ok {
+		t.Fatal("expected a type mismatch to report ok=false")
+	}
+	if ct := reopened.pkg.lookupContentType("docProps/custom.xml"); ct != ContentTypeCustomProps {
+		t.Fatalf("expected custom properties content type, got %q", ct)
+	}
+
+	plainPath := filepath.Join(t.TempDir(), "plain.docx")
+	if err := NewDocument().SaveAs(plainPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	plain, err := OpenDocument(plainPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer plain.Close()
+	if _, ok := plain.pkg.parts["docProps/custom.xml"]; ok {
+		t.Fatal("expected no custom properties part without properties")
+	}
+}
+
+func TestCustomPropertiesPreserveStructuredValues(t *testing.T) {
+	vector := `<vt:vector size="2" baseType="lpwstr"><vt:lpwstr>a &amp; b</vt:lpwstr><vt:lpwstr>c</vt:lpwstr></vt:vector>`
+	props, err := parseCustomProperties([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">
+<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="Tags"><vt:variant>` + vector + `</vt:variant></property>
+<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="3" name="Ratio"><vt:r8>0.5</vt:r8></property>
+<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="4" name="Owner"><vt:lpwstr>R &amp; D</vt:lpwstr></property>
+</Properties>`))
+	if err != nil {
+		t.Fatalf("parseCustomProperties failed: %v", err)
+	}
+	xml := string(props.ToXML())
+	for _, want := range []string{
+		`name="Tags"><vt:variant>` + vector + `</vt:variant>`,
+		`name="Ratio"><vt:r8>0.5</vt:r8>`,
+		`name="Owner"><vt:lpwstr>R &amp; D</vt:lpwstr>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("expected %s in %s", want, xml)
+		}
+	}
+
+	props.SetString("Tags", "plain")
+	if xml := string(props.ToXML()); !strings.Contains(xml, `name="Tags"><vt:lpwstr>plain</vt:lpwstr>`) {
+		t.Errorf("expected a replaced value to drop the preserved XML, got %s", xml)
+	}
+}
+
+func readZipEntry(t *testing.T, archivePath, name string) string {
+	t.Helper()
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatalf("failed to open saved package: %v", err)
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	t.Fatalf("entry %s not found", name)
+	return ""
+}
+
+func TestIndentOutput(t *testing.T) {
+	build := func(indent bool) string {
+		doc := NewDocument()
+		doc.SetIndentOutput(indent)
+		paragraph := doc.AddParagraph()
+		paragraph.AddRun("Hello")
+		paragraph.AddRun(" ")
+		paragraph.AddRun("world")
+		doc.AddTable(1, 1).Row(0).GetCell(0).SetText("cell")
+		header, err := doc.Header()
+		if err != nil {
+			t.Fatalf("Header failed: %v", err)
+		}
+		header.AddParagraph("header")
+		footer, err := doc.Footer()
+		if err != nil {
+			t.Fatalf("Footer failed: %v", err)
+		}
+		footer.AddParagraph("footer")
+		outputPath := filepath.Join(t.TempDir(), "indent.docx")
+		if err := doc.SaveAs(outputPath); err != nil {
+			t.Fatalf("SaveAs failed: %v", err)
+		}
+		return outputPath
+	}
+
+	compactPath := build(false)
+	if body := readZipEntry(t, compactPath, "word/document.xml"); strings.Count(body, "\n") != 1 {
+		t.Fatalf("expected compact body markup, got %s", body)
+	}
+	for _, name := range []string{"word/header1.xml", "word/footer1.xml"} {
+		if part := readZipEntry(t, compactPath, name); strings.Count(part, "\n") != 1 {
+			t.Fatalf("expected compact %s markup, got %s", name, part)
+		}
+	}
+
+	indentedPath := build(true)
+	body := readZipEntry(t, indentedPath, "word/document.xml")
+	if !strings.Contains(body, "\n  <w:body>\n    <w:") || !strings.Contains(body, "\n    <w:p>\n      <w:r>\n        <w:t>Hello</w:t>") {
+		t.Fatalf("expected indented body markup, got %s", body)
+	}
+	if !strings.Contains(readZipEntry(t, indentedPath, "_rels/.rels"), "\n  <Relationship") {
+		t.Fatal("expected indented relationships")
+	}
+
+	reopened, err := OpenDocument(indentedPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if text := reopened.Paragraphs()[0].Text(); text != "Hello world" {
+		t.Fatalf("expected whitespace-only runs to survive indentation, got %q", text)
+	}
+	if text := reopened.Tables()[0].Row(0).GetCell(0).Text(); text != "cell" {
+		t.Fatalf("unexpected cell text %q", text)
+	}
+}
+
+func TestSettingsTypographyRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.Settings().SetAutoHyphenation(true)
+	doc.Settings().SetHyphenationZone(357)
+	doc.Settings().SetZoom(120)
+	doc.Settings().SetNoPunctuationKerning(true)
+	doc.Settings().SetKinsoku(true)
+	doc.AddParagraph("Justified text").SetAlignment(WDAlignParagraphJustify)
+
+	outputPath := filepath.Join(t.TempDir(), "hyphenation.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	settingsXML := readZipEntry(t, outputPath, "word/settings.xml")
+	expected := []string{`<w:zoom w:percent="120"/>`, `<w:defaultTabStop w:val="708"/>`, `<w:autoHyphenation/>`, `<w:hyphenationZone w:val="357"/>`, `<w:noPunctuationKerning/>`, `<w:characterSpacingControl`, `<w:strictFirstAndLastChars/>`, `<w:compat>`}
+	last := -1
+	for _, fragment := range expected {
+		index := strings.Index(settingsXML, fragment)
+		if index <= last {
+			t.Fatalf("expected %s after the previous settings element, got %s", fragment, settingsXML)
+		}
+		last = index
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	settings := reopened.Settings()
+	if !settings.AutoHyphenation() || settings.HyphenationZone() != 357 {
+		t.Fatalf("unexpected hyphenation settings %v %d", settings.AutoHyphenation(), settings.HyphenationZone())
+	}
+	if !settings.NoPunctuationKerning() || !settings.Kinsoku() {
+		t.Fatal("expected the East Asian line-break settings to round trip")
+	}
+
+	settings.SetAutoHyphenation(false)
+	resavedPath := filepath.Join(t.TempDir(), "hyphenation-off.docx")
+	if err := reopened.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	settingsXML = readZipEntry(t, resavedPath, "word/settings.xml")
+	if strings.Contains(settingsXML, "autoHyphenation") || !strings.Contains(settingsXML, `<w:zoom w:percent="120"/>`) {
+		t.Fatalf("expected hyphenation removed and zoom kept, got %s", settingsXML)
+	}
+}
+
+func TestSettingsKeepUnsetZoomAndTabStop(t *testing.T) {
+	doc := NewDocument()
+	doc.pkg.parts["word/settings.xml"].Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:zoom w:val="bestFit" w:percent="90"/><w:compat/></w:settings>`)
+	sourcePath := filepath.Join(t.TempDir(), "best-fit.docx")
+	if err := doc.SaveAs(sourcePath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopened.Settings().SetAutoHyphenation(true)
+
+	outputPath := filepath.Join(t.TempDir(), "best-fit-resaved.docx")
+	if err := reopened.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	settingsXML := readZipEntry(t, outputPath, "word/settings.xml")
+	if !strings.Contains(settingsXML, `<w:zoom w:val="bestFit" w:percent="90"/><w:autoHyphenation/>`) {
+		t.Fatalf("expected the opened zoom element to be kept, got %s", settingsXML)
+	}
+	if strings.Contains(settingsXML, "defaultTabStop") {
+		t.Fatalf("expected no default tab stop to be injected, got %s", settingsXML)
+	}
+
+	reopened.Settings().SetZoom(150)
+	reopened.Settings().SetDefaultTabStop(720)
+	if err := reopened.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	settingsXML = readZipEntry(t, outputPath, "word/settings.xml")
+	if !strings.Contains(settingsXML, `<w:zoom w:percent="150"/><w:defaultTabStop w:val="720"/><w:autoHyphenation/>`) {
+		t.Fatalf("expected explicit zoom and tab stop, got %s", settingsXML)
+	}
+}
+
+func TestMergeParagraphs(t *testing.T) {
+	doc := NewDocument()
+	first := doc.AddParagraph("Wrapped line one")
+	second := doc.AddParagraph()
+	second.AddRun(" and two").SetItalic(true)
+	doc.AddParagraph("Next paragraph")
+
+	if err := doc.MergeParagraphs(first, second); err != nil {
+		t.Fatalf("MergeParagraphs failed: %v", err)
+	}
+	if err := doc.MergeParagraphs(first, second); err == nil {
+		t.Fatal("expected an error when merging a paragraph that was already removed")
+	}
+	if err := doc.MergeParagraphs(first, first); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("expected ErrInvalidArgument when merging a paragraph into itself, got %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "merge-paragraphs.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 2 {
+		t.Fatalf("expected 2 paragraphs after merging, got %d", len(paragraphs))
+	}
+	if text := paragraphs[0].Text(); text != "Wrapped line one and two" {
+		t.Fatalf("unexpected merged text %q", text)
+	}
+	if runs := paragraphs[0].Runs(); len(runs) != 2 || !runs[1].IsItalic() {
+		t.Fatal("expected the moved run to keep its formatting")
+	}
+
+	target := reopened.Paragraphs()[1]
+	target.AppendRuns(NewRun("!"))
+	if text := target.Text(); text != "Next paragraph!" {
+		t.Fatalf("unexpected text after AppendRuns %q", text)
+	}
+}
+
+func TestAutoLineSpacingWithoutLineValue(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:pPr><w:spacing w:after="120" w:lineRule="auto"/></w:pPr><w:r><w:t>Minimal</w:t></w:r></w:p><w:p><w:pPr><w:spacing w:line="0" w:lineRule="bogus"/></w:pPr><w:r><w:t>Invalid rule</w:t></w:r></w:p></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "auto-spacing.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	paragraphs := doc.Paragraphs()
+	if _, after, line, rule := paragraphs[0].Spacing(); after != 120 || line != 240 || rule != "auto" {
+		t.Fatalf("expected single auto spacing, got after %d line %d rule %q", after, line, rule)
+	}
+	if _, _, _, rule := paragraphs[1].Spacing(); rule != "" {
+		t.Fatalf("expected an invalid line rule to be dropped, got %q", rule)
+	}
+
+	resavedPath := filepath.Join(dir, "auto-spacing-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xmlContent := string(doc.docPart.Part.Data); !strings.Contains(xmlContent, `<w:spacing w:after="120" w:line="240" w:lineRule="auto"/>`) {
+		t.Fatalf("expected the resaved spacing to keep single lines, got %s", xmlContent)
+	}
+
+	paragraphs[1].SetSpacing(0, 0, 0, "auto")
+	if _, _, line, _ := paragraphs[1].Spacing(); line != 240 {
+		t.Fatalf("expected SetSpacing to treat a zero auto line as single spacing, got %d", line)
+	}
+}
+
+func TestAddTextWithAutoLinks(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	runs := paragraph.AddTextWithAutoLinks("See https://example.com/docs (or https://en.wikipedia.org/wiki/Go_(language)), mail help@example.org.")
+
+	var links []string
+	for _, run := range runs {
+		if run.HasHyperlink() {
+			links = append(links, run.Text()+"|"+run.HyperlinkURL())
+		}
+	}
+	expected := "https://example.com/docs|https://example.com/docs,https://en.wikipedia.org/wiki/Go_(language)|https://en.wikipedia.org/wiki/Go_(language),help@example.org|mailto:help@example.org"
+	if got := strings.Join(links, ","); got != expected {
+		t.Fatalf("unexpected links %s", got)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "autolinks.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	reopenedParagraph := reopened.Paragraphs()[0]
+	if text := reopenedParagraph.Text(); text != "See https://example.com/docs (or https://en.wikipedia.org/wiki/Go_(language)), mail help@example.org." {
+		t.Fatalf("unexpected paragraph text %q", text)
+	}
+	var reopenedLinks int
+	for _, run := range reopenedParagraph.Runs() {
+		if run.HasHyperlink() {
+			reopenedLinks++
+		}
+	}
+	if reopenedLinks != 3 {
+		t.Fatalf("expected 3 hyperlinks after reopen, got %d", reopenedLinks)
+	}
+}
+
+func TestRemoveProofingMarks(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:proofErr w:type="spellStart"/><w:r><w:t>Teh</w:t></w:r><w:proofErr w:type="spellEnd"/><w:r><w:t xml:space="preserve"> draft</w:t></w:r></w:p></w:body></w:document>`
+	const commentsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:comment w:id="0"><w:p><w:proofErr w:type="gramStart"/><w:r><w:t>Note</w:t></w:r><w:proofErr w:type="gramEnd"></w:proofErr></w:p></w:comment></w:comments>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	pkg.parts["word/comments.xml"] = &Part{URI: "word/comments.xml", ContentType: ContentTypeWMLComments, Data: []byte(commentsXML)}
+	pkg.contentTypes["/word/comments.xml"] = ContentTypeWMLComments
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "proofing.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	removed, err := doc.RemoveProofingMarks()
+	if err != nil {
+		t.Fatalf("RemoveProofingMarks failed: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 markers removed from the comments part, got %d", removed)
+	}
+
+	resavedPath := filepath.Join(dir, "proofing-clean.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	for _, name := range []string{"word/document.xml", "word/comments.xml"} {
+		if content := readZipEntry(t, resavedPath, name); strings.Contains(content, "proofErr") {
+			t.Fatalf("expected no proofing marks in %s, got %s", name, content)
+		}
+	}
+	if text := doc.Paragraphs()[0].Text(); text != "Teh draft" {
+		t.Fatalf("unexpected paragraph text %q", text)
+	}
+}
+
+func TestTableVerticalAlignmentCascades(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.SetVerticalAlignment(WDVerticalAlignmentCenter)
+	table.AddRow()
+	table.InsertRowAt(0)
+	table.Row(1).GetCell(1).SetVerticalAlignment(WDVerticalAlignmentBottom)
+
+	outputPath := filepath.Join(t.TempDir(), "table-valign.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	for rowIndex, row := range reopened.Tables()[0].Rows() {
+		for cellIndex, cell := range row.Cells() {
+			expected := WDVerticalAlignmentCenter
+			if rowIndex == 1 && cellIndex == 1 {
+				expected = WDVerticalAlignmentBottom
+			}
+			if got := cell.VerticalAlignment(); got != expected {
+				t.Fatalf("cell (%d,%d): expected %q, got %q", rowIndex, cellIndex, expected, got)
+			}
+		}
+	}
+}
+
+func TestRunBorderRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph("Press ")
+	key := paragraph.AddRun("Enter")
+	key.SetBorder(RunBorder{Style: "single", Color: "7F7F7F", Size: 4, Space: 1})
+	paragraph.AddRun(" to continue")
+
+	outputPath := filepath.Join(t.TempDir(), "run-border.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	runs := reopened.Paragraphs()[0].Runs()
+	border, ok := runs[1].Border()
+	if !ok || *border != (RunBorder{Style: "single", Color: "7F7F7F", Size: 4, Space: 1}) {
+		t.Fatalf("unexpected run border %+v", border)
+	}
+	if _, ok := runs[0].Border(); ok {
+		t.Fatal("expected neighbouring runs to have no border")
+	}
+	runs[1].ClearBorder()
+	if strings.Contains(runs[1].ToXML(), "w:bdr") {
+		t.Fatal("expected ClearBorder to remove the border")
+	}
+}
+
+func TestParagraphThemeShadingRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	callout := doc.AddParagraph("Callout")
+	callout.SetShadingTheme("clear", "accent1", "")
+	doc.AddParagraph("Plain").SetShading("clear", "FFFF00", "auto")
+
+	outputPath := filepath.Join(t.TempDir(), "theme-shading.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	shading, ok := paragraphs[0].Shading()
+	if !ok || shading.ThemeFill != "accent1" || shading.ThemeColor != "" || shading.Pattern != "clear" {
+		t.Fatalf("unexpected theme shading %+v", shading)
+	}
+	if plain, ok := paragraphs[1].Shading(); !ok || plain.Fill != "FFFF00" || plain.ThemeFill != "" {
+		t.Fatalf("unexpected explicit shading %+v", plain)
+	}
+}
+
+func TestParagraphIDsRoundTrip(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="w14"><w:body><w:p w14:paraId="1A2B3C4D" w14:textId="77777777"><w:r><w:t>Commented</w:t></w:r></w:p><w:tbl><w:tr><w:tc><w:p w14:paraId="0F0F0F0F" w14:textId="12345678"><w:r><w:t>Cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "para-ids.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	doc.AddParagraph("New paragraph")
+
+	resavedPath := filepath.Join(dir, "para-ids-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(resavedPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if paragraphs[0].ParagraphID() != "1A2B3C4D" || paragraphs[0].TextID() != "77777777" {
+		t.Fatalf("unexpected paragraph IDs %q %q", paragraphs[0].ParagraphID(), paragraphs[0].TextID())
+	}
+	if paragraphs[1].ParagraphID() != "" {
+		t.Fatal("expected new paragraphs to carry no paragraph ID")
+	}
+	if cell := reopened.Tables()[0].Row(0).GetCell(0).Paragraphs()[0]; cell.ParagraphID() != "0F0F0F0F" {
+		t.Fatalf("expected the cell paragraph ID to survive, got %q", cell.ParagraphID())
+	}
+	if body := readZipEntry(t, resavedPath, "word/document.xml"); !strings.Contains(body, `xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"`) {
+		t.Fatal("expected the w14 namespace to be declared")
+	}
+}
+
+func TestApplyToAllRuns(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("body")
+	doc.AddTable(1, 1).Row(0).Cell(0).SetText("cell")
+	header, err := doc.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	header.AddParagraph("header")
+	footer, err := doc.Footer()
+	if err != nil {
+		t.Fatalf("Footer failed: %v", err)
+	}
+	footer.AddParagraph("footer")
+
+	output := filepath.Join(t.TempDir(), "apply-runs.docx")
+	if err := doc.SaveAs(output); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(output)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	visited := 0
+	reopened.ApplyToAllRuns(func(run *Run) {
+		visited++
+		run.SetFont("Georgia")
+		run.SetSize(run.Size() + 4)
+	})
+	if visited != 4 {
+		t.Fatalf("expected 4 runs across body, table, header and footer, got %d", visited)
+	}
+
+	restyled := filepath.Join(t.TempDir(), "apply-runs-restyled.docx")
+	if err := reopened.SaveAs(restyled); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	for _, name := range []string{"word/document.xml", "word/header1.xml", "word/footer1.xml"} {
+		if content := readZipEntry(t, restyled, name); !strings.Contains(content, `w:ascii="Georgia"`) || !strings.Contains(content, `<w:sz w:val="30"/>`) {
+			t.Fatalf("expected restyled runs in %s, got %s", name, content)
+		}
+	}
+}
+
+func TestParagraphIndentationCharsRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("首行缩进").SetIndentationChars(0, 200, 0)
+	doc.AddParagraph("悬挂缩进").SetIndentationChars(100, 200, 150)
+	doc.AddParagraph("Twips only").SetIndentation(720, 0, 0, 0)
+
+	outputPath := filepath.Join(t.TempDir(), "indent-chars.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if left, firstLine, hanging, ok := paragraphs[0].IndentationChars(); !ok || left != 0 || firstLine != 200 || hanging != 0 {
+		t.Fatalf("unexpected first-line chars %d %d %d %v", left, firstLine, hanging, ok)
+	}
+	if left, firstLine, hanging, _ := paragraphs[1].IndentationChars(); left != 100 || firstLine != 0 || hanging != 150 {
+		t.Fatalf("expected the hanging indent to replace the first-line indent, got %d %d %d", left, firstLine, hanging)
+	}
+	if _, _, _, ok := paragraphs[2].IndentationChars(); ok {
+		t.Fatal("expected no character indentation on a twip-indented paragraph")
+	}
+}
+
+func TestDocumentBytesAndEstimatedSize(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("In-memory document")
+	revision := doc.CoreProperties().Revision
+	modified := doc.CoreProperties().Modified
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	size, err := doc.EstimatedSize()
+	if err != nil {
+		t.Fatalf("EstimatedSize failed: %v", err)
+	}
+	if size != int64(len(data)) {
+		t.Fatalf("expected EstimatedSize %d to match Bytes length %d", size, len(data))
+	}
+	if props := doc.CoreProperties(); props.Revision != revision || !props.Modified.Equal(modified) {
+		t.Fatalf("expected in-memory serialization to leave core properties alone, got revision %q modified %v", props.Revision, props.Modified)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "bytes.docx")
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed on in-memory output: %v", err)
+	}
+	defer reopened.Close()
+	if text := reopened.Paragraphs()[0].Text(); text != "In-memory document" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
+
+func TestTableRowGridBeforeAfterRoundTrip(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:tbl><w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="2000"/><w:gridCol w:w="3000"/></w:tblGrid><w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>b</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>c</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:trPr><w:gridBefore w:val="1"/><w:wBefore w:w="1000" w:type="dxa"/><w:tblHeader/></w:trPr><w:tc><w:p><w:r><w:t>indented</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr></w:tbl></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "grid-before.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+
+	table := doc.Tables()[0]
+	if count, width := table.Row(1).GridBefore(); count != 1 || width != 1000 {
+		t.Fatalf("unexpected gridBefore %d width %d", count, width)
+	}
+	if cell := table.CellAt(1, 1); cell == nil || cell.Text() != "indented" {
+		t.Fatal("expected grid column 1 of the indented row to hold its first cell")
+	}
+	if table.CellAt(1, 0) != nil {
+		t.Fatal("expected no cell in the skipped grid column")
+	}
+	table.Row(0).SetGridAfter(1, 3000)
+
+	resavedPath := filepath.Join(dir, "grid-before-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, resavedPath, "word/document.xml")
+	if !strings.Contains(body, `<w:trPr><w:gridBefore w:val="1"/><w:wBefore w:w="1000" w:type="dxa"/><w:tblHeader/></w:trPr>`) {
+		t.Fatalf("expected row properties to keep grid and header settings, got %s", body)
+	}
+	reopened, err := OpenDocument(resavedPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if count, width := reopened.Tables()[0].Row(0).GridAfter(); count != 1 || width != 3000 {
+		t.Fatalf("unexpected gridAfter %d width %d", count, width)
+	}
+}
+
+func TestNumberingRestartAfterSection(t *testing.T) {
+	doc := NewDocument()
+	doc.AddNumberedParagraph("Chapter one, item one", 0)
+	last := doc.AddNumberedParagraph("Chapter one, item two", 0)
+	if _, err := doc.SplitSectionAt(last, SectionStartNewPage); err != nil {
+		t.Fatalf("SplitSectionAt failed: %v", err)
+	}
+	doc.AddNumberedParagraph("Chapter two, item one", 0)
+	doc.AddNumberedParagraph("Chapter two, item two", 1)
+
+	numID := doc.Numbering().DecimalListID()
+	if err := doc.Numbering().SetRestartAfterSection(numID, true); err != nil {
+		t.Fatalf("SetRestartAfterSection failed: %v", err)
+	}
+	if err := doc.Numbering().SetRestartAfterSection(99, true); !errors.Is(err, ErrNumberingNotFound) {
+		t.Fatalf("expected ErrNumberingNotFound for an unknown list, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "restart.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	numbering := readZipEntry(t, path, "word/numbering.xml")
+	if !strings.Contains(numbering, `<w:num w:numId="3"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/></w:lvlOverride>`) {
+		t.Fatalf("expected a restarting num instance, got %s", numbering)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	want := []int{1, 1, 3, 3}
+	for i, paragraph := range reopened.Paragraphs() {
+		id, _, ok := paragraph.Numbering()
+		if !ok || id != want[i] {
+			t.Fatalf("paragraph %d: expected numId %d, got %d (ok=%v)", i, want[i], id, ok)
+		}
+	}
+
+	// the restart is resolved on output only, so the model keeps its IDs and saving again
+	// does not add further instances
+	for i, paragraph := range doc.Paragraphs() {
+		if id, _, _ := paragraph.Numbering(); id != numID {
+			t.Fatalf("paragraph %d: expected in-memory numId %d, got %d", i, numID, id)
+		}
+	}
+	if strings.Contains(string(doc.Numbering().part.Data), `w:numId="3"`) {
+		t.Fatal("expected the numbering part in memory to stay unchanged")
+	}
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("second SaveAs failed: %v", err)
+	}
+	if resaved := readZipEntry(t, path, "word/numbering.xml"); resaved != numbering {
+		t.Fatalf("expected repeated saves to write the same numbering part, got %s", resaved)
+	}
+
+	if err := doc.Numbering().SetRestartAfterSection(numID, false); err != nil {
+		t.Fatalf("SetRestartAfterSection(false) failed: %v", err)
+	}
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if strings.Contains(readZipEntry(t, path, "word/numbering.xml"), `w:numId="3"`) {
+		t.Fatal("expected turning the restart off to drop the section instance")
+	}
+}
+
+func TestRunFontHintRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	mixed := paragraph.AddRun("“中文” quotes")
+	mixed.SetEastAsiaFont("SimSun")
+	mixed.SetFontHint("eastAsia")
+	latin := paragraph.AddRun(" Latin")
+	latin.SetFont("Arial")
+	latin.SetFontHint("default")
+
+	path := filepath.Join(t.TempDir(), "font-hint.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	if !strings.Contains(body, `<w:rFonts w:eastAsia="SimSun" w:hint="eastAsia"/>`) {
+		t.Fatalf("expected East Asian font and hint without a Latin font, got %s", body)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs := reopened.Paragraphs()[0].Runs()
+	if got := runs[0]; got.FontHint() != "eastAsia" || got.EastAsiaFont() != "SimSun" || got.Font() != "Calibri" {
+		t.Fatalf("unexpected fonts on mixed run: %q %q %q", got.Font(), got.EastAsiaFont(), got.FontHint())
+	}
+	if got := runs[1]; got.FontHint() != "default" || got.Font() != "Arial" {
+		t.Fatalf("unexpected fonts on Latin run: %q %q", got.Font(), got.FontHint())
+	}
+}
+
+func TestTwoColumnSection(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("Masthead")
+	columns, err := doc.AddTwoColumnSection(720)
+	if err != nil {
+		t.Fatalf("AddTwoColumnSection failed: %v", err)
+	}
+	doc.AddParagraph("Left column story")
+	doc.AddParagraph("Right column story")
+	if err := doc.EndColumns(); err != nil {
+		t.Fatalf("EndColumns failed: %v", err)
+	}
+	doc.AddParagraph("Footer note")
+	if err := doc.EndColumns(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected EndColumns to fail with ErrNotFound without an open column section, got %v", err)
+	}
+	if count, space := columns.Columns(); count != 1 || space != 0 {
+		t.Fatalf("expected the final section to be single-column again, got %d/%d", count, space)
+	}
+
+	path := filepath.Join(t.TempDir(), "columns.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	if !strings.HasSuffix(body, `<w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr></w:body></w:document>`) {
+		t.Fatalf("expected the body section properties to close the body, got %s", body)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	sections := reopened.docPart.allSections()
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %d", len(sections))
+	}
+	want := [][2]int{{1, 0}, {2, 720}, {1, 0}}
+	for i, section := range sections {
+		if count, space := section.Columns(); count != want[i][0] || space != want[i][1] {
+			t.Fatalf("section %d: expected %v columns, got %d/%d", i, want[i], count, space)
+		}
+	}
+	if sections[1].startType != SectionStartContinuous || sections[2].startType != SectionStartContinuous {
+		t.Fatal("expected the column sections to start continuously")
+	}
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 4 || paragraphs[0].section == nil || paragraphs[2].section == nil {
+		t.Fatal("expected section breaks after the masthead and the second column story")
+	}
+}
+
+func TestParagraphSetCompact(t *testing.T) {
+	const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:pPr><w:spacing w:after="160" w:line="259" w:lineRule="auto"/></w:pPr></w:style>
+</w:styles>`
+	const noSpacingStyle = `<w:style w:type="paragraph" w:styleId="NoSpacing"><w:name w:val="No Spacing"/><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:style>`
+
+	doc := NewDocument()
+	doc.pkg.parts["word/styles.xml"].Data = []byte(stylesXML)
+	direct := doc.AddParagraph("Direct")
+	direct.SetSpacing(240, 240, 480, "auto")
+	direct.SetCompact()
+	if before, after, line, rule := direct.EffectiveSpacing(); before != 0 || after != 0 || line != 240 || rule != "auto" {
+		t.Fatalf("expected compact direct spacing, got %d %d %d %q", before, after, line, rule)
+	}
+	if direct.Style() != "" {
+		t.Fatalf("expected no style without a No Spacing definition, got %q", direct.Style())
+	}
+
+	doc.pkg.parts["word/styles.xml"].Data = []byte(strings.Replace(stylesXML, "</w:styles>", noSpacingStyle+"</w:styles>", 1))
+	styled := doc.AddParagraph("Styled")
+	styled.SetSpacing(120, 120, 360, "auto")
+	styled.SetCompact()
+	if styled.Style() != "NoSpacing" {
+		t.Fatalf("expected the No Spacing style, got %q", styled.Style())
+	}
+	if before, after, line, rule := styled.EffectiveSpacing(); before != 0 || after != 0 || line != 240 || rule != "auto" {
+		t.Fatalf("expected spacing from the No Spacing style, got %d %d %d %q", before, after, line, rule)
+	}
+
+	path := filepath.Join(t.TempDir(), "compact.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	if !strings.Contains(body, `<w:pStyle w:val="NoSpacing"/></w:pPr>`) {
+		t.Fatalf("expected the styled paragraph to carry only its style, got %s", body)
+	}
+}
+
+func TestTableCellSetTextKeepFormat(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(1, 2)
+	cell := table.Row(0).Cell(0)
+	cell.SetShading("clear", "D9E2F3", "auto")
+	cell.SetText("placeholder")
+	paragraph := cell.Paragraphs()[0]
+	paragraph.SetAlignment(WDAlignParagraphRight)
+	run := paragraph.Runs()[0]
+	run.SetBold(true)
+	run.SetColor("C00000")
+	paragraph.AddRun(" (unit)")
+	cell.AddParagraph("second line")
+
+	cell.SetTextKeepFormat("42")
+	table.Row(0).Cell(1).SetTextKeepFormat("empty before")
+
+	path := filepath.Join(t.TempDir(), "keep-format.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	filled := reopened.Tables()[0].Row(0).Cell(0)
+	if filled.Text() != "42" || len(filled.Paragraphs()) != 1 {
+		t.Fatalf("expected a single paragraph with the new text, got %q in %d paragraphs", filled.Text(), len(filled.Paragraphs()))
+	}
+	if filled.Paragraphs()[0].Alignment() != WDAlignParagraphRight {
+		t.Fatal("expected the paragraph alignment to survive")
+	}
+	runs := filled.Paragraphs()[0].Runs()
+	if len(runs) != 1 || !runs[0].IsBold() || runs[0].Color() != "C00000" {
+		t.Fatal("expected the first run formatting to survive")
+	}
+	if shading, ok := filled.Shading(); !ok || shading.Fill != "D9E2F3" {
+		t.Fatalf("expected the cell shading to survive, got %+v", shading)
+	}
+	if got := reopened.Tables()[0].Row(0).Cell(1).Text(); got != "empty before" {
+		t.Fatalf("expected text in the previously empty cell, got %q", got)
+	}
+}
+
+func TestSectionPageNumberFormatRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	preface := doc.AddParagraph("Preface")
+	front, err := doc.SplitSectionAt(preface, SectionStartNewPage)
+	if err != nil {
+		t.Fatalf("SplitSectionAt failed: %v", err)
+	}
+	front.SetPageNumberFormat("lowerRoman", 1)
+	doc.AddParagraph("Chapter 1")
+	body := doc.docPart.finalSection()
+	body.SetPageNumberFormat("decimal", 1)
+	if format, start, ok := body.PageNumberFormat(); !ok || format != "decimal" || start != 1 {
+		t.Fatalf("unexpected page numbering %q %d %v", format, start, ok)
+	}
+
+	path := filepath.Join(t.TempDir(), "page-numbers.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xml := readZipEntry(t, path, "word/document.xml")
+	if !strings.Contains(xml, `<w:pgNumType w:fmt="lowerRoman" w:start="1"/>`) {
+		t.Fatalf("expected roman page numbering in the first section, got %s", xml)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	sections := reopened.docPart.allSections()
+	if format, start, ok := sections[0].PageNumberFormat(); !ok || format != "lowerRoman" || start != 1 {
+		t.Fatalf("unexpected front matter numbering %q %d %v", format, start, ok)
+	}
+	last := sections[len(sections)-1]
+	if format, start, ok := last.PageNumberFormat(); !ok || format != "decimal" || start != 1 {
+		t.Fatalf("unexpected body numbering %q %d %v", format, start, ok)
+	}
+	last.SetPageNumberFormat("", -1)
+	if _, start, ok := last.PageNumberFormat(); ok || start != -1 {
+		t.Fatal("expected continued numbering without a format to report no settings")
+	}
+}
+
+func TestSurgicalEditKeepsUntouchedParagraphs(t *testing.T) {
+	const first = `<w:p w:rsidR="00A1"><w:r><w:t>Untouched</w:t></w:r></w:p>`
+	const third = `<w:p w:rsidR="00A3"><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>Also untouched</w:t></w:r></w:p>`
+	const sectPr = `<w:sectPr w:rsidR="00A4"><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr>`
+	documentXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    ` + first + `
+    <w:bookmarkStart w:id="0" w:name="clause"/><w:p w:rsidR="00A2"><w:r><w:t>Edit me</w:t></w:r></w:p><w:bookmarkEnd w:id="0"/>
+    ` + third + `
+    ` + sectPr + `
+  </w:body>
+</w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "surgical-source.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+
+	doc.Paragraphs()[1].Runs()[0].SetText("Edited")
+	if err := doc.SetSurgicalEdit(true); err != nil {
+		t.Fatalf("SetSurgicalEdit failed: %v", err)
+	}
+	if !doc.SurgicalEdit() {
+		t.Fatal("expected surgical editing to be enabled")
+	}
+	doc.AddParagraph("Appended")
+
+	outputPath := filepath.Join(dir, "surgical.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, outputPath, "word/document.xml")
+	for _, want := range []string{
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    ` + first + `
+    <w:bookmarkStart w:id="0" w:name="clause"/><w:p>`,
+		`Edited</w:t></w:r></w:p><w:bookmarkEnd w:id="0"/>
+    ` + third,
+		`Appended</w:t></w:r></w:p>` + sectPr + `
+  </w:body>
+</w:document>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in surgically edited document, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, `00A2`) {
+		t.Fatal("expected the edited paragraph to be re-serialized")
+	}
+
+	if err := doc.SetSurgicalEdit(false); err != nil {
+		t.Fatalf("SetSurgicalEdit failed: %v", err)
+	}
+	regeneratedPath := filepath.Join(dir, "regenerated.docx")
+	if err := doc.SaveAs(regeneratedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if strings.Contains(readZipEntry(t, regeneratedPath, "word/document.xml"), "00A1") {
+		t.Fatal("expected a regular save to regenerate every paragraph")
+	}
+}
+
+func TestSurgicalEditRemovingProofingMarks(t *testing.T) {
+	const third = `<w:p w:rsidR="00B3"><w:r><w:t>Clean</w:t></w:r></w:p>`
+	documentXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p w:rsidR="00B1"><w:proofErr w:type="spellStart"/><w:r><w:t>Teh</w:t></w:r><w:proofErr w:type="spellEnd"/></w:p>` +
+		`<w:p w:rsidR="00B2"><w:r><w:t>Edit me</w:t></w:r></w:p>` + third +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "surgical-proofing.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	if err := doc.SetSurgicalEdit(true); err != nil {
+		t.Fatalf("SetSurgicalEdit failed: %v", err)
+	}
+
+	removed, err := doc.RemoveProofingMarks()
+	if err != nil {
+		t.Fatalf("RemoveProofingMarks failed: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 markers removed from the surgically kept body, got %d", removed)
+	}
+	doc.Paragraphs()[1].Runs()[0].SetText("Edited")
+
+	outputPath := filepath.Join(dir, "surgical-proofing-clean.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, outputPath, "word/document.xml")
+	if strings.Contains(body, "proofErr") {
+		t.Fatalf("expected no proofing marks after saving, got %s", body)
+	}
+	for _, want := range []string{`<w:body><w:p w:rsidR="00B1"><w:r><w:t>Teh</w:t></w:r></w:p><w:p>`, `Edited</w:t></w:r></w:p>` + third} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in surgically edited document, got %s", want, body)
+		}
+	}
+}
+
+func TestSurgicalEditRemovingParagraphAfterContentControl(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:sdt><w:sdtContent><w:p w:rsidR="00B1"><w:r><w:t>A</w:t></w:r></w:p></w:sdtContent></w:sdt><w:p><w:r><w:t>B</w:t></w:r></w:p><w:p><w:r><w:t>C</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "surgical-sdt.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	if err := doc.SetSurgicalEdit(true); err != nil {
+		t.Fatalf("SetSurgicalEdit failed: %v", err)
+	}
+
+	wellFormed := func(body string) {
+		t.Helper()
+		decoder := xml.NewDecoder(strings.NewReader(body))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				return
+			} else if err != nil {
+				t.Fatalf("expected well-formed document.xml, got %v in %s", err, body)
+			}
+		}
+	}
+
+	// the markup around C is balanced, so the content control is still kept byte for byte
+	if err := doc.RemoveParagraph(doc.Paragraphs()[2]); err != nil {
+		t.Fatalf("RemoveParagraph failed: %v", err)
+	}
+	path := filepath.Join(dir, "without-c.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	wellFormed(body)
+	if !strings.Contains(body, `<w:sdt><w:sdtContent><w:p w:rsidR="00B1">`) {
+		t.Fatalf("expected the content control to be preserved, got %s", body)
+	}
+
+	// B follows the end tags of the content control, which must not be lost with it
+	if err := doc.RemoveParagraph(doc.Paragraphs()[1]); err != nil {
+		t.Fatalf("RemoveParagraph failed: %v", err)
+	}
+	path = filepath.Join(dir, "without-b.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body = readZipEntry(t, path, "word/document.xml")
+	wellFormed(body)
+	if strings.Contains(body, ">B<") || !strings.Contains(body, ">A<") {
+		t.Fatalf("expected only paragraph A to remain, got %s", body)
+	}
+}
+
+func TestTableRowPropertyExceptionsRoundTrip(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid><w:gridCol w:w="2000"/></w:tblGrid><w:tr><w:tc><w:p><w:r><w:t>plain</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tblPrEx><w:tblW w:w="5000" w:type="pct"/><w:tblBorders><w:top w:val="double" w:sz="4" w:space="0" w:color="FF0000"/></w:tblBorders><w:tblCellMar><w:left w:w="57" w:type="dxa"/></w:tblCellMar></w:tblPrEx><w:tc><w:p><w:r><w:t>pasted</w:t></w:r></w:p></w:tc></w:tr></w:tbl></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "tblprex.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+
+	table := doc.Tables()[0]
+	if _, ok := table.Row(0).PropertyExceptions(); ok {
+		t.Fatal("expected no overrides on the first row")
+	}
+	exceptions, ok := table.Row(1).PropertyExceptions()
+	if !ok || exceptions.Borders[TableBorderTop].Style != "double" || exceptions.CellMargins == nil || *exceptions.CellMargins.Left != 57 {
+		t.Fatalf("unexpected row overrides %+v", exceptions)
+	}
+	exceptions.Alignment = TableAlignmentCenter
+	table.Row(0).SetPropertyExceptions(TablePropertyExceptions{Alignment: TableAlignmentRight})
+
+	resavedPath := filepath.Join(dir, "tblprex-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, resavedPath, "word/document.xml")
+	for _, want := range []string{
+		`<w:tr><w:tblPrEx><w:jc w:val="right"/></w:tblPrEx><w:tc>`,
+		`<w:tblPrEx><w:tblW w:w="5000" w:type="pct"></w:tblW><w:jc w:val="center"/><w:tblBorders><w:top w:val="double"`,
+		`<w:tblCellMar><w:left w:w="57" w:type="dxa"/></w:tblCellMar></w:tblPrEx>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in %s", want, body)
+		}
+	}
+}
+
+func TestRunExplicitDefaultsRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	auto := paragraph.AddRun("auto color")
+	auto.SetColorAuto()
+	unset := paragraph.AddRun(" inherited")
+	defaults := paragraph.AddRun(" explicit defaults")
+	defaults.SetFont("Calibri")
+	defaults.SetSize(11)
+	defaults.SetHighlight(WDColorIndexNone)
+	cleared := paragraph.AddRun(" cleared")
+	cleared.SetColor("FF0000")
+	cleared.ClearColor()
+
+	if !auto.HasColor() || unset.HasColor() || cleared.HasColor() {
+		t.Fatal("expected only the automatic color to count as set")
+	}
+
+	path := filepath.Join(t.TempDir(), "explicit-defaults.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	for _, want := range []string{
+		`<w:color w:val="auto"/></w:rPr><w:t>auto color</w:t>`,
+		`<w:sz w:val="22"/><w:szCs w:val="22"/><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri"/><w:highlight w:val="none"/>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in %s", want, body)
+		}
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs := reopened.Paragraphs()[0].Runs()
+	if !runs[0].HasColor() || runs[0].Color() != "auto" {
+		t.Fatal("expected w:color auto to read back as an explicit color")
+	}
+	if runs[1].HasColor() || runs[1].HasFont() || runs[1].HasSize() || runs[1].HasHighlight() {
+		t.Fatal("expected the plain run to inherit everything")
+	}
+	if !runs[2].HasFont() || !runs[2].HasSize() || runs[2].Highlight() != WDColorIndexNone {
+		t.Fatal("expected explicit default values to survive")
+	}
+	if runs[3].HasColor() || runs[3].Color() != "auto" {
+		t.Fatal("expected the cleared color to stay unset")
+	}
+}
+
+func TestParagraphMarkFormatRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	spacer := doc.AddParagraph()
+	size, color := 2, "FF0000"
+	spacer.SetMarkFormat(RunFormat{Size: &size, Color: &color, Bold: boolPtr(true)})
+
+	path := filepath.Join(t.TempDir(), "mark-format.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	body := readZipEntry(t, path, "word/document.xml")
+	if !strings.Contains(body, `<w:p><w:pPr><w:rPr><w:b/><w:sz w:val="4"/><w:szCs w:val="4"/><w:color w:val="FF0000"/></w:rPr></w:pPr></w:p>`) {
+		t.Fatalf("expected the mark formatting inside pPr, got %s", body)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraph := reopened.Paragraphs()[0]
+	format, ok := paragraph.MarkFormat()
+	if !ok || format.Size == nil || *format.Size != 2 || format.Color == nil || *format.Color != "FF0000" || format.Bold == nil || format.Italic != nil {
+		t.Fatalf("unexpected mark format %+v", format)
+	}
+	paragraph.ClearMarkFormat()
+	if _, ok := paragraph.MarkFormat(); ok {
+		t.Fatal("expected no mark format after clearing")
+	}
+}
+
+func TestFlattenAndRemoveHyperlinks(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	paragraph.AddRun("See ")
+	paragraph.AddTextWithAutoLinks("https://example.com")
+	paragraph.AddCrossReference("intro", "the introduction")
+
+	linkedPath := filepath.Join(t.TempDir(), "linked.docx")
+	if err := doc.SaveAs(linkedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if rels := readZipEntry(t, linkedPath, "word/_rels/document.xml.rels"); !strings.Contains(rels, "https://example.com") {
+		t.Fatalf("expected hyperlink relationship before flattening, got %s", rels)
+	}
+
+	for _, remove := range []bool{false, true} {
+		reopened, err := OpenDocument(linkedPath)
+		if err != nil {
+			t.Fatalf("OpenDocument failed: %v", err)
+		}
+		flatten := reopened.FlattenHyperlinks
+		if remove {
+			flatten = reopened.RemoveHyperlinks
+		}
+		count, err := flatten()
+		if err != nil {
+			t.Fatalf("unlinking failed: %v", err)
+		}
+		if count != 2 {
+			t.Fatalf("expected 2 runs unlinked, got %d", count)
+		}
+		outputPath := filepath.Join(t.TempDir(), "flat.docx")
+		if err := reopened.SaveAs(outputPath); err != nil {
+			t.Fatalf("SaveAs failed: %v", err)
+		}
+		reopened.Close()
+
+		if documentXML := readZipEntry(t, outputPath, "word/document.xml"); strings.Contains(documentXML, "<w:hyperlink") {
+			t.Fatalf("expected no hyperlinks after unlinking, got %s", documentXML)
+		}
+		if rels := readZipEntry(t, outputPath, "word/_rels/document.xml.rels"); strings.Contains(rels, RelTypeHyperlink) {
+			t.Fatalf("expected hyperlink relationship to be removed, got %s", rels)
+		}
+
+		flat, err := OpenDocument(outputPath)
+		if err != nil {
+			t.Fatalf("OpenDocument failed: %v", err)
+		}
+		runs := flat.Paragraphs()[0].Runs()
+		if got := flat.Paragraphs()[0].Text(); got != "See https://example.comthe introduction" {
+			t.Fatalf("expected text to be kept, got %q", got)
+		}
+		link := runs[1]
+		if link.HasHyperlink() {
+			t.Fatalf("expected run to be unlinked")
+		}
+		if remove && (link.HasColor() || link.Underline() != WDUnderlineNone) {
+			t.Fatalf("expected hyperlink look to be removed, got color %q underline %q", link.Color(), link.Underline())
+		}
+		if !remove && (link.Color() != hyperlinkColor || link.Underline() != WDUnderlineSingle) {
+			t.Fatalf("expected hyperlink look to be kept, got color %q underline %q", link.Color(), link.Underline())
+		}
+		flat.Close()
+	}
+}
+
+func TestPictureNativeSizeAfterResize(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "native.png")
+	createTestImage(t, imgPath, 40, 30)
+
+	doc := NewDocument()
+	pic, err := doc.AddParagraph().AddRun("").AddPicture(imgPath, InchesToEMU(1), InchesToEMU(1))
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "native.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedPic := reopened.Paragraphs()[0].Runs()[0].Picture()
+	if reopenedPic == nil {
+		t.Fatalf("expected picture after reopen")
+	}
+	if reopenedPic.WidthEMU() != pic.WidthEMU() || reopenedPic.WidthEMU() != reopenedPic.HeightEMU() {
+		t.Fatalf("expected square display size, got %dx%d", reopenedPic.WidthEMU(), reopenedPic.HeightEMU())
+	}
+	width, height, err := reopenedPic.NativeSizeEMU()
+	if err != nil {
+		t.Fatalf("NativeSizeEMU failed: %v", err)
+	}
+	if width != 40*9525 || height != 30*9525 {
+		t.Fatalf("expected native size %dx%d, got %dx%d", 40*9525, 30*9525, width, height)
+	}
+}
+
+func TestInsertParagraphAtIndex(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("First")
+	doc.AddTable(1, 1)
+	doc.AddParagraph("Last")
+	sourcePath := filepath.Join(t.TempDir(), "source.docx")
+	if err := doc.SaveAs(sourcePath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+
+	// body elements are now: First, table, Last, section properties
+	if _, err := doc.InsertParagraphAtIndex(4, "End"); err != nil {
+		t.Fatalf("InsertParagraphAtIndex failed: %v", err)
+	}
+	if _, err := doc.InsertParagraphAtIndex(1, "Before table"); err != nil {
+		t.Fatalf("InsertParagraphAtIndex failed: %v", err)
+	}
+	if _, err := doc.InsertParagraphAtIndex(0, "Top"); err != nil {
+		t.Fatalf("InsertParagraphAtIndex failed: %v", err)
+	}
+	if _, err := doc.InsertParagraphAtIndex(-1, "Invalid"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange for negative index, got %v", err)
+	}
+	if _, err := doc.InsertParagraphAtIndex(100, "Invalid"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange for index past the end, got %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "insert-index.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	documentXML := readZipEntry(t, outputPath, "word/document.xml")
+	if strings.Index(documentXML, "Before table") > strings.Index(documentXML, "<w:tbl>") {
+		t.Fatalf("expected inserted paragraph before the table, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	var texts []string
+	for _, paragraph := range reopened.Paragraphs() {
+		texts = append(texts, paragraph.Text())
+	}
+	if got := strings.Join(texts, "|"); got != "Top|First|Before table|Last|End" {
+		t.Fatalf("unexpected paragraph order %q", got)
+	}
+}
+
+func TestTableFloatingPositionRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(1, 1)
+	table.SetFloatingPosition(TablePosition{
+		HorizontalAnchor: "page",
+		VerticalAnchor:   "text",
+		X:                1440,
+		Y:                360,
+		RightFromText:    180,
+	})
+	table.SetOverlap(false)
+	doc.AddTable(1, 1)
+
+	outputPath := filepath.Join(t.TempDir(), "floating-table.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	documentXML := readZipEntry(t, outputPath, "word/document.xml")
+	if !strings.Contains(documentXML, `<w:tblpPr w:rightFromText="180" w:vertAnchor="text" w:horzAnchor="page" w:tblpX="1440" w:tblpY="360"/><w:tblOverlap w:val="never"/>`) {
+		t.Fatalf("expected floating table properties, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	tables := reopened.Tables()
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d", len(tables))
+	}
+	position, ok := tables[0].FloatingPosition()
+	if !ok {
+		t.Fatalf("expected floating position after reopen")
+	}
+	if position.HorizontalAnchor != "page" || position.VerticalAnchor != "text" || position.X != 1440 || position.Y != 360 || position.RightFromText != 180 {
+		t.Fatalf("unexpected floating position %+v", *position)
+	}
+	if overlap, ok := tables[0].Overlap(); !ok || overlap {
+		t.Fatalf("expected overlap to be disallowed, got %v (set %v)", overlap, ok)
+	}
+	if _, ok := tables[1].FloatingPosition(); ok {
+		t.Fatalf("expected second table to stay inline")
+	}
+	if overlap, ok := tables[1].Overlap(); ok || !overlap {
+		t.Fatalf("expected unset overlap to allow overlapping")
+	}
+}
+
+func TestSectionOfParagraph(t *testing.T) {
+	doc := NewDocument()
+	first := doc.AddParagraph("First section")
+	if _, err := doc.SplitSectionAt(first, SectionStartNewPage); err != nil {
+		t.Fatalf("SplitSectionAt failed: %v", err)
+	}
+	second := doc.AddParagraph("Second section")
+	table := doc.AddTable(1, 1)
+	cellParagraph := table.CellAt(0, 0).AddParagraph("In table")
+
+	outputPath := filepath.Join(t.TempDir(), "section-of.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	for _, d := range []*Document{doc, reopened} {
+		paragraphs := d.Paragraphs()
+		firstSection := d.SectionOf(paragraphs[0])
+		lastSection := d.SectionOf(paragraphs[1])
+		if firstSection == nil || lastSection == nil || firstSection == lastSection {
+			t.Fatalf("expected paragraphs in distinct sections, got %p and %p", firstSection, lastSection)
+		}
+		if sections := d.Sections(); lastSection != sections[len(sections)-1] {
+			t.Fatalf("expected second paragraph in the final section")
+		}
+		cell := d.Tables()[0].CellAt(0, 0).Paragraphs()
+		if got := d.SectionOf(cell[len(cell)-1]); got != lastSection {
+			t.Fatalf("expected table paragraph in the final section")
+		}
+	}
+	if doc.SectionOf(second) != doc.SectionOf(cellParagraph) {
+		t.Fatalf("expected table content to share the section of the preceding paragraph")
+	}
+	if doc.SectionOf(NewParagraph()) != nil {
+		t.Fatalf("expected nil section for a detached paragraph")
+	}
+}
+
+func TestRunHalfPointSizeRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	paragraph.AddRun("Ten and a half").SetSizePoints(10.5)
+	paragraph.AddRun("Eleven and a half").SetSizeHalfPoints(23)
+
+	outputPath := filepath.Join(t.TempDir(), "half-points.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if documentXML := readZipEntry(t, outputPath, "word/document.xml"); !strings.Contains(documentXML, `<w:sz w:val="21"/>`) {
+		t.Fatalf("expected 10.5pt to be stored as 21 half-points, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	runs := reopened.Paragraphs()[0].Runs()
+	if got := runs[0].SizePoints(); got != 10.5 {
+		t.Fatalf("expected 10.5pt, got %v", got)
+	}
+	if got := runs[1].SizeHalfPoints(); got != 23 {
+		t.Fatalf("expected 23 half-points, got %d", got)
+	}
+	if got := runs[0].Size(); got != 10 {
+		t.Fatalf("expected whole-point size 10, got %d", got)
+	}
+
+	format := runs[1].format()
+	if format.SizePoints == nil || *format.SizePoints != 11.5 {
+		t.Fatalf("expected run format to keep the half point, got %+v", format)
+	}
+	copied := reopened.AddParagraph().AddRun("Copy")
+	copied.ApplyFormat(format)
+	if copied.SizeHalfPoints() != 23 {
+		t.Fatalf("expected applied format size of 23 half-points, got %d", copied.SizeHalfPoints())
+	}
+}
+
+func TestAddPictureWithDPI(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "screenshot.png")
+	createTestImage(t, imgPath, 300, 150)
+
+	doc := NewDocument()
+	_, standard, err := doc.AddPicture(imgPath, 0, 0)
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	if standard.WidthEMU() != 300*9525 {
+		t.Fatalf("expected 96 DPI default width %d, got %d", 300*9525, standard.WidthEMU())
+	}
+	_, hiDPI, err := doc.AddPictureWithOptions(imgPath, 0, 0, PictureOptions{DPI: 300})
+	if err != nil {
+		t.Fatalf("AddPictureWithOptions failed: %v", err)
+	}
+	if hiDPI.WidthEMU() != InchesToEMU(1) || hiDPI.HeightEMU() != InchesToEMU(0.5) {
+		t.Fatalf("expected 1in x 0.5in at 300 DPI, got %dx%d EMU", hiDPI.WidthEMU(), hiDPI.HeightEMU())
+	}
+	_, scaled, err := doc.AddParagraph().AddPictureWithOptions(imgPath, 0, InchesToEMU(1), PictureOptions{DPI: 72})
+	if err != nil {
+		t.Fatalf("AddPictureWithOptions on paragraph failed: %v", err)
+	}
+	if scaled.WidthEMU() != InchesToEMU(2) {
+		t.Fatalf("expected aspect-preserving width of 2in, got %d EMU", scaled.WidthEMU())
+	}
+
+	data, err := os.ReadFile(imgPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	_, fromBytes, err := doc.AddPictureFromBytes(data, "chart.png", 0, 0, PictureOptions{DPI: 150})
+	if err != nil {
+		t.Fatalf("AddPictureFromBytes failed: %v", err)
+	}
+	if fromBytes.WidthEMU() != InchesToEMU(2) || fromBytes.HeightEMU() != InchesToEMU(1) {
+		t.Fatalf("expected 2in x 1in at 150 DPI, got %dx%d EMU", fromBytes.WidthEMU(), fromBytes.HeightEMU())
+	}
+	if embedded, err := fromBytes.ImageData(); err != nil || !bytes.Equal(embedded, data) {
+		t.Fatalf("expected the image bytes to be embedded, got err %v", err)
+	}
+	if _, _, err := doc.AddParagraph().AddPictureFromBytes(data, "chart", 0, 0, PictureOptions{}); !errors.Is(err, ErrUnsupportedImage) {
+		t.Fatalf("expected ErrUnsupportedImage without a format extension, got %v", err)
+	}
+}
+
+func TestPictureHiddenAndAspectLockRoundTrip(t *testing.T) {
+	imgPath := filepath.Join(t.TempDir(), "flags.png")
+	createTestImage(t, imgPath, 4, 3)
+
+	doc := NewDocument()
+	_, locked, err := doc.AddPicture(imgPath, 0, 0)
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	if !locked.LockAspectRatio() || locked.Hidden() {
+		t.Fatalf("expected new picture to be visible with a locked aspect ratio")
+	}
+	_, free, err := doc.AddPicture(imgPath, 0, 0)
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	free.SetLockAspectRatio(false)
+	free.SetHidden(true)
+
+	outputPath := filepath.Join(t.TempDir(), "picture-flags.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if documentXML := readZipEntry(t, outputPath, "word/document.xml"); strings.Count(documentXML, `noChangeAspect="1"`) != 1 || !strings.Contains(documentXML, `hidden="1"`) {
+		t.Fatalf("expected one locked and one hidden picture, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraphs := reopened.Paragraphs()
+	first := paragraphs[0].Runs()[0].Picture()
+	second := paragraphs[1].Runs()[0].Picture()
+	if first == nil || second == nil {
+		t.Fatalf("expected pictures after reopen")
+	}
+	if !first.LockAspectRatio() || first.Hidden() {
+		t.Fatalf("expected first picture locked and visible")
+	}
+	if second.LockAspectRatio() || !second.Hidden() {
+		t.Fatalf("expected second picture unlocked and hidden")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	dir := t.TempDir()
+	placeholderPath := filepath.Join(dir, "placeholder.png")
+	createTestImage(t, placeholderPath, 4, 4)
+	logoPath := filepath.Join(dir, "logo.png")
+	createTestImage(t, logoPath, 8, 2)
+
+	template := NewDocument()
+	header, err := template.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	header.AddParagraph("{{title}}")
+	greeting := template.AddParagraph()
+	greeting.AddRun("Dear {{cust").SetBold(true)
+	greeting.AddRun("omer.name}}, your total is {{total}} {{missing}}")
+	table := template.AddTable(2, 2)
+	table.CellAt(0, 0).SetText("Item")
+	table.CellAt(0, 1).SetText("Qty")
+	table.CellAt(1, 0).SetText("{{items.name}}")
+	table.CellAt(1, 1).SetText("{{items.qty}}")
+	_, logo, err := template.AddPicture(placeholderPath, InchesToEMU(1), InchesToEMU(1))
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	logo.description = "{{logo}}"
+
+	templatePath := filepath.Join(dir, "template.docx")
+	if err := template.SaveAs(templatePath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	templateXML := readZipEntry(t, templatePath, "word/document.xml")
+
+	doc, err := RenderTemplate(templatePath, map[string]any{
+		"title":    "Invoice",
+		"customer": map[string]any{"name": "Ada"},
+		"total":    42.5,
+		"items": []map[string]any{
+			{"name": "Widget", "qty": 2},
+			{"name": "Gadget", "qty": 1},
+		},
+		"logo": TemplateImage{Path: logoPath},
+	})
+	if err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	if err := doc.Save(); err == nil {
+		t.Fatalf("expected Save to refuse overwriting the template")
+	}
+	outputPath := filepath.Join(dir, "rendered.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if got := readZipEntry(t, templatePath, "word/document.xml"); got != templateXML {
+		t.Fatalf("expected the template to be left unchanged")
+	}
+
+	rendered, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer rendered.Close()
+	paragraphs := rendered.Paragraphs()
+	if got := paragraphs[0].Text(); got != "Dear Ada, your total is 42.5 {{missing}}" {
+		t.Fatalf("unexpected greeting %q", got)
+	}
+	if !paragraphs[0].Runs()[0].IsBold() {
+		t.Fatalf("expected the value to keep the formatting of the run holding the token")
+	}
+	rows := rendered.Tables()[0].Rows()
+	if len(rows) != 3 {
+		t.Fatalf("expected header row plus 2 item rows, got %d", len(rows))
+	}
+	if rows[1].Cell(0).Text() != "Widget" || rows[2].Cell(0).Text() != "Gadget" || rows[2].Cell(1).Text() != "1" {
+		t.Fatalf("unexpected item rows %q/%q", rows[1].Cell(0).Text(), rows[2].Cell(0).Text())
+	}
+	renderedHeader, err := rendered.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	if got := renderedHeader.Paragraphs()[0].Text(); got != "Invoice" {
+		t.Fatalf("expected header token to be filled, got %q", got)
+	}
+	var picture *Picture
+	for _, paragraph := range paragraphs {
+		for _, run := range paragraph.Runs() {
+			if run.Picture() != nil {
+				picture = run.Picture()
+			}
+		}
+	}
+	if picture == nil {
+		t.Fatalf("expected picture after rendering")
+	}
+	width, height, err := picture.NativeSizeEMU()
+	if err != nil {
+		t.Fatalf("NativeSizeEMU failed: %v", err)
+	}
+	if width != 8*9525 || height != 2*9525 {
+		t.Fatalf("expected the logo image, got native size %dx%d", width, height)
+	}
+	if picture.WidthEMU() != InchesToEMU(1) {
+		t.Fatalf("expected the placeholder size to be kept, got %d", picture.WidthEMU())
+	}
+}
+
+func TestRenderTemplateHeaderPicture(t *testing.T) {
+	dir := t.TempDir()
+	placeholderPath := filepath.Join(dir, "placeholder.png")
+	createTestImage(t, placeholderPath, 4, 4)
+	logoPath := filepath.Join(dir, "logo.png")
+	createTestImage(t, logoPath, 8, 2)
+
+	template := NewDocument()
+	header, err := template.Header()
+	if err != nil {
+		t.Fatalf("Header failed: %v", err)
+	}
+	placeholder, err := header.AddParagraph().AddRun("").AddPicture(placeholderPath, InchesToEMU(1), InchesToEMU(1))
+	if err != nil {
+		t.Fatalf("AddPicture failed: %v", err)
+	}
+	// reference the placeholder from the header part, as Word does for a header logo
+	placeholder.relID = template.pkg.ensureRelationship(header.part.URI, RelTypeImage, placeholder.target)
+	placeholder.description = "{{logo}}"
+	header.updateXMLData()
+	templatePath := filepath.Join(dir, "header-template.docx")
+	if err := template.SaveAs(templatePath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	doc, err := RenderTemplate(templatePath, map[string]any{"logo": TemplateImage{Path: logoPath}})
+	if err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	outputPath := filepath.Join(dir, "header-rendered.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	headerURI := header.part.URI
+	embed := regexp.MustCompile(`r:embed="([^"]+)"`).FindStringSubmatch(readZipEntry(t, outputPath, headerURI))
+	if embed == nil {
+		t.Fatalf("expected the header to embed a picture")
+	}
+	rels := readZipEntry(t, outputPath, path.Join(path.Dir(headerURI), "_rels", path.Base(headerURI)+".rels"))
+	target := regexp.MustCompile(`Id="` + embed[1] + `"[^>]*Target="([^"]+)"|Target="([^"]+)"[^>]*Id="` + embed[1] + `"`).FindStringSubmatch(rels)
+	if target == nil {
+		t.Fatalf("expected relationship %s in the header relationships, got %s", embed[1], rels)
+	}
+	media := target[1] + target[2]
+	config, _, err := image.DecodeConfig(strings.NewReader(readZipEntry(t, outputPath, path.Join(path.Dir(headerURI), media))))
+	if err != nil {
+		t.Fatalf("expected the header image %s to be saved: %v", media, err)
+	}
+	if config.Width != 8 || config.Height != 2 {
+		t.Fatalf("expected the logo image, got %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestTableExpandRowTemplate(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(2, 2)
+	table.CellAt(0, 0).SetText("Description")
+	table.CellAt(0, 1).SetText("Amount")
+	table.CellAt(1, 0).AddParagraph().AddRun("{{description}}").SetItalic(true)
+	table.CellAt(1, 1).SetText("{{amount}} {{currency}}")
+
+	records := []map[string]string{
+		{"description": "Consulting", "amount": "100"},
+		{"description": "Support", "amount": "50"},
+		{"description": "Travel", "amount": "20"},
+	}
+	if err := table.ExpandRowTemplate(1, records); err != nil {
+		t.Fatalf("ExpandRowTemplate failed: %v", err)
+	}
+	if err := table.ExpandRowTemplate(10, records); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "row-template.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	rows := reopened.Tables()[0].Rows()
+	if len(rows) != 4 {
+		t.Fatalf("expected header row plus 3 record rows, got %d", len(rows))
+	}
+	for i, record := range records {
+		row := rows[i+1]
+		if got := strings.TrimSpace(row.Cell(0).Text()); got != record["description"] {
+			t.Fatalf("row %d: expected description %q, got %q", i, record["description"], got)
+		}
+		if got := row.Cell(1).Text(); got != record["amount"]+" {{currency}}" {
+			t.Fatalf("row %d: expected amount with unfilled currency token, got %q", i, got)
+		}
+		runs := row.Cell(0).Paragraphs()[len(row.Cell(0).Paragraphs())-1].Runs()
+		if len(runs) == 0 || !runs[0].IsItalic() {
+			t.Fatalf("row %d: expected copies to keep the template formatting", i)
+		}
+	}
+
+	empty := doc.AddTable(2, 1)
+	empty.CellAt(1, 0).SetText("{{description}}")
+	if err := empty.ExpandRowTemplate(1, nil); err != nil {
+		t.Fatalf("ExpandRowTemplate failed: %v", err)
+	}
+	if len(empty.Rows()) != 1 {
+		t.Fatalf("expected template row to be removed without records, got %d rows", len(empty.Rows()))
+	}
+}
+
+func TestTableExpandRowTemplateUniqueIdentifiers(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"><w:body><w:tbl><w:tr><w:tc>` +
+		`<w:p w14:paraId="1A2B3C4D" w14:textId="77777777"><w:bookmarkStart w:id="5" w:name="item"/><w:r><w:t>{{name}}</w:t></w:r><w:bookmarkEnd w:id="5"/></w:p>` +
+		`</w:tc></w:tr></w:tbl><w:sectPr/></w:body></w:document>`
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	sourcePath := filepath.Join(t.TempDir(), "row-ids.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+
+	table := doc.Tables()[0]
+	if err := table.ExpandRowTemplate(0, []map[string]string{{"name": "a"}, {"name": "b"}, {"name": "c"}}); err != nil {
+		t.Fatalf("ExpandRowTemplate failed: %v", err)
+	}
+	xml := table.ToXML()
+	if strings.Count(xml, `w14:paraId="1A2B3C4D"`) != 1 || strings.Count(xml, "w14:textId") != 1 {
+		t.Fatalf("expected only the first copy to keep the paragraph IDs, got %s", xml)
+	}
+	if strings.Contains(xml, "bookmarkStart") {
+		t.Fatalf("expected copies not to repeat the bookmark, got %s", xml)
+	}
+	if got := table.Rows()[0].Cell(0).Paragraphs()[0].ParagraphID(); got != "1A2B3C4D" {
+		t.Fatalf("expected the first copy to keep its paragraph ID, got %q", got)
+	}
+}
+
+func TestRunFormatEquals(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph()
+	first := paragraph.AddRun("One")
+	first.SetBold(true)
+	first.SetColor("FF0000")
+	second := paragraph.AddRun("Two")
+	second.SetBold(true)
+	second.SetColor("FF0000")
+
+	if !first.FormatEquals(second) {
+		t.Fatalf("expected runs with the same formatting to compare equal")
+	}
+	if first.Equals(second) {
+		t.Fatalf("expected runs with different text not to be equal")
+	}
+	second.SetSizePoints(10.5)
+	if first.FormatEquals(second) {
+		t.Fatalf("expected a size difference to be detected")
+	}
+	second.ClearSize()
+	second.SetText("One")
+	if !first.Equals(second) {
+		t.Fatalf("expected runs with the same text and formatting to be equal")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "format-equals.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if runs := reopened.Paragraphs()[0].Runs(); !runs[0].FormatEquals(first) || !runs[0].Equals(runs[1]) {
+		t.Fatalf("expected reopened runs to keep equal formatting")
+	}
+	if first.FormatEquals(nil) {
+		t.Fatalf("expected a run not to equal nil")
+	}
+}
+
+func TestParagraphFullWidthShading(t *testing.T) {
+	doc := NewDocument()
+	note := doc.AddParagraph("Note: back up your data first.")
+	note.SetFullWidthShading("DEEAF6")
+
+	outputPath := filepath.Join(t.TempDir(), "callout.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraph := reopened.Paragraphs()[0]
+	shading, ok := paragraph.Shading()
+	if !ok || shading.Fill != "DEEAF6" {
+		t.Fatalf("expected shading fill DEEAF6, got %+v", shading)
+	}
+	for _, side := range []ParagraphBorderSide{ParagraphBorderTop, ParagraphBorderLeft, ParagraphBorderBottom, ParagraphBorderRight} {
+		border, ok := paragraph.Border(side)
+		if !ok || border.Color != "DEEAF6" || border.Space != 4 {
+			t.Fatalf("expected %s border in the fill color padding the text, got %+v", side, border)
+		}
+	}
+	if left, right, _, _ := paragraph.Indentation(); left != 90 || right != 90 {
+		t.Fatalf("expected the band to be inset by its padding, got left %d right %d", left, right)
+	}
+}
+
+func TestOpenDocumentBytes(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("From memory")
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+
+	opened, err := OpenDocumentBytes(data)
+	if err != nil {
+		t.Fatalf("OpenDocumentBytes failed: %v", err)
+	}
+	defer opened.Close()
+	if got := opened.Paragraphs()[0].Text(); got != "From memory" {
+		t.Fatalf("expected paragraph text to survive, got %q", got)
+	}
+	if err := opened.Save(); err == nil {
+		t.Fatalf("expected Save to fail without a file path")
+	}
+	opened.AddParagraph("Edited")
+	outputPath := filepath.Join(t.TempDir(), "from-bytes.docx")
+	if err := opened.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if documentXML := readZipEntry(t, outputPath, "word/document.xml"); !strings.Contains(documentXML, "Edited") {
+		t.Fatalf("expected edit to be saved, got %s", documentXML)
+	}
+
+	if _, err := OpenDocumentBytes([]byte("not a zip")); !errors.Is(err, ErrNotADocx) {
+		t.Fatalf("expected ErrNotADocx for invalid data, got %v", err)
+	}
+	encrypted := append(append([]byte(nil), cfbSignature...), make([]byte, 512)...)
+	if _, err := OpenPackageBytes(encrypted); !errors.Is(err, ErrEncryptedDocument) {
+		t.Fatalf("expected ErrEncryptedDocument for a compound file, got %v", err)
+	}
+}
+
+func TestParagraphAutoSpaceRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	paragraph := doc.AddParagraph("中文English123")
+	paragraph.SetAutoSpaceLatin(false)
+	paragraph.SetAutoSpaceNumbers(false)
+	paragraph.SetRightToLeft(false)
+	doc.AddParagraph("默认")
+
+	outputPath := filepath.Join(t.TempDir(), "autospace.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if documentXML := readZipEntry(t, outputPath, "word/document.xml"); !strings.Contains(documentXML, `<w:autoSpaceDE w:val="0"/><w:autoSpaceDN w:val="0"/><w:bidi w:val="0"/>`) {
+		t.Fatalf("expected auto-space properties before bidi, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	paragraphs := reopened.Paragraphs()
+	if paragraphs[0].AutoSpaceLatin() || paragraphs[0].AutoSpaceNumbers() {
+		t.Fatalf("expected auto-spacing to be turned off after reopen")
+	}
+	if !paragraphs[1].AutoSpaceLatin() || !paragraphs[1].AutoSpaceNumbers() {
+		t.Fatalf("expected auto-spacing to default to on")
+	}
+}
+
+func TestTableRepeatHeaderRows(t *testing.T) {
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid><w:gridCol w:w="2000"/></w:tblGrid><w:tr><w:trPr><w:trHeight w:val="400"/><w:jc w:val="center"/></w:trPr><w:tc><w:p><w:r><w:t>Group</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>Label</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>Data</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:sectPr/></w:body></w:document>`)
+	sourcePath := filepath.Join(t.TempDir(), "source.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	table := doc.Tables()[0]
+	table.SetRepeatHeaderRows(2)
+	if got := table.RepeatHeaderRows(); got != 2 {
+		t.Fatalf("expected 2 header rows, got %d", got)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "repeat-header.docx")
+	if err := doc.SaveAs(outputPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	documentXML := readZipEntry(t, outputPath, "word/document.xml")
+	height, header, jc := strings.Index(documentXML, "<w:trHeight"), strings.Index(documentXML, "<w:tblHeader/>"), strings.Index(documentXML, "<w:jc ")
+	if height < 0 || !(height < header && header < jc) {
+		t.Fatalf("expected tblHeader between trHeight and jc, got %s", documentXML)
+	}
+	if strings.Count(documentXML, "<w:tblHeader/>") != 2 {
+		t.Fatalf("expected 2 repeated header rows, got %s", documentXML)
+	}
+
+	reopened, err := OpenDocument(outputPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	reopenedTable := reopened.Tables()[0]
+	if got := reopenedTable.RepeatHeaderRows(); got != 2 {
+		t.Fatalf("expected 2 header rows after reopen, got %d", got)
+	}
+	reopenedTable.SetRepeatHeaderRows(0)
+	if reopenedTable.Rows()[0].RepeatHeader() {
+		t.Fatalf("expected SetRepeatHeaderRows(0) to clear header rows")
+	}
+}
+
+func TestSmartTagAndCustomXMLWrappersRoundTrip(t *testing.T) {
+	const documentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body><w:p><w:r><w:t xml:space="preserve">Call </w:t></w:r><w:smartTag w:uri="urn:schemas-microsoft-com:office:smarttags" w:element="PersonName"><w:smartTagPr><w:attr w:name="ProductID" w:val="Jane Doe"/></w:smartTagPr><w:r><w:t xml:space="preserve">Jane </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>Doe</w:t></w:r></w:smartTag><w:r><w:t xml:space="preserve"> at </w:t></w:r><w:customXml w:uri="urn:invoice" w:element="phone"><w:customXmlPr><w:placeholder w:val="number"/></w:customXmlPr><w:customXml w:uri="urn:invoice" w:element="extension"><w:r><w:t>555</w:t></w:r></w:customXml></w:customXml></w:p></w:body></w:document>`
+
+	pkg := NewPackage()
+	pkg.MainDocumentPart().Part.Data = []byte(documentXML)
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "wrappers.docx")
+	if err := pkg.SaveAs(sourcePath); err != nil {
+		t.Fatalf("Package.SaveAs failed: %v", err)
+	}
+
+	doc, err := OpenDocument(sourcePath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer doc.Close()
+	paragraphs := doc.Paragraphs()
+	if len(paragraphs) != 1 || paragraphs[0].Text() != "Call Jane Doe at 555" {
+		t.Fatalf("unexpected paragraph text after load")
+	}
+	resavedPath := filepath.Join(dir, "wrappers-resaved.docx")
+	if err := doc.SaveAs(resavedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	xmlContent := readZipEntry(t, resavedPath, "word/document.xml")
+	for _, fragment := range []string{
+		`<w:smartTag w:uri="urn:schemas-microsoft-com:office:smarttags" w:element="PersonName"><w:smartTagPr><w:attr w:name="ProductID" w:val="Jane Doe"></w:attr></w:smartTagPr><w:r>`,
+		`<w:t>Doe</w:t></w:r></w:smartTag><w:r>`,
+		`<w:customXml w:uri="urn:invoice" w:element="phone"><w:customXmlPr><w:placeholder w:val="number"></w:placeholder></w:customXmlPr><w:customXml w:uri="urn:invoice" w:element="extension"><w:r>`,
+		`<w:t>555</w:t></w:r></w:customXml></w:customXml></w:p>`,
+	} {
+		if !strings.Contains(xmlContent, fragment) {
+			t.Fatalf("expected %s in document XML, got %s", fragment, xmlContent)
+		}
+	}
+	if count := strings.Count(xmlContent, "<w:smartTag "); count != 1 {
+		t.Fatalf("expected the smart tag runs to share one wrapper, got %d", count)
+	}
+
+	reopened, err := OpenDocument(resavedPath)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if text := reopened.Paragraphs()[0].Text(); text != "Call Jane Doe at 555" {
+		t.Fatalf("unexpected text after round trip: %q", text)
+	}
+}
+
+func TestDocumentPageColorRoundTrip(t *testing.T) {
+	doc := NewDocument()
+	doc.AddParagraph("Digital only")
+	if err := doc.SetPageColor("#FFF2CC"); err != nil {
+		t.Fatalf("SetPageColor failed: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page-color.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xmlContent := readZipEntry(t, path, "word/document.xml"); !strings.Contains(xmlContent, `<w:background w:color="FFF2CC"/><w:body>`) {
+		t.Fatalf("expected background before the body, got %s", xmlContent)
+	}
+	if settingsXML := readZipEntry(t, path, "word/settings.xml"); !strings.Contains(settingsXML, "<w:displayBackgroundShape/>") {
+		t.Fatalf("expected displayBackgroundShape in settings, got %s", settingsXML)
+	}
+
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+	if color := reopened.PageColor(); color != "FFF2CC" {
+		t.Fatalf("expected page color FFF2CC, got %q", color)
+	}
+	if !reopened.Settings().DisplayBackgroundShape() {
+		t.Fatal("expected displayBackgroundShape to be read back")
+	}
+
+	// surgical saving splices the changed background in front of the untouched body
+	if err := reopened.SetSurgicalEdit(true); err != nil {
+		t.Fatalf("SetSurgicalEdit failed: %v", err)
+	}
+	if err := reopened.SetPageColor("DEEAF6"); err != nil {
+		t.Fatalf("SetPageColor failed: %v", err)
+	}
+	surgicalPath := filepath.Join(dir, "page-color-surgical.docx")
+	if err := reopened.SaveAs(surgicalPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	xmlContent := readZipEntry(t, surgicalPath, "word/document.xml")
+	if strings.Count(xmlContent, "<w:background") != 1 || !strings.Contains(xmlContent, `<w:background w:color="DEEAF6"/><w:body>`) {
+		t.Fatalf("expected the background to be replaced, got %s", xmlContent)
+	}
+
+	for _, invalid := range []string{"red", "FFF", "#GG0000", "FFF2CC00"} {
+		if err := reopened.SetPageColor(invalid); !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("expected ErrInvalidArgument for page color %q, got %v", invalid, err)
+		}
+	}
+	if got := reopened.PageColor(); got != "DEEAF6" {
+		t.Fatalf("expected a rejected color to leave the page color unchanged, got %q", got)
+	}
+	if err := reopened.SetPageColor(""); err != nil {
+		t.Fatalf("SetPageColor failed: %v", err)
+	}
+	clearedPath := filepath.Join(dir, "page-color-cleared.docx")
+	if err := reopened.SaveAs(clearedPath); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	if xmlContent := readZipEntry(t, clearedPath, "word/document.xml"); strings.Contains(xmlContent, "<w:background") {
+		t.Fatalf("expected the background to be removed, got %s", xmlContent)
+	}
+	if settingsXML := readZipEntry(t, clearedPath, "word/settings.xml"); strings.Contains(settingsXML, "displayBackgroundShape") {
+		t.Fatalf("expected displayBackgroundShape to be removed, got %s", settingsXML)
+	}
+}
+
+func TestTableCellSetTextMultiline(t *testing.T) {
+	doc := NewDocument()
+	table := doc.AddTable(1, 2)
+	table.CellAt(0, 0).SetContentSpacing(0, 0)
+	table.CellAt(0, 0).SetTextMultiline("Jane Doe", "1 Main Street\r\nSpringfield")
+	table.CellAt(0, 1).SetTextWithLineBreaks("Call after 5pm", "Ring twice")
+
+	path := filepath.Join(t.TempDir(), "cell-multiline.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	parsed := reopened.Tables()[0]
+	address := parsed.CellAt(0, 0).Paragraphs()
+	if len(address) != 3 {
+		t.Fatalf("expected one paragraph per line, got %d", len(address))
+	}
+	for i, want := range []string{"Jane Doe", "1 Main Street", "Springfield"} {
+		if got := address[i].Text(); got != want {
+			t.Fatalf("paragraph %d: expected %q, got %q", i, want, got)
+		}
+		if before, after := address[i].spacingBefore, address[i].spacingAfter; before != 0 || after != 0 || !address[i].spacingAfterSet {
+			t.Fatalf("paragraph %d: expected the cell content spacing, got %d/%d", i, before, after)
+		}
+	}
+
+	notes := parsed.CellAt(0, 1).Paragraphs()
+	if len(notes) != 1 || notes[0].Text() != "Call after 5pm\nRing twice" {
+		t.Fatalf("expected a single paragraph with a line break, got %d paragraphs", len(notes))
+	}
+	if xmlContent := readZipEntry(t, path, "word/document.xml"); !strings.Contains(xmlContent, "<w:br/>") {
+		t.Fatalf("expected a line break in the notes cell, got %s", xmlContent)
+	}
+}
+
+func TestAddBlockQuote(t *testing.T) {
+	doc := NewDocument()
+	plain := doc.AddBlockQuote("To be, or not to be")
+	if plain.Style() != "" {
+		t.Fatalf("expected no style without a Quote style definition, got %q", plain.Style())
+	}
+
+	doc.pkg.parts["word/styles.xml"].Data = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/></w:style></w:styles>`)
+	doc.AddBlockQuoteWithBorder("First line\nSecond line", "#A5A5A5")
+
+	path := filepath.Join(t.TempDir(), "block-quote.docx")
+	if err := doc.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+	reopened, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	defer reopened.Close()
+
+	paragraphs := reopened.Paragraphs()
+	if len(paragraphs) != 2 {
+		t.Fatalf("expected 2 paragraphs, got %d", len(paragraphs))
+	}
+	for i, paragraph := range paragraphs {
+		if paragraph.indentLeft != blockQuoteIndent || paragraph.indentRight != blockQuoteIndent {
+			t.Fatalf("paragraph %d: expected both sides indented, got %d/%d", i, paragraph.indentLeft, paragraph.indentRight)
+		}
+		if runs := paragraph.Runs(); len(runs) != 1 || !runs[0].italic {
+			t.Fatalf("paragraph %d: expected a single italic run", i)
+		}
+	}
+	if _, ok := paragraphs[0].Border(ParagraphBorderLeft); ok {
+		t.Fatal("expected no border on the plain block quote")
+	}
+
+	quote := paragraphs[1]
+	if quote.Style() != "Quote" || quote.Text() != "First line\nSecond line" {
+		t.Fatalf("unexpected quote paragraph: style %q, text %q", quote.Style(), quote.Text())
+	}
+	border, ok := quote.Border(ParagraphBorderLeft)
+	if !ok || border.Style != "single" || border.Color != "A5A5A5" || border.Size != blockQuoteBorderSize {
+		t.Fatalf("expected a left rule, got %+v", border)
+	}
+}
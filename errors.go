@@ -0,0 +1,26 @@
+package docx
+
+import "errors"
+
+var (
+	// ErrEncryptedDocument is returned when the file is a password-protected (OLE compound file) document.
+	ErrEncryptedDocument = errors.New("document is encrypted or password protected")
+	// ErrNotADocx is returned when the file is not a zip package containing a Word document.
+	ErrNotADocx = errors.New("file is not a valid DOCX package")
+	// ErrPartNotFound is returned when a package part (main document, header, footer, image) is missing.
+	ErrPartNotFound = errors.New("part not found")
+	// ErrNotAttached is returned when an element is used without belonging to a document package.
+	ErrNotAttached = errors.New("not attached to a document")
+	// ErrUnsupportedImage is returned when an image format is unknown or its data cannot be decoded.
+	ErrUnsupportedImage = errors.New("unsupported image")
+	// ErrRelationshipNotFound is returned when a relationship ID does not resolve to a relationship.
+	ErrRelationshipNotFound = errors.New("relationship not found")
+	// ErrIndexOutOfRange is returned when a row, column or level index is outside the valid range.
+	ErrIndexOutOfRange = errors.New("index out of range")
+	// ErrNotFound is returned when a paragraph, table or section does not belong to the document.
+	ErrNotFound = errors.New("element not found")
+	// ErrInvalidArgument is returned when an argument is empty, negative or otherwise unusable.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNumberingNotFound is returned when a numbering ID has no definition in the numbering part.
+	ErrNumberingNotFound = errors.New("numbering definition not found")
+)
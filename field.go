@@ -0,0 +1,303 @@
+package docx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddField appends a complex field (e.g. "PAGE", "SEQ Figure \* ARABIC") to the paragraph.
+// result is the cached value shown until the field is updated by Word.
+func (p *Paragraph) AddField(instruction, result string) *Run {
+	run := p.AddRun(result)
+	run.SetFieldInstruction(instruction)
+	return run
+}
+
+// AddDateField appends a DATE field using a Word date-time picture such as "MMMM d, yyyy" or
+// "dd.MM.yyyy HH:mm" (the \@ switch). The cached result is today's date formatted with the
+// same picture, so the field reads correctly before Word updates it. An empty format uses
+// Word's default "M/d/yyyy".
+func (p *Paragraph) AddDateField(format string) *Run {
+	return p.addDateField(format, time.Now())
+}
+
+func (p *Paragraph) addDateField(format string, now time.Time) *Run {
+	instruction := "DATE"
+	if format != "" {
+		instruction = fmt.Sprintf(`DATE \@ "%s"`, format)
+	} else {
+		format = "M/d/yyyy"
+	}
+	return p.AddField(instruction, formatWordDate(now, format))
+}
+
+// DateFieldFormat returns the date-time picture of a DATE, TIME, CREATEDATE, SAVEDATE or
+// PRINTDATE field run. ok is false for other runs; format is empty when no \@ switch is set.
+func (r *Run) DateFieldFormat() (format string, ok bool) {
+	fields := strings.Fields(r.fieldInstruction)
+	if len(fields) == 0 {
+		return "", false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "DATE", "TIME", "CREATEDATE", "SAVEDATE", "PRINTDATE":
+	default:
+		return "", false
+	}
+	idx := strings.Index(r.fieldInstruction, `\@`)
+	if idx < 0 {
+		return "", true
+	}
+	rest := strings.TrimSpace(r.fieldInstruction[idx+2:])
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			return rest[1 : end+1], true
+		}
+		return rest[1:], true
+	}
+	if space := strings.IndexAny(rest, " \t"); space >= 0 {
+		rest = rest[:space]
+	}
+	return rest, true
+}
+
+// formatWordDate renders t using a Word date-time picture. Runs of the letters d, M, y, h, H,
+// m and s select date parts, AM/PM and am/pm select the period, and text in single quotes
+// is copied literally.
+func formatWordDate(t time.Time, picture string) string {
+	var builder strings.Builder
+	for i := 0; i < len(picture); {
+		c := picture[i]
+		if c == '\'' {
+			end := strings.IndexByte(picture[i+1:], '\'')
+			if end < 0 {
+				builder.WriteString(picture[i+1:])
+				break
+			}
+			builder.WriteString(picture[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		if rest := picture[i:]; strings.HasPrefix(rest, "AM/PM") || strings.HasPrefix(rest, "am/pm") {
+			period := t.Format("PM")
+			if c == 'a' {
+				period = strings.ToLower(period)
+			}
+			builder.WriteString(period)
+			i += len("AM/PM")
+			continue
+		}
+
+		count := 1
+		for i+count < len(picture) && picture[i+count] == c {
+			count++
+		}
+		switch c {
+		case 'd':
+			switch count {
+			case 1:
+				builder.WriteString(strconv.Itoa(t.Day()))
+			case 2:
+				builder.WriteString(t.Format("02"))
+			case 3:
+				builder.WriteString(t.Format("Mon"))
+			default:
+				builder.WriteString(t.Format("Monday"))
+			}
+		case 'M':
+			switch count {
+			case 1:
+				builder.WriteString(strconv.Itoa(int(t.Month())))
+			case 2:
+				builder.WriteString(t.Format("01"))
+			case 3:
+				builder.WriteString(t.Format("Jan"))
+			default:
+				builder.WriteString(t.Format("January"))
+			}
+		case 'y':
+			if count <= 2 {
+				builder.WriteString(t.Format("06"))
+			} else {
+				builder.WriteString(t.Format("2006"))
+			}
+		case 'h':
+			if count == 1 {
+				builder.WriteString(t.Format("3"))
+			} else {
+				builder.WriteString(t.Format("03"))
+			}
+		case 'H':
+			if count == 1 {
+				builder.WriteString(strconv.Itoa(t.Hour()))
+			} else {
+				builder.WriteString(t.Format("15"))
+			}
+		case 'm':
+			if count == 1 {
+				builder.WriteString(strconv.Itoa(t.Minute()))
+			} else {
+				builder.WriteString(t.Format("04"))
+			}
+		case 's':
+			if count == 1 {
+				builder.WriteString(strconv.Itoa(t.Second()))
+			} else {
+				builder.WriteString(t.Format("05"))
+			}
+		default:
+			builder.WriteString(picture[i : i+count])
+		}
+		i += count
+	}
+	return builder.String()
+}
+
+// AddCaption styles the paragraph as a caption and appends "label N: text", where N is a SEQ
+// field numbering items with the same label (e.g. "Figure", "Table"). The field run is returned.
+func (p *Paragraph) AddCaption(label, text string) *Run {
+	p.SetStyle("Caption")
+	number := 1
+	if p.owner != nil {
+		number = p.owner.countSequenceFields(label) + 1
+	}
+	p.AddRun(label + " ")
+	field := p.AddField(fmt.Sprintf(`SEQ %s \* ARABIC`, label), fmt.Sprintf("%d", number))
+	if text != "" {
+		p.AddRun(": " + text)
+	}
+	return field
+}
+
+// SetFieldInstruction turns the run into a field with the given instruction. The run text is used
+// as the cached field result. Pass an empty instruction to turn the run back into plain text.
+func (r *Run) SetFieldInstruction(instruction string) {
+	r.fieldInstruction = strings.TrimSpace(instruction)
+}
+
+// FieldInstruction returns the field instruction if the run is a field.
+func (r *Run) FieldInstruction() (string, bool) {
+	if r.fieldInstruction == "" {
+		return "", false
+	}
+	return r.fieldInstruction, true
+}
+
+// IsField reports whether the run represents a field.
+func (r *Run) IsField() bool {
+	return r.fieldInstruction != ""
+}
+
+// fieldXML renders the run as a begin/instrText/separate/result/end field sequence.
+func (r *Run) fieldXML(rPrXML, resultContent string) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(`<w:r>%s<w:fldChar w:fldCharType="begin"/></w:r>`, rPrXML))
+	builder.WriteString(fmt.Sprintf(`<w:r>%s<w:instrText xml:space="preserve"> %s </w:instrText></w:r>`, rPrXML, escapeCharData(r.fieldInstruction)))
+	builder.WriteString(fmt.Sprintf(`<w:r>%s<w:fldChar w:fldCharType="separate"/></w:r>`, rPrXML))
+	builder.WriteString(fmt.Sprintf(`<w:r>%s%s</w:r>`, rPrXML, resultContent))
+	builder.WriteString(fmt.Sprintf(`<w:r>%s<w:fldChar w:fldCharType="end"/></w:r>`, rPrXML))
+	return builder.String()
+}
+
+func (dp *DocumentPart) countSequenceFields(label string) int {
+	count := 0
+	prefix := "SEQ " + label
+	countIn := func(paragraphs []*Paragraph) {
+		for _, paragraph := range paragraphs {
+			for _, run := range paragraph.runs {
+				instr := run.fieldInstruction
+				if instr == prefix || strings.HasPrefix(instr, prefix+" ") {
+					count++
+				}
+			}
+		}
+	}
+	var countTables func(tables []*Table)
+	countTables = func(tables []*Table) {
+		for _, table := range tables {
+			for _, row := range table.rows {
+				for _, cell := range row.cells {
+					countIn(cell.paragraphs)
+					countTables(cell.tables)
+				}
+			}
+		}
+	}
+	countIn(dp.paragraphs)
+	countTables(dp.tables)
+	return count
+}
+
+// fieldParser accumulates the runs making up a complex field while a paragraph is parsed.
+type fieldParser struct {
+	depth       int
+	ending      bool
+	instruction strings.Builder
+	runs        []*Run
+	resultStart int
+	inResult    bool
+}
+
+func (fp *fieldParser) begin() {
+	if fp.depth == 0 {
+		fp.instruction.Reset()
+		fp.runs = nil
+		fp.resultStart = -1
+		fp.inResult = false
+	}
+	fp.depth++
+}
+
+// separate marks the start of the cached result. inRun reports whether the separator sits
+// inside a run that has not been collected yet, in which case the result starts after it.
+func (fp *fieldParser) separate(inRun bool) {
+	if fp.depth == 1 && !fp.inResult {
+		fp.inResult = true
+		fp.resultStart = len(fp.runs)
+		if inRun {
+			fp.resultStart++
+		}
+	}
+}
+
+func (fp *fieldParser) end() {
+	if fp.depth == 0 {
+		return
+	}
+	fp.depth--
+	if fp.depth == 0 {
+		fp.ending = true
+	}
+}
+
+func (fp *fieldParser) active() bool {
+	return fp.depth > 0 || fp.ending
+}
+
+// finish collapses the collected runs into a single field run.
+func (fp *fieldParser) finish(newRun func() *Run) *Run {
+	fp.ending = false
+	var field *Run
+	if fp.resultStart >= 0 && fp.resultStart < len(fp.runs) {
+		field = fp.runs[fp.resultStart]
+	} else if len(fp.runs) > 0 {
+		field = fp.runs[0]
+		field.text = ""
+	} else {
+		field = newRun()
+	}
+	if fp.resultStart >= 0 && fp.resultStart < len(fp.runs) {
+		var text strings.Builder
+		for _, run := range fp.runs[fp.resultStart:] {
+			text.WriteString(run.text)
+			if field.picture == nil && run.picture != nil {
+				field.picture = run.picture
+			}
+		}
+		field.SetText(text.String())
+	}
+	field.SetFieldInstruction(fp.instruction.String())
+	fp.runs = nil
+	return field
+}
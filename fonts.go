@@ -0,0 +1,112 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// EmbeddedFontStyle identifies which face of a font an embedded font part provides.
+type EmbeddedFontStyle string
+
+const (
+	EmbeddedFontRegular    EmbeddedFontStyle = "regular"
+	EmbeddedFontBold       EmbeddedFontStyle = "bold"
+	EmbeddedFontItalic     EmbeddedFontStyle = "italic"
+	EmbeddedFontBoldItalic EmbeddedFontStyle = "boldItalic"
+)
+
+// EmbeddedFont describes a font face embedded in the document package.
+// Data holds the raw part bytes; .odttf parts are obfuscated using FontKey as described in ECMA-376.
+type EmbeddedFont struct {
+	Name    string
+	Style   EmbeddedFontStyle
+	FontKey string
+	URI     string
+	Data    []byte
+}
+
+type fontTableXML struct {
+	Fonts []struct {
+		Name            string            `xml:"name,attr"`
+		EmbedRegular    *fontEmbedElement `xml:"embedRegular"`
+		EmbedBold       *fontEmbedElement `xml:"embedBold"`
+		EmbedItalic     *fontEmbedElement `xml:"embedItalic"`
+		EmbedBoldItalic *fontEmbedElement `xml:"embedBoldItalic"`
+	} `xml:"font"`
+}
+
+type fontEmbedElement struct {
+	ID      string `xml:"id,attr"`
+	FontKey string `xml:"fontKey,attr"`
+}
+
+// EmbeddedFonts lists the fonts embedded in the document by reading word/fontTable.xml
+// and the font parts it references. Documents without embedded fonts (or with an
+// unreadable font table) return an empty slice.
+func (d *Document) EmbeddedFonts() []EmbeddedFont {
+	if d.docPart == nil {
+		return nil
+	}
+	fonts, err := d.pkg.embeddedFonts(d.docPart.Part.URI)
+	if err != nil {
+		return nil
+	}
+	return fonts
+}
+
+func (p *Package) fontTableURI(docURI string) string {
+	for _, rel := range p.relations[docURI] {
+		if rel.Type == RelTypeFontTable {
+			return resolveRelationshipTarget(docURI, rel.Target)
+		}
+	}
+	return "word/fontTable.xml"
+}
+
+func (p *Package) embeddedFonts(docURI string) ([]EmbeddedFont, error) {
+	fonts := make([]EmbeddedFont, 0)
+	tableURI := p.fontTableURI(docURI)
+	part, ok := p.parts[tableURI]
+	if !ok || len(part.Data) == 0 {
+		return fonts, nil
+	}
+
+	var table fontTableXML
+	if err := xml.Unmarshal(part.Data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse font table: %w", err)
+	}
+
+	for _, font := range table.Fonts {
+		embeds := []struct {
+			style EmbeddedFontStyle
+			elem  *fontEmbedElement
+		}{
+			{EmbeddedFontRegular, font.EmbedRegular},
+			{EmbeddedFontBold, font.EmbedBold},
+			{EmbeddedFontItalic, font.EmbedItalic},
+			{EmbeddedFontBoldItalic, font.EmbedBoldItalic},
+		}
+		for _, embed := range embeds {
+			if embed.elem == nil || embed.elem.ID == "" {
+				continue
+			}
+			embedded := EmbeddedFont{
+				Name:    font.Name,
+				Style:   embed.style,
+				FontKey: embed.elem.FontKey,
+			}
+			for _, rel := range p.relations[tableURI] {
+				if rel.ID != embed.elem.ID {
+					continue
+				}
+				embedded.URI = resolveRelationshipTarget(tableURI, rel.Target)
+				if fontPart, exists := p.parts[embedded.URI]; exists {
+					embedded.Data = fontPart.Data
+				}
+				break
+			}
+			fonts = append(fonts, embedded)
+		}
+	}
+	return fonts, nil
+}
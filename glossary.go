@@ -0,0 +1,37 @@
+package docx
+
+// GlossaryParagraphs returns the paragraphs of the building blocks (AutoText, Quick Parts)
+// stored in the glossary document (word/glossary/document.xml). The glossary part itself is
+// written back unchanged on save; edits to the returned paragraphs are not persisted.
+func (d *Document) GlossaryParagraphs() []*Paragraph {
+	if d.docPart == nil {
+		return nil
+	}
+	glossary := d.pkg.glossaryPart(d.docPart.Part.URI)
+	if glossary == nil {
+		return nil
+	}
+	return glossary.AllParagraphs()
+}
+
+// glossaryPart parses the glossary document related to the given main document part, or
+// returns nil when the document has none.
+func (p *Package) glossaryPart(docURI string) *DocumentPart {
+	for _, rel := range p.relations[docURI] {
+		if rel.Type != RelTypeGlossary {
+			continue
+		}
+		part, ok := p.parts[resolveRelationshipTarget(docURI, rel.Target)]
+		if !ok || len(part.Data) == 0 {
+			return nil
+		}
+		// parse a detached copy so nothing regenerates the preserved glossary XML
+		detached := &Part{URI: part.URI, ContentType: part.ContentType, Data: part.Data}
+		glossary := &DocumentPart{Part: detached, pkg: p}
+		if err := glossary.loadFromXML(); err != nil {
+			return nil
+		}
+		return glossary
+	}
+	return nil
+}
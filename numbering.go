@@ -1,5 +1,15 @@
 package docx
 
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 const (
 	defaultDecimalNumID = 1
 	defaultBulletNumID  = 2
@@ -62,6 +72,9 @@ func NewNumberingPart() *NumberingPart {
 type Numbering struct {
 	pkg  *Package
 	part *Part
+
+	// restartBySection holds the numbering IDs that restart in every section
+	restartBySection map[int]bool
 }
 
 // NewNumbering creates a numbering helper for the given package
@@ -104,8 +117,574 @@ func (n *Numbering) BulletedListID() int {
 	return defaultBulletNumID
 }
 
+// OutlineListID returns the numbering ID of a nine-level legal outline list (1., 1.1., 1.1.1., ...),
+// registering its definition in the numbering part on first use. It returns 0 if the existing
+// numbering part cannot be parsed.
+func (n *Numbering) OutlineListID() int {
+	return n.registerList(outlineListName, "multilevel", outlineLevelXML)
+}
+
+// LetteredListID returns the numbering ID of a nine-level lettered list (A., a., i., ...),
+// registering its definition in the numbering part on first use. It returns 0 if the existing
+// numbering part cannot be parsed.
+func (n *Numbering) LetteredListID() int {
+	return n.registerList(letteredListName, "hybridMultilevel", letteredLevelXML)
+}
+
 // Part returns the underlying numbering part, ensuring it exists
 func (n *Numbering) Part() *Part {
 	n.ensureDefault()
 	return n.part
 }
+
+const (
+	outlineListName  = "Outline List"
+	letteredListName = "Lettered List"
+)
+
+// outlineIndents holds the left indent and hanging indent, in twips, of each outline level,
+// widening as the level labels grow.
+var outlineIndents = [9][2]int{
+	{360, 360}, {792, 432}, {1224, 504}, {1728, 648}, {2232, 792},
+	{2736, 936}, {3240, 1080}, {3744, 1224}, {4320, 1440},
+}
+
+func outlineLevelXML(level int) string {
+	var text strings.Builder
+	for i := 0; i <= level; i++ {
+		text.WriteString(fmt.Sprintf("%%%d.", i+1))
+	}
+	return fmt.Sprintf(`<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%s"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="%d" w:hanging="%d"/></w:pPr></w:lvl>`,
+		level, text.String(), outlineIndents[level][0], outlineIndents[level][1])
+}
+
+func letteredLevelXML(level int) string {
+	format := [3]string{"upperLetter", "lowerLetter", "lowerRoman"}[level%3]
+	jc := "left"
+	if format == "lowerRoman" {
+		jc = "right"
+	}
+	return fmt.Sprintf(`<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="%s"/><w:lvlText w:val="%%%d."/><w:lvlJc w:val="%s"/><w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr></w:lvl>`,
+		level, format, level+1, jc, 720*(level+1))
+}
+
+// registerList returns the numbering ID of the named built-in list, adding its nine-level
+// abstract definition and a num instance to the numbering part when they are missing.
+func (n *Numbering) registerList(name, multiLevelType string, levelXML func(level int) string) int {
+	n.ensureDefault()
+	data := n.part.Data
+	_, innerEnd, children, err := scanChildren(data)
+	if err != nil {
+		return 0
+	}
+
+	abstractID := -1
+	maxAbstractID, maxNumID := -1, 0
+	for _, child := range children {
+		if child.local != "abstractNum" {
+			continue
+		}
+		id, err := strconv.Atoi(attrValue(child.attrs, "abstractNumId"))
+		if err != nil {
+			continue
+		}
+		if id > maxAbstractID {
+			maxAbstractID = id
+		}
+		_, _, props, err := scanChildren(data[child.start:child.end])
+		if err != nil {
+			return 0
+		}
+		for _, prop := range props {
+			if prop.local == "name" && attrValue(prop.attrs, "val") == name && abstractID < 0 {
+				abstractID = id
+			}
+		}
+	}
+
+	numInsert, firstNum := innerEnd, -1
+	for _, child := range children {
+		switch child.local {
+		case "num":
+			if firstNum < 0 {
+				firstNum = child.start
+			}
+			id, err := strconv.Atoi(attrValue(child.attrs, "numId"))
+			if err != nil {
+				continue
+			}
+			if id > maxNumID {
+				maxNumID = id
+			}
+			if abstractID < 0 {
+				continue
+			}
+			_, _, props, err := scanChildren(data[child.start:child.end])
+			if err != nil {
+				return 0
+			}
+			for _, prop := range props {
+				if prop.local == "abstractNumId" && attrValue(prop.attrs, "val") == strconv.Itoa(abstractID) {
+					return id
+				}
+			}
+		case "numIdMacAtCleanup":
+			numInsert = child.start
+		}
+	}
+
+	numID := maxNumID + 1
+	if abstractID < 0 {
+		abstractID = maxAbstractID + 1
+		abstractInsert := numInsert
+		if firstNum >= 0 {
+			abstractInsert = firstNum
+		}
+		var abstract strings.Builder
+		abstract.WriteString(fmt.Sprintf(`<w:abstractNum w:abstractNumId="%d"><w:multiLevelType w:val="%s"/><w:name w:val="%s"/>`, abstractID, multiLevelType, name))
+		for level := 0; level < 9; level++ {
+			abstract.WriteString(levelXML(level))
+		}
+		abstract.WriteString(`</w:abstractNum>`)
+
+		data = splice(data, numInsert, numInsert, []byte(fmt.Sprintf(`<w:num w:numId="%d"><w:abstractNumId w:val="%d"/></w:num>`, numID, abstractID)))
+		data = splice(data, abstractInsert, abstractInsert, []byte(abstract.String()))
+	} else {
+		data = splice(data, numInsert, numInsert, []byte(fmt.Sprintf(`<w:num w:numId="%d"><w:abstractNumId w:val="%d"/></w:num>`, numID, abstractID)))
+	}
+	n.part.Data = data
+	return numID
+}
+
+// SetBulletCharacter changes the bullet glyph of a list level. The level's numFmt becomes
+// bullet, its lvlText is set to char and its run font to font; an empty font removes the
+// font override so the bullet uses the paragraph font. The change applies to the abstract
+// definition behind numID, so other lists sharing that definition change as well. A level
+// that is not defined yet is added.
+func (n *Numbering) SetBulletCharacter(numID int, level int, char string, font string) error {
+	if level < 0 || level > 8 {
+		return fmt.Errorf("%w: level must be in range 0-8, got %d", ErrIndexOutOfRange, level)
+	}
+	if char == "" {
+		return fmt.Errorf("%w: bullet character must not be empty", ErrInvalidArgument)
+	}
+	n.ensureDefault()
+	data := n.part.Data
+
+	abstract, err := findAbstractNum(data, numID)
+	if err != nil {
+		return err
+	}
+	abstractData := data[abstract.start:abstract.end]
+	innerStart, innerEnd, children, err := scanChildren(abstractData)
+	if err != nil {
+		return fmt.Errorf("failed to parse numbering part: %w", err)
+	}
+	if innerStart == len(abstractData) {
+		return fmt.Errorf("%w: numId %d has an empty abstract definition", ErrNumberingNotFound, numID)
+	}
+
+	var updated []byte
+	for _, child := range children {
+		if child.local != "lvl" || attrValue(child.attrs, "ilvl") != strconv.Itoa(level) {
+			continue
+		}
+		lvlXML, err := bulletLevelXML(abstractData[child.start:child.end], level, char, font)
+		if err != nil {
+			return fmt.Errorf("failed to parse numbering level: %w", err)
+		}
+		updated = splice(abstractData, child.start, child.end, lvlXML)
+		break
+	}
+	if updated == nil {
+		updated = splice(abstractData, innerEnd, innerEnd, []byte(newBulletLevelXML(level, char, font)))
+		// a single-level definition ignores every level but the first
+		for _, child := range children {
+			if child.local == "multiLevelType" && attrValue(child.attrs, "val") == "singleLevel" {
+				updated = splice(updated, child.start, child.end, []byte(`<w:multiLevelType w:val="hybridMultilevel"/>`))
+				break
+			}
+		}
+	}
+
+	n.part.Data = splice(data, abstract.start, abstract.end, updated)
+	return nil
+}
+
+// SetRestartAfterSection makes the list behind numID start over in every section after the
+// first. Word has no section-scoped list restart, so each later section's paragraphs using
+// numID are written to their own num instance, sharing the abstract definition and carrying a
+// w:lvlOverride/w:startOverride for every level. The instances are resolved every time the
+// document is serialized; paragraphs and numbering definitions in memory keep their IDs, so
+// passing false undoes the setting.
+func (n *Numbering) SetRestartAfterSection(numID int, restart bool) error {
+	n.ensureDefault()
+	if _, err := findAbstractNum(n.part.Data, numID); err != nil {
+		return err
+	}
+	if !restart {
+		delete(n.restartBySection, numID)
+		return nil
+	}
+	if n.restartBySection == nil {
+		n.restartBySection = make(map[int]bool)
+	}
+	n.restartBySection[numID] = true
+	return nil
+}
+
+// RestartsAfterSection reports whether the list behind numID restarts in every section
+func (n *Numbering) RestartsAfterSection(numID int) bool {
+	return n.restartBySection[numID]
+}
+
+// numIDValuePattern matches the val attribute of a w:numId element
+var numIDValuePattern = regexp.MustCompile(`(\bval\s*=\s*["'])[^"']*(["'])`)
+
+// sectionRestarts returns the main document and numbering XML to write in place of
+// documentXML and the numbering part, with the paragraphs of lists that restart per section
+// moved to the num instance of their section. Both are nil when nothing restarts.
+func (n *Numbering) sectionRestarts(documentXML []byte) (document, numbering []byte, err error) {
+	if len(n.restartBySection) == 0 || n.part == nil {
+		return nil, nil, nil
+	}
+
+	type numIDElement struct {
+		start, end     int
+		numID, section int
+	}
+	var elements []numIDElement
+	var stack []string
+	section, bodyDepth, sectionEnds := 0, -1, false
+	decoder := xml.NewDecoder(bytes.NewReader(documentXML))
+	for {
+		offset := int(decoder.InputOffset())
+		tok, tokErr := decoder.Token()
+		if tokErr == io.EOF {
+			break
+		}
+		if tokErr != nil {
+			return nil, nil, fmt.Errorf("failed to parse document part: %w", tokErr)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			depth := len(stack)
+			switch {
+			case t.Name.Local == "body" && bodyDepth < 0:
+				bodyDepth = depth
+			case t.Name.Local == "sectPr" && depth == bodyDepth+3 && stack[depth-2] == "pPr" && stack[depth-3] == "p":
+				sectionEnds = true
+			case t.Name.Local == "numId" && depth >= 4 && stack[depth-2] == "numPr" && stack[depth-3] == "pPr" && stack[depth-4] == "p":
+				numID, convErr := strconv.Atoi(attrValue(t.Attr, "val"))
+				if convErr == nil && section > 0 && n.restartBySection[numID] {
+					elements = append(elements, numIDElement{start: offset, end: -1, numID: numID, section: section})
+				}
+			}
+		case xml.EndElement:
+			depth := len(stack)
+			if t.Name.Local == "numId" && len(elements) > 0 && elements[len(elements)-1].end < 0 {
+				elements[len(elements)-1].end = int(decoder.InputOffset())
+			}
+			if t.Name.Local == "p" && depth == bodyDepth+1 && sectionEnds {
+				section++
+				sectionEnds = false
+			}
+			stack = stack[:depth-1]
+		}
+	}
+	if len(elements) == 0 {
+		return nil, nil, nil
+	}
+
+	numbering = append([]byte(nil), n.part.Data...)
+	instances := make(map[[2]int]int)
+	var result bytes.Buffer
+	last := 0
+	for _, element := range elements {
+		key := [2]int{element.numID, element.section}
+		instance, ok := instances[key]
+		if !ok {
+			if numbering, instance, err = addRestartInstance(numbering, element.numID); err != nil {
+				return nil, nil, err
+			}
+			instances[key] = instance
+		}
+		result.Write(documentXML[last:element.start])
+		result.Write(numIDValuePattern.ReplaceAll(documentXML[element.start:element.end], []byte("${1}"+strconv.Itoa(instance)+"${2}")))
+		last = element.end
+	}
+	result.Write(documentXML[last:])
+	return result.Bytes(), numbering, nil
+}
+
+// addRestartInstance adds a num instance to the numbering XML in data that shares the
+// abstract definition of numID and restarts every level, returning the updated XML and the
+// new instance ID
+func addRestartInstance(data []byte, numID int) ([]byte, int, error) {
+	abstract, err := findAbstractNum(data, numID)
+	if err != nil {
+		return nil, 0, err
+	}
+	_, innerEnd, children, err := scanChildren(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse numbering part: %w", err)
+	}
+	maxNumID, insert := 0, innerEnd
+	for _, child := range children {
+		switch child.local {
+		case "num":
+			if id, err := strconv.Atoi(attrValue(child.attrs, "numId")); err == nil && id > maxNumID {
+				maxNumID = id
+			}
+		case "numIdMacAtCleanup":
+			insert = child.start
+		}
+	}
+
+	starts, err := levelStarts(data[abstract.start:abstract.end])
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse numbering part: %w", err)
+	}
+	instance := maxNumID + 1
+	var num strings.Builder
+	num.WriteString(fmt.Sprintf(`<w:num w:numId="%d"><w:abstractNumId w:val="%s"/>`, instance, attrValue(abstract.attrs, "abstractNumId")))
+	for level := 0; level < 9; level++ {
+		num.WriteString(fmt.Sprintf(`<w:lvlOverride w:ilvl="%d"><w:startOverride w:val="%d"/></w:lvlOverride>`, level, starts[level]))
+	}
+	num.WriteString(`</w:num>`)
+	return splice(data, insert, insert, []byte(num.String())), instance, nil
+}
+
+// levelStarts returns the start value of each level of an abstract definition, defaulting to 1
+func levelStarts(abstract []byte) ([9]int, error) {
+	starts := [9]int{1, 1, 1, 1, 1, 1, 1, 1, 1}
+	_, _, children, err := scanChildren(abstract)
+	if err != nil {
+		return starts, err
+	}
+	for _, child := range children {
+		level, err := strconv.Atoi(attrValue(child.attrs, "ilvl"))
+		if child.local != "lvl" || err != nil || level < 0 || level > 8 {
+			continue
+		}
+		_, _, props, err := scanChildren(abstract[child.start:child.end])
+		if err != nil {
+			return starts, err
+		}
+		for _, prop := range props {
+			if prop.local != "start" {
+				continue
+			}
+			if start, err := strconv.Atoi(attrValue(prop.attrs, "val")); err == nil {
+				starts[level] = start
+			}
+		}
+	}
+	return starts, nil
+}
+
+// findAbstractNum locates the w:abstractNum element referenced by the w:num with the given ID
+func findAbstractNum(data []byte, numID int) (xmlChild, error) {
+	_, _, children, err := scanChildren(data)
+	if err != nil {
+		return xmlChild{}, fmt.Errorf("failed to parse numbering part: %w", err)
+	}
+
+	abstractID := ""
+	for _, child := range children {
+		if child.local != "num" || attrValue(child.attrs, "numId") != strconv.Itoa(numID) {
+			continue
+		}
+		_, _, props, err := scanChildren(data[child.start:child.end])
+		if err != nil {
+			return xmlChild{}, fmt.Errorf("failed to parse numbering part: %w", err)
+		}
+		for _, prop := range props {
+			if prop.local == "abstractNumId" {
+				abstractID = attrValue(prop.attrs, "val")
+			}
+		}
+		break
+	}
+	if abstractID == "" {
+		return xmlChild{}, fmt.Errorf("%w: numId %d", ErrNumberingNotFound, numID)
+	}
+
+	for _, child := range children {
+		if child.local == "abstractNum" && attrValue(child.attrs, "abstractNumId") == abstractID {
+			return child, nil
+		}
+	}
+	return xmlChild{}, fmt.Errorf("%w: abstractNumId %s", ErrNumberingNotFound, abstractID)
+}
+
+// bulletLevelXML rewrites a w:lvl element as a bullet level, keeping its other settings
+func bulletLevelXML(lvl []byte, level int, char, font string) ([]byte, error) {
+	innerStart, innerEnd, children, err := scanChildren(lvl)
+	if err != nil {
+		return nil, err
+	}
+	if innerStart == len(lvl) {
+		return []byte(newBulletLevelXML(level, char, font)), nil
+	}
+
+	numFmtXML := `<w:numFmt w:val="bullet"/>`
+	lvlTextXML := fmt.Sprintf(`<w:lvlText w:val="%s"/>`, xmlEscapeAttribute(char))
+	hasNumFmt, hasStart := false, false
+	for _, child := range children {
+		switch child.local {
+		case "numFmt":
+			hasNumFmt = true
+		case "start":
+			hasStart = true
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(lvl[:innerStart])
+	if !hasNumFmt && !hasStart {
+		buf.WriteString(numFmtXML)
+	}
+	textPending := true
+	fontPending := font != ""
+	for _, child := range children {
+		raw := lvl[child.start:child.end]
+		switch child.local {
+		case "start":
+			buf.Write(raw)
+			if !hasNumFmt {
+				buf.WriteString(numFmtXML)
+			}
+		case "numFmt":
+			buf.WriteString(numFmtXML)
+		case "lvlText":
+			buf.WriteString(lvlTextXML)
+			textPending = false
+		case "lvlPicBulletId":
+			// a picture bullet would take precedence over the character
+		case "legacy", "lvlJc", "pPr", "rPr":
+			if textPending {
+				buf.WriteString(lvlTextXML)
+				textPending = false
+			}
+			if child.local != "rPr" {
+				buf.Write(raw)
+				continue
+			}
+			rPr, err := bulletRunPropertiesXML(raw, font)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(rPr)
+			fontPending = false
+		default:
+			buf.Write(raw)
+		}
+	}
+	if textPending {
+		buf.WriteString(lvlTextXML)
+	}
+	if fontPending {
+		buf.WriteString(`<w:rPr>` + bulletFontXML(font) + `</w:rPr>`)
+	}
+	buf.Write(lvl[innerEnd:])
+	return buf.Bytes(), nil
+}
+
+// bulletRunPropertiesXML replaces the w:rFonts of a level's w:rPr, dropping it when font is empty
+func bulletRunPropertiesXML(rPr []byte, font string) ([]byte, error) {
+	innerStart, innerEnd, children, err := scanChildren(rPr)
+	if err != nil {
+		return nil, err
+	}
+	if innerStart == len(rPr) {
+		if font == "" {
+			return nil, nil
+		}
+		return []byte(`<w:rPr>` + bulletFontXML(font) + `</w:rPr>`), nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(rPr[:innerStart])
+	fontPending := font != ""
+	for _, child := range children {
+		if child.local == "rFonts" {
+			continue
+		}
+		if fontPending && child.local != "rStyle" {
+			buf.WriteString(bulletFontXML(font))
+			fontPending = false
+		}
+		buf.Write(rPr[child.start:child.end])
+	}
+	if fontPending {
+		buf.WriteString(bulletFontXML(font))
+	}
+	buf.Write(rPr[innerEnd:])
+	return buf.Bytes(), nil
+}
+
+func newBulletLevelXML(level int, char, font string) string {
+	rPr := ""
+	if font != "" {
+		rPr = `<w:rPr>` + bulletFontXML(font) + `</w:rPr>`
+	}
+	return fmt.Sprintf(`<w:lvl w:ilvl="%d"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="%s"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="%d" w:hanging="360"/></w:pPr>%s</w:lvl>`,
+		level, xmlEscapeAttribute(char), 720*(level+1), rPr)
+}
+
+func bulletFontXML(font string) string {
+	font = xmlEscapeAttribute(font)
+	return fmt.Sprintf(`<w:rFonts w:ascii="%s" w:hAnsi="%s" w:hint="default"/>`, font, font)
+}
+
+// xmlChild is a direct child element located by its byte offsets within raw XML
+type xmlChild struct {
+	local      string
+	attrs      []xml.Attr
+	start, end int
+}
+
+// scanChildren locates the direct children of the first element in data. innerStart and
+// innerEnd delimit the element's content; both equal len(data) when it is self-closing.
+func scanChildren(data []byte) (innerStart, innerEnd int, children []xmlChild, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		offset := int(decoder.InputOffset())
+		tok, tokErr := decoder.Token()
+		if tokErr == io.EOF {
+			return 0, 0, nil, io.ErrUnexpectedEOF
+		}
+		if tokErr != nil {
+			return 0, 0, nil, tokErr
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				innerStart = int(decoder.InputOffset())
+			case 2:
+				children = append(children, xmlChild{local: t.Name.Local, attrs: t.Attr, start: offset})
+			}
+		case xml.EndElement:
+			switch depth {
+			case 1:
+				return innerStart, offset, children, nil
+			case 2:
+				children[len(children)-1].end = int(decoder.InputOffset())
+			}
+			depth--
+		}
+	}
+}
+
+// splice returns a copy of data with data[start:end] replaced by replacement
+func splice(data []byte, start, end int, replacement []byte) []byte {
+	result := make([]byte, 0, len(data)-(end-start)+len(replacement))
+	result = append(result, data[:start]...)
+	result = append(result, replacement...)
+	return append(result, data[end:]...)
+}
@@ -2,14 +2,17 @@ package docx
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Package represents an OpenXML package (DOCX file)
@@ -19,11 +22,14 @@ type Package struct {
 	relations           map[string][]*Relationship
 	filePath            string
 	coreProps           *CoreProperties
+	customProps         *CustomProperties
 	contentTypes        map[string]string
 	defaultContentTypes map[string]string
 	mediaCounter        int
 	headerCounter       int
 	footerCounter       int
+	hasContentTypes     bool
+	indentOutput        bool
 }
 
 // Part represents a part within the OpenXML package
@@ -44,50 +50,109 @@ type Relationship struct {
 
 // NewPackage creates a new empty package
 func NewPackage() *Package {
-	pkg := &Package{
-		parts:               make(map[string]*Part),
-		relations:           make(map[string][]*Relationship),
-		coreProps:           NewCoreProperties(),
-		contentTypes:        make(map[string]string),
-		defaultContentTypes: make(map[string]string),
-		mediaCounter:        0,
-		headerCounter:       0,
-		footerCounter:       0,
-	}
+	pkg := newEmptyPackage()
 
 	// Add default parts
 	pkg.addDefaultParts()
 	return pkg
 }
 
+// cfbSignature is the magic header of OLE compound files, used by Office to wrap encrypted OOXML packages.
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
 // OpenPackage opens an existing package from file
 func OpenPackage(filePath string) (*Package, error) {
 	zipReader, err := zip.OpenReader(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open zip file: %w", err)
+		if isCompoundFile(filePath) {
+			return nil, ErrEncryptedDocument
+		}
+		if _, statErr := os.Stat(filePath); statErr != nil {
+			return nil, fmt.Errorf("failed to open zip file: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNotADocx, err)
+	}
+
+	pkg := newEmptyPackage()
+	pkg.zipReader = zipReader
+	pkg.filePath = filePath
+	if err := pkg.load(&zipReader.Reader); err != nil {
+		zipReader.Close()
+		return nil, err
 	}
+	return pkg, nil
+}
 
-	pkg := &Package{
-		zipReader:           zipReader,
+// OpenPackageBytes opens a package held in memory, e.g. one fetched from object storage or
+// a database. The package has no file path, so it is written with SaveAs.
+func OpenPackageBytes(data []byte) (*Package, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		if bytes.HasPrefix(data, cfbSignature) {
+			return nil, ErrEncryptedDocument
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNotADocx, err)
+	}
+
+	pkg := newEmptyPackage()
+	if err := pkg.load(archive); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+func newEmptyPackage() *Package {
+	return &Package{
 		parts:               make(map[string]*Part),
 		relations:           make(map[string][]*Relationship),
-		filePath:            filePath,
 		coreProps:           NewCoreProperties(),
+		customProps:         NewCustomProperties(),
 		contentTypes:        make(map[string]string),
 		defaultContentTypes: make(map[string]string),
 		mediaCounter:        0,
 		headerCounter:       0,
 		footerCounter:       0,
 	}
+}
 
-	// Load all parts from the zip file
-	err = pkg.loadParts()
+// load reads every part of the archive and checks that it is a Word package
+func (p *Package) load(archive *zip.Reader) error {
+	if err := p.loadParts(archive); err != nil {
+		return fmt.Errorf("failed to load parts: %w", err)
+	}
+	if !p.hasContentTypes {
+		return fmt.Errorf("%w: missing [Content_Types].xml", ErrNotADocx)
+	}
+	if !p.hasMainDocumentPart() {
+		return fmt.Errorf("%w: missing main document part", ErrNotADocx)
+	}
+	p.loadCoreProperties()
+	p.loadCustomProperties()
+	return nil
+}
+
+func isCompoundFile(filePath string) bool {
+	file, err := os.Open(filePath)
 	if err != nil {
-		zipReader.Close()
-		return nil, fmt.Errorf("failed to load parts: %w", err)
+		return false
 	}
+	defer file.Close()
+	header := make([]byte, len(cfbSignature))
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, cfbSignature)
+}
 
-	return pkg, nil
+func (p *Package) hasMainDocumentPart() bool {
+	for _, rel := range p.relations[""] {
+		if rel.Type == RelTypeOfficeDocument {
+			if _, exists := p.parts[resolveRelationshipTarget("", rel.Target)]; exists {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // MainDocumentPart returns the main document part
@@ -96,7 +161,7 @@ func (p *Package) MainDocumentPart() *DocumentPart {
 	rels := p.relations[""]
 	for _, rel := range rels {
 		if rel.Type == RelTypeOfficeDocument {
-			if part, exists := p.parts[rel.Target]; exists {
+			if part, exists := p.parts[resolveRelationshipTarget("", rel.Target)]; exists {
 				docPart := &DocumentPart{
 					Part: part,
 					pkg:  p,
@@ -132,15 +197,168 @@ func (p *Package) CoreProperties() *CoreProperties {
 	return p.coreProps
 }
 
+func (p *Package) corePropertiesURI() string {
+	for _, rel := range p.relations[""] {
+		if rel.Type == RelTypeCoreProps {
+			return resolveRelationshipTarget("", rel.Target)
+		}
+	}
+	return "docProps/core.xml"
+}
+
+// loadCoreProperties replaces the default core properties with the ones stored in the package.
+// A missing or malformed part keeps the defaults so the document can still be opened.
+func (p *Package) loadCoreProperties() {
+	part, ok := p.parts[p.corePropertiesURI()]
+	if !ok || len(part.Data) == 0 {
+		return
+	}
+	if props, err := parseCoreProperties(part.Data); err == nil {
+		p.coreProps = props
+	}
+}
+
+// updateCorePropertiesPart serializes the core properties into their part, registering the
+// relationship and content type override if needed.
+func (p *Package) updateCorePropertiesPart() error {
+	if p.coreProps == nil {
+		return nil
+	}
+	data, err := p.coreProps.ToXML()
+	if err != nil {
+		return err
+	}
+	uri := p.corePropertiesURI()
+	part, ok := p.parts[uri]
+	if !ok {
+		part = &Part{URI: uri, ContentType: ContentTypeOPCCoreProps}
+		p.parts[uri] = part
+	}
+	part.Data = data
+	p.contentTypes["/"+uri] = ContentTypeOPCCoreProps
+	p.ensureRelationship("", RelTypeCoreProps, uri)
+	return nil
+}
+
+// SetIndentOutput controls how XML parts are written on save. When enabled every XML part,
+// including relationships and content types, is pretty-printed with consistent indentation;
+// otherwise (the default) generated XML is written compactly.
+func (p *Package) SetIndentOutput(indent bool) {
+	p.indentOutput = indent
+}
+
+// IndentOutput reports whether XML parts are pretty-printed on save
+func (p *Package) IndentOutput() bool {
+	return p.indentOutput
+}
+
+// marshalXML serializes package-level XML according to the indent setting
+func (p *Package) marshalXML(v interface{}) ([]byte, error) {
+	if p.indentOutput {
+		return xml.MarshalIndent(v, "", xmlIndent)
+	}
+	return xml.Marshal(v)
+}
+
+// CustomProperties returns the custom (user-defined) document properties
+func (p *Package) CustomProperties() *CustomProperties {
+	return p.customProps
+}
+
+func (p *Package) customPropertiesURI() string {
+	for _, rel := range p.relations[""] {
+		if rel.Type == RelTypeCustomProps {
+			return resolveRelationshipTarget("", rel.Target)
+		}
+	}
+	return "docProps/custom.xml"
+}
+
+// loadCustomProperties reads the custom properties stored in the package. A missing or
+// malformed part leaves the collection empty.
+func (p *Package) loadCustomProperties() {
+	part, ok := p.parts[p.customPropertiesURI()]
+	if !ok || len(part.Data) == 0 {
+		return
+	}
+	if props, err := parseCustomProperties(part.Data); err == nil {
+		p.customProps = props
+	}
+}
+
+// updateCustomPropertiesPart serializes the custom properties into their part. The part is
+// only created once a property has been set.
+func (p *Package) updateCustomPropertiesPart() {
+	if p.customProps == nil {
+		return
+	}
+	uri := p.customPropertiesURI()
+	part, ok := p.parts[uri]
+	if !ok {
+		if len(p.customProps.properties) == 0 {
+			return
+		}
+		part = &Part{URI: uri, ContentType: ContentTypeCustomProps}
+		p.parts[uri] = part
+	}
+	part.Data = p.customProps.ToXML()
+	p.contentTypes["/"+uri] = ContentTypeCustomProps
+	p.ensureRelationship("", RelTypeCustomProps, uri)
+}
+
 // SaveAs saves the package to a new file
 func (p *Package) SaveAs(filePath string) error {
+	return p.saveAs(filePath, nil)
+}
+
+// saveAs saves the package to filePath, writing overrides in place of the stored data of
+// the parts they name
+func (p *Package) saveAs(filePath string, overrides map[string][]byte) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 
-	zipWriter := zip.NewWriter(file)
+	if err := p.write(file, true, overrides); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	p.filePath = filePath
+	return nil
+}
+
+// Bytes serializes the package to an in-memory zip archive. Unlike a save it does not
+// stamp the core properties, so Modified and Revision are written as they currently are.
+func (p *Package) Bytes() ([]byte, error) {
+	return p.bytes(nil)
+}
+
+// bytes serializes the package in memory, writing overrides in place of the stored data of
+// the parts they name
+func (p *Package) bytes(overrides map[string][]byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := p.write(&buffer, false, overrides); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// write serializes the package as a zip archive to out, stamping the core properties
+// first when it is a save. Parts named in overrides are written with that data instead of
+// their own.
+func (p *Package) write(out io.Writer, save bool, overrides map[string][]byte) error {
+	if save && p.coreProps != nil {
+		p.coreProps.touch(time.Now())
+	}
+	if err := p.updateCorePropertiesPart(); err != nil {
+		return fmt.Errorf("failed to serialize core properties: %w", err)
+	}
+	p.updateCustomPropertiesPart()
+
+	zipWriter := zip.NewWriter(out)
 	defer zipWriter.Close()
 
 	// Write all parts to the zip file
@@ -150,7 +368,16 @@ func (p *Package) SaveAs(filePath string) error {
 			return fmt.Errorf("failed to create zip entry %s: %w", uri, err)
 		}
 
-		_, err = w.Write(part.Data)
+		data := part.Data
+		if override, ok := overrides[uri]; ok {
+			data = override
+		}
+		if p.indentOutput && isXMLContentType(part.ContentType) {
+			if formatted, err := formatXML(data, true); err == nil {
+				data = formatted
+			}
+		}
+		_, err = w.Write(data)
 		if err != nil {
 			return fmt.Errorf("failed to write part data %s: %w", uri, err)
 		}
@@ -176,12 +403,13 @@ func (p *Package) SaveAs(filePath string) error {
 	}
 
 	// Write content types
-	err = p.writeContentTypes(zipWriter)
-	if err != nil {
+	if err := p.writeContentTypes(zipWriter); err != nil {
 		return fmt.Errorf("failed to write content types: %w", err)
 	}
 
-	p.filePath = filePath
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip archive: %w", err)
+	}
 	return nil
 }
 
@@ -264,10 +492,15 @@ func (p *Package) lookupContentType(partName string) string {
 
 // Save saves the package to its original location
 func (p *Package) Save() error {
+	return p.save(nil)
+}
+
+// save saves the package to its original location with the given part overrides
+func (p *Package) save(overrides map[string][]byte) error {
 	if p.filePath == "" {
-		return fmt.Errorf("no file path set, use SaveAs instead")
+		return fmt.Errorf("%w: no file path set, use SaveAs instead", ErrInvalidArgument)
 	}
-	return p.SaveAs(p.filePath)
+	return p.saveAs(p.filePath, overrides)
 }
 
 // Close closes the package and releases resources
@@ -318,10 +551,10 @@ func (p *Package) addDefaultParts() {
 	p.ensureRelationship("word/document.xml", RelTypeNumbering, "numbering.xml")
 }
 
-// loadParts loads all parts from the zip file
-func (p *Package) loadParts() error {
+// loadParts loads all parts from the zip archive
+func (p *Package) loadParts(archive *zip.Reader) error {
 	// First, parse content types so they are available for subsequent parts
-	for _, file := range p.zipReader.File {
+	for _, file := range archive.File {
 		if file.Name != "[Content_Types].xml" {
 			continue
 		}
@@ -340,11 +573,12 @@ func (p *Package) loadParts() error {
 		if err := p.parseContentTypes(data); err != nil {
 			return fmt.Errorf("failed to parse content types: %w", err)
 		}
+		p.hasContentTypes = true
 
 		break
 	}
 
-	for _, file := range p.zipReader.File {
+	for _, file := range archive.File {
 		// Skip directories
 		if strings.HasSuffix(file.Name, "/") {
 			continue
@@ -439,7 +673,7 @@ func (p *Package) serializeRelationships(rels []*Relationship) ([]byte, error) {
 		Relationships: rels,
 	}
 
-	return xml.MarshalIndent(relationships, "", "  ")
+	return p.marshalXML(relationships)
 }
 
 // writeContentTypes writes the [Content_Types].xml file
@@ -484,6 +718,20 @@ func (p *Package) writeContentTypes(zipWriter *zip.Writer) error {
 		})
 	}
 
+	// Every part whose content type is not implied by its extension needs an override,
+	// otherwise consumers such as Word cannot resolve it (e.g. headers and footers).
+	for uri, part := range p.parts {
+		partName := "/" + uri
+		if _, ok := p.contentTypes[partName]; ok || part.ContentType == "" {
+			continue
+		}
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(uri), "."))
+		if p.defaultContentTypes[ext] == part.ContentType {
+			continue
+		}
+		p.contentTypes[partName] = part.ContentType
+	}
+
 	overrideKeys := make([]string, 0, len(p.contentTypes))
 	for partName := range p.contentTypes {
 		overrideKeys = append(overrideKeys, partName)
@@ -501,7 +749,7 @@ func (p *Package) writeContentTypes(zipWriter *zip.Writer) error {
 		return err
 	}
 
-	data, err := xml.MarshalIndent(types, "", "  ")
+	data, err := p.marshalXML(types)
 	if err != nil {
 		return err
 	}
@@ -580,6 +828,114 @@ func (p *Package) addImagePart(data []byte, ext, contentType string) (string, er
 	return uri, nil
 }
 
+// RemovePart deletes the part with the given package URI, its content type override and its
+// own relationships, and removes every relationship elsewhere in the package that targets it.
+// Markup that still refers to those relationship IDs is not rewritten.
+func (p *Package) RemovePart(uri string) error {
+	uri = strings.TrimPrefix(uri, "/")
+	if _, ok := p.parts[uri]; !ok {
+		return fmt.Errorf("%w: %s", ErrPartNotFound, uri)
+	}
+	delete(p.parts, uri)
+	delete(p.contentTypes, "/"+uri)
+	delete(p.relations, uri)
+	for baseURI, rels := range p.relations {
+		kept := rels[:0]
+		for _, rel := range rels {
+			if rel.TargetMode != "External" && resolveRelationshipTarget(baseURI, rel.Target) == uri {
+				continue
+			}
+			kept = append(kept, rel)
+		}
+		p.relations[baseURI] = kept
+	}
+	return nil
+}
+
+// pruneUnusedHyperlinks drops hyperlink relationships whose ID is no longer referenced by
+// the markup of their source part
+func (p *Package) pruneUnusedHyperlinks() {
+	for baseURI, rels := range p.relations {
+		source, ok := p.parts[baseURI]
+		if !ok {
+			continue
+		}
+		kept := rels[:0]
+		for _, rel := range rels {
+			if rel.Type == RelTypeHyperlink && !referencesRelationship(source.Data, rel.ID) {
+				continue
+			}
+			kept = append(kept, rel)
+		}
+		p.relations[baseURI] = kept
+	}
+}
+
+// pruneUnusedImages drops image relationships whose ID is no longer referenced by the markup
+// of their source part, then removes the image parts nothing targets any more. It returns
+// the number of image parts removed.
+func (p *Package) pruneUnusedImages() int {
+	for baseURI, rels := range p.relations {
+		source, ok := p.parts[baseURI]
+		if !ok {
+			continue
+		}
+		kept := rels[:0]
+		for _, rel := range rels {
+			if rel.Type == RelTypeImage && !referencesRelationship(source.Data, rel.ID) {
+				continue
+			}
+			kept = append(kept, rel)
+		}
+		p.relations[baseURI] = kept
+	}
+
+	targeted := make(map[string]bool)
+	for baseURI, rels := range p.relations {
+		for _, rel := range rels {
+			if rel.TargetMode != "External" {
+				targeted[resolveRelationshipTarget(baseURI, rel.Target)] = true
+			}
+		}
+	}
+	var unused []string
+	for uri, part := range p.parts {
+		if strings.HasPrefix(part.ContentType, "image/") && !targeted[uri] {
+			unused = append(unused, uri)
+		}
+	}
+	for _, uri := range unused {
+		_ = p.RemovePart(uri)
+	}
+	return len(unused)
+}
+
+// proofErrPattern matches a w:proofErr marker under any namespace prefix
+var proofErrPattern = regexp.MustCompile(`<(\w+:)?proofErr\b[^>]*?(/>|>\s*</(\w+:)?proofErr>)`)
+
+// removeProofingMarks strips proofErr markers from every WordprocessingML part and
+// returns the number removed
+func (p *Package) removeProofingMarks() int {
+	removed := 0
+	for _, part := range p.parts {
+		if !strings.HasPrefix(part.ContentType, "application/vnd.openxmlformats-officedocument.wordprocessingml.") {
+			continue
+		}
+		matches := proofErrPattern.FindAllIndex(part.Data, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		removed += len(matches)
+		part.Data = proofErrPattern.ReplaceAll(part.Data, nil)
+	}
+	return removed
+}
+
+// referencesRelationship reports whether data contains id as a quoted attribute value
+func referencesRelationship(data []byte, id string) bool {
+	return bytes.Contains(data, []byte(`"`+id+`"`)) || bytes.Contains(data, []byte(`'`+id+`'`))
+}
+
 func (p *Package) newHeaderPart() *Part {
 	p.headerCounter++
 	name := fmt.Sprintf("word/header%d.xml", p.headerCounter)
@@ -587,7 +943,7 @@ func (p *Package) newHeaderPart() *Part {
 		URI:         name,
 		ContentType: ContentTypeWMLHeader,
 		Data: []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"/>`),
+<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:w10="urn:schemas-microsoft-com:office:word"/>`),
 	}
 	p.parts[name] = part
 	p.contentTypes["/"+name] = ContentTypeWMLHeader
@@ -604,7 +960,7 @@ func (p *Package) newFooterPart() *Part {
 		URI:         name,
 		ContentType: ContentTypeWMLFooter,
 		Data: []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"/>`),
+<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:w10="urn:schemas-microsoft-com:office:word"/>`),
 	}
 	p.parts[name] = part
 	p.contentTypes["/"+name] = ContentTypeWMLFooter
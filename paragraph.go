@@ -2,6 +2,8 @@ package docx
 
 import (
 	"fmt"
+	"math"
+	"regexp"
 	"strings"
 )
 
@@ -11,6 +13,7 @@ type Paragraph struct {
 	runs             []*Run
 	style            string
 	alignment        WDAlignParagraph
+	alignmentSet     bool // explicit alignment, kept even when left so it overrides the style
 	numberingApplied bool
 	numberingID      int
 	numberingLevel   int
@@ -23,10 +26,14 @@ type Paragraph struct {
 	indentRightSet     bool
 	indentFirstLineSet bool
 	indentHangingSet   bool
-	spacingBefore      int
-	spacingAfter       int
-	spacingLine        int
-	spacingLineRule    string
+	// character-unit indentation (w:leftChars etc.) in hundredths of a character
+	indentLeftChars      *int
+	indentFirstLineChars *int
+	indentHangingChars   *int
+	spacingBefore        int
+	spacingAfter         int
+	spacingLine          int
+	spacingLineRule      string
 	// Track whether spacing attributes were explicitly set in the source (including zero)
 	spacingBeforeSet   bool
 	spacingAfterSet    bool
@@ -37,12 +44,20 @@ type Paragraph struct {
 	keepLines          *bool
 	pageBreakBefore    *bool
 	widowControl       *bool
+	rightToLeft        *bool
+	snapToGrid         *bool
+	autoSpaceLatin     *bool // w:autoSpaceDE
+	autoSpaceNumbers   *bool // w:autoSpaceDN
 	borders            map[ParagraphBorderSide]*ParagraphBorder
 	bordersDefined     bool
 	shading            *ParagraphShading
 	markRunProperties  []string
 	// section holds a paragraph-level section break (sectPr) if present.
 	section *Section
+	// paraID and textID are Word's w14:paraId/w14:textId stamps, kept so comments and
+	// co-authoring stay anchored across a round trip
+	paraID string
+	textID string
 }
 
 // TabStop represents a paragraph tab stop configuration
@@ -73,11 +88,23 @@ type ParagraphBorder struct {
 	Shadow bool   // Whether shadow effect is applied
 }
 
+// RunBorder describes the character border drawn around a run (w:bdr).
+type RunBorder struct {
+	Style string // WordprocessingML value, e.g. "single", "dashed"
+	Color string // Hex color or "auto"
+	Size  int    // Border width in eighths of a point
+	Space int    // Space between border and text in points
+}
+
 // ParagraphShading describes the shading applied to a paragraph.
 type ParagraphShading struct {
 	Pattern string // Shading pattern, e.g. "clear", "solid"
 	Fill    string // Fill color (background)
 	Color   string // Pattern color (foreground)
+	// ThemeFill and ThemeColor name theme colors (e.g. "accent1") used instead of Fill and
+	// Color when the document theme is applied
+	ThemeFill  string
+	ThemeColor string
 }
 
 // NewParagraph creates a new paragraph
@@ -99,13 +126,90 @@ func (p *Paragraph) AddRun(text string) *Run {
 	return run
 }
 
+// InsertRun creates a new run with the specified text at the given run index and returns it.
+// An out-of-range index is clamped, so len(Runs()) or more appends like AddRun.
+func (p *Paragraph) InsertRun(index int, text string) *Run {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(p.runs) {
+		index = len(p.runs)
+	}
+	run := NewRun(text)
+	run.owner = p.owner
+	p.runs = append(p.runs, nil)
+	copy(p.runs[index+1:], p.runs[index:])
+	p.runs[index] = run
+	return run
+}
+
+// AppendRuns moves the given runs to the end of the paragraph, re-owning them to the
+// paragraph's document part. The runs are not copied: a run still listed in another
+// paragraph must be removed from it by the caller.
+func (p *Paragraph) AppendRuns(runs ...*Run) {
+	for _, run := range runs {
+		if run == nil {
+			continue
+		}
+		run.owner = p.owner
+		p.runs = append(p.runs, run)
+	}
+}
+
 // AddPicture creates a new run containing an inline picture
 func (p *Paragraph) AddPicture(path string, widthEMU, heightEMU int64) (*Run, *Picture, error) {
+	return p.AddPictureWithOptions(path, widthEMU, heightEMU, PictureOptions{})
+}
+
+// AddPictureWithOptions creates a new run containing an inline picture sized as for
+// Run.AddPictureWithOptions
+func (p *Paragraph) AddPictureWithOptions(path string, widthEMU, heightEMU int64, opts PictureOptions) (*Run, *Picture, error) {
 	if p.owner == nil {
-		return nil, nil, fmt.Errorf("paragraph is not attached to a document")
+		return nil, nil, fmt.Errorf("%w: paragraph is not attached to a document", ErrNotAttached)
 	}
 	run := p.AddRun("")
-	picture, err := run.AddPicture(path, widthEMU, heightEMU)
+	picture, err := run.AddPictureWithOptions(path, widthEMU, heightEMU, opts)
+	if err != nil {
+		p.runs = p.runs[:len(p.runs)-1]
+		return nil, nil, err
+	}
+	return run, picture, nil
+}
+
+// AddPictureFromBytes creates a new run containing an inline picture from image data held
+// in memory, as for Run.AddPictureFromBytes
+func (p *Paragraph) AddPictureFromBytes(data []byte, name string, widthEMU, heightEMU int64, opts PictureOptions) (*Run, *Picture, error) {
+	if p.owner == nil {
+		return nil, nil, fmt.Errorf("%w: paragraph is not attached to a document", ErrNotAttached)
+	}
+	run := p.AddRun("")
+	picture, err := run.AddPictureFromBytes(data, name, widthEMU, heightEMU, opts)
+	if err != nil {
+		p.runs = p.runs[:len(p.runs)-1]
+		return nil, nil, err
+	}
+	return run, picture, nil
+}
+
+// AddCenteredPicture adds a picture like AddPicture and centers the paragraph so the inline
+// image sits in the middle of the line.
+func (p *Paragraph) AddCenteredPicture(path string, widthEMU, heightEMU int64) (*Run, *Picture, error) {
+	run, picture, err := p.AddPicture(path, widthEMU, heightEMU)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.SetAlignment(WDAlignParagraphCenter)
+	return run, picture, nil
+}
+
+// AddLinkedPicture creates a new run containing a picture that references an external image
+// by URL or path instead of embedding it. Width and height are required and specified in EMUs.
+func (p *Paragraph) AddLinkedPicture(url string, widthEMU, heightEMU int64) (*Run, *Picture, error) {
+	if p.owner == nil {
+		return nil, nil, fmt.Errorf("%w: paragraph is not attached to a document", ErrNotAttached)
+	}
+	run := p.AddRun("")
+	picture, err := run.AddLinkedPicture(url, widthEMU, heightEMU)
 	if err != nil {
 		p.runs = p.runs[:len(p.runs)-1]
 		return nil, nil, err
@@ -120,8 +224,104 @@ func (p *Paragraph) AddHyperlink(text, url string) *Run {
 	return run
 }
 
-// SetSpacing configures paragraph spacing (values in twentieths of a point)
+// autoLinkPattern matches http(s) URLs and e-mail addresses for AddTextWithAutoLinks
+var autoLinkPattern = regexp.MustCompile(`https?://[^\s<>"]+|[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+
+// AddTextWithAutoLinks adds text to the paragraph, turning http(s) URLs and e-mail
+// addresses into hyperlinks (e-mail addresses link to mailto:) formatted like Word's
+// Hyperlink style. Trailing punctuation such as a sentence-ending period is kept out of
+// a link. It returns the runs added, in order.
+func (p *Paragraph) AddTextWithAutoLinks(text string) []*Run {
+	var runs []*Run
+	position := 0
+	for _, match := range autoLinkPattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], trimLinkPunctuation(text, match[0], match[1])
+		if start < position || end <= start {
+			continue
+		}
+		if start > position {
+			runs = append(runs, p.AddRun(text[position:start]))
+		}
+		link := text[start:end]
+		target := link
+		if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+			target = "mailto:" + link
+		}
+		run := p.AddHyperlink(link, target)
+		run.SetColor(hyperlinkColor)
+		run.SetUnderline(WDUnderlineSingle)
+		runs = append(runs, run)
+		position = end
+	}
+	if position < len(text) {
+		runs = append(runs, p.AddRun(text[position:]))
+	}
+	return runs
+}
+
+// trimLinkPunctuation returns the end of the link at text[start:end] without trailing
+// punctuation; a closing parenthesis is kept when the link itself opened one
+func trimLinkPunctuation(text string, start, end int) int {
+	for end > start {
+		last := text[end-1]
+		if last == ')' && strings.Count(text[start:end], "(") >= strings.Count(text[start:end], ")") {
+			break
+		}
+		if !strings.ContainsRune(".,;:!?)]}'\"", rune(last)) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// hyperlinkColor is the text color Word's built-in Hyperlink style uses
+const hyperlinkColor = "0563C1"
+
+// monospaceFont and codeBlockShading are used by SetMonospace and AddCodeBlock
+const (
+	monospaceFont    = "Courier New"
+	codeBlockShading = "F2F2F2"
+)
+
+// blockQuoteIndent (0.5 inch on both sides) and the left rule used by AddBlockQuote
+const (
+	blockQuoteIndent      = 720
+	blockQuoteBorderSize  = 18 // 2.25pt
+	blockQuoteBorderSpace = 8
+)
+
+// AddCrossReference adds a run linking to the bookmark with the given name, formatted like a
+// Word hyperlink (blue, single underline). The bookmark itself must be defined elsewhere.
+func (p *Paragraph) AddCrossReference(bookmarkName, displayText string) *Run {
+	run := p.AddRun(displayText)
+	run.SetHyperlinkAnchor(bookmarkName)
+	run.SetColor(hyperlinkColor)
+	run.SetUnderline(WDUnderlineSingle)
+	return run
+}
+
+const (
+	lineRuleAuto    = "auto"
+	lineRuleExact   = "exact"
+	lineRuleAtLeast = "atLeast"
+
+	// singleLineSpacing is the line value, in 240ths of a line, of single spacing
+	singleLineSpacing = 240
+)
+
+// isLineRule reports whether rule is a valid w:lineRule value
+func isLineRule(rule string) bool {
+	return rule == lineRuleAuto || rule == lineRuleExact || rule == lineRuleAtLeast
+}
+
+// SetSpacing configures paragraph spacing (values in twentieths of a point). With the
+// "auto" line rule, line is measured in 240ths of a line; a zero or negative value
+// would collapse the lines and is treated as single spacing (240).
 func (p *Paragraph) SetSpacing(before, after, line int, lineRule string) {
+	if lineRule == lineRuleAuto && line <= 0 {
+		line = singleLineSpacing
+	}
 	p.spacingBefore = before
 	p.spacingAfter = after
 	p.spacingLine = line
@@ -138,12 +338,34 @@ func (p *Paragraph) SetSpacing(before, after, line int, lineRule string) {
 	}
 }
 
+// noSpacingStyleID is the ID of Word's built-in "No Spacing" paragraph style
+const noSpacingStyleID = "NoSpacing"
+
+// SetCompact removes the space around the paragraph and makes it single-spaced. When the
+// document defines the built-in "No Spacing" style, the paragraph takes that style and drops
+// its direct spacing; otherwise spacing before and after is set to zero with single line
+// spacing.
+func (p *Paragraph) SetCompact() {
+	if sheet := p.owner.styleSheet(); sheet != nil {
+		if _, ok := sheet.paragraphStyles[noSpacingStyleID]; ok {
+			p.style = noSpacingStyleID
+			p.spacingBefore, p.spacingAfter, p.spacingLine, p.spacingLineRule = 0, 0, 0, ""
+			p.spacingBeforeSet, p.spacingAfterSet, p.spacingLineSet, p.spacingLineRuleSet = false, false, false, false
+			return
+		}
+	}
+	p.SetSpacing(0, 0, singleLineSpacing, lineRuleAuto)
+}
+
 // Spacing returns the spacing configuration
 func (p *Paragraph) Spacing() (before, after, line int, lineRule string) {
 	return p.spacingBefore, p.spacingAfter, p.spacingLine, p.spacingLineRule
 }
 
-// SetIndentation configures paragraph indentation (values in twentieths of a point)
+// SetIndentation configures paragraph indentation (values in twentieths of a point).
+// On a numbered paragraph this indentation takes precedence over the indentation of the
+// list level; all four values are written, so a zero hanging indent also replaces the
+// level's hanging indent.
 func (p *Paragraph) SetIndentation(left, right, firstLine, hanging int) {
 	p.indentLeft = left
 	p.indentRight = right
@@ -156,6 +378,39 @@ func (p *Paragraph) SetIndentation(left, right, firstLine, hanging int) {
 	p.indentHangingSet = true
 }
 
+// SetIndentationChars sets the left, first-line and hanging indentation in character
+// units, measured in hundredths of a character (100 is one character) as used by East
+// Asian layouts. Word prefers these over the twip values set by SetIndentation. A first
+// line and a hanging indent exclude each other, so a non-zero hangingChars wins.
+func (p *Paragraph) SetIndentationChars(leftChars, firstLineChars, hangingChars int) {
+	p.indentLeftChars = intPtr(leftChars)
+	p.indentFirstLineChars = nil
+	p.indentHangingChars = nil
+	if hangingChars != 0 {
+		p.indentHangingChars = intPtr(hangingChars)
+	} else {
+		p.indentFirstLineChars = intPtr(firstLineChars)
+	}
+}
+
+// IndentationChars returns the character-unit indentation in hundredths of a character.
+// ok is false when none is set.
+func (p *Paragraph) IndentationChars() (leftChars, firstLineChars, hangingChars int, ok bool) {
+	if p.indentLeftChars == nil && p.indentFirstLineChars == nil && p.indentHangingChars == nil {
+		return 0, 0, 0, false
+	}
+	if p.indentLeftChars != nil {
+		leftChars = *p.indentLeftChars
+	}
+	if p.indentFirstLineChars != nil {
+		firstLineChars = *p.indentFirstLineChars
+	}
+	if p.indentHangingChars != nil {
+		hangingChars = *p.indentHangingChars
+	}
+	return leftChars, firstLineChars, hangingChars, true
+}
+
 // SetBorder configures the border for the specified side. Pass a zero-style border to remove it.
 func (p *Paragraph) SetBorder(side ParagraphBorderSide, border ParagraphBorder) {
 	if side == "" {
@@ -176,6 +431,20 @@ func (p *Paragraph) SetBorder(side ParagraphBorderSide, border ParagraphBorder)
 	p.bordersDefined = true
 }
 
+// SetBox draws a box around the paragraph by applying the border to the top, left, bottom and right sides.
+func (p *Paragraph) SetBox(border ParagraphBorder) {
+	for _, side := range []ParagraphBorderSide{ParagraphBorderTop, ParagraphBorderLeft, ParagraphBorderBottom, ParagraphBorderRight} {
+		p.SetBorder(side, border)
+	}
+}
+
+// SetAllBorders applies the border to the four box sides and to the between border, which
+// separates consecutive paragraphs that share the same border settings.
+func (p *Paragraph) SetAllBorders(border ParagraphBorder) {
+	p.SetBox(border)
+	p.SetBorder(ParagraphBorderBetween, border)
+}
+
 // Border returns the configured border for the given side, if any.
 func (p *Paragraph) Border(side ParagraphBorderSide) (*ParagraphBorder, bool) {
 	if p.borders == nil {
@@ -209,6 +478,47 @@ func (p *Paragraph) SetShading(pattern, fill, color string) {
 	}
 }
 
+// fullWidthShadingPadding is the space in points between the text and the edges of the
+// band drawn by SetFullWidthShading; fullWidthShadingBorder is the width of its borders in
+// eighths of a point
+const (
+	fullWidthShadingPadding = 4
+	fullWidthShadingBorder  = 4
+)
+
+// SetFullWidthShading shades the paragraph as a callout band across the text column. Plain
+// shading only covers the lines of text, so borders in the fill color pad the text on every
+// side, and the left and right indentation is set to the padding so the band lines up with
+// the margins instead of sticking out. Consecutive paragraphs shaded this way form one block.
+func (p *Paragraph) SetFullWidthShading(fill string) {
+	p.SetShading("clear", fill, "auto")
+	p.SetBox(ParagraphBorder{Style: "single", Color: fill, Size: fullWidthShadingBorder, Space: fullWidthShadingPadding})
+	inset := fullWidthShadingPadding*20 + fullWidthShadingBorder*20/8
+	p.indentLeft, p.indentRight = inset, inset
+	p.indentLeftSet, p.indentRightSet = true, true
+}
+
+// SetShadingTheme configures paragraph shading from theme colors, so it follows the
+// document theme. themeFill is the background and themeColor the pattern color; pass ""
+// to omit either.
+func (p *Paragraph) SetShadingTheme(pattern, themeFill, themeColor string) {
+	p.shading = &ParagraphShading{
+		Pattern:    pattern,
+		ThemeFill:  themeFill,
+		ThemeColor: themeColor,
+	}
+}
+
+// ParagraphID returns the w14:paraId Word assigned to the paragraph, or "" if none
+func (p *Paragraph) ParagraphID() string {
+	return p.paraID
+}
+
+// TextID returns the w14:textId Word assigned to the paragraph, or "" if none
+func (p *Paragraph) TextID() string {
+	return p.textID
+}
+
 // Shading returns the paragraph shading information if set.
 func (p *Paragraph) Shading() (*ParagraphShading, bool) {
 	if p.shading == nil {
@@ -222,6 +532,28 @@ func (p *Paragraph) ClearShading() {
 	p.shading = nil
 }
 
+// SetSectionBreak ends a section at this paragraph using the given section properties.
+// Content up to and including the paragraph is laid out according to section. Passing nil removes the break.
+func (p *Paragraph) SetSectionBreak(section *Section) {
+	if section != nil && p.owner != nil {
+		section.setOwner(p.owner)
+	}
+	p.section = section
+}
+
+// SectionBreak returns the section that ends at this paragraph, if any.
+func (p *Paragraph) SectionBreak() (*Section, bool) {
+	if p.section == nil {
+		return nil, false
+	}
+	return p.section, true
+}
+
+// ClearSectionBreak removes the paragraph-level section break.
+func (p *Paragraph) ClearSectionBreak() {
+	p.section = nil
+}
+
 // Indentation returns the indentation configuration
 func (p *Paragraph) Indentation() (left, right, firstLine, hanging int) {
 	return p.indentLeft, p.indentRight, p.indentFirstLine, p.indentHanging
@@ -240,6 +572,7 @@ func (p *Paragraph) Style() string {
 // SetAlignment sets the paragraph alignment
 func (p *Paragraph) SetAlignment(alignment WDAlignParagraph) {
 	p.alignment = alignment
+	p.alignmentSet = true
 }
 
 // Alignment returns the paragraph alignment
@@ -247,7 +580,71 @@ func (p *Paragraph) Alignment() WDAlignParagraph {
 	return p.alignment
 }
 
-// SetNumbering applies numbering to the paragraph using the specified numbering ID and level
+func (p *Paragraph) hasAlignment() bool {
+	return p.alignmentSet || p.alignment != WDAlignParagraphLeft
+}
+
+// EffectiveAlignment returns the alignment the paragraph is laid out with: the direct
+// alignment if set, otherwise the one inherited from its style (following basedOn) or the
+// document defaults, falling back to left.
+func (p *Paragraph) EffectiveAlignment() WDAlignParagraph {
+	if p.hasAlignment() {
+		return p.alignment
+	}
+	if layout := p.styleLayout(); layout.alignment != nil {
+		return *layout.alignment
+	}
+	return WDAlignParagraphLeft
+}
+
+// EffectiveSpacing returns the spacing the paragraph is laid out with, resolving each value
+// not set directly through its style (following basedOn) and the document defaults. Values
+// specified nowhere take Word's defaults: no space before or after and single line spacing
+// (240, "auto").
+func (p *Paragraph) EffectiveSpacing() (before, after, line int, lineRule string) {
+	layout := p.styleLayout()
+	before, after, line, lineRule = 0, 0, singleLineSpacing, lineRuleAuto
+	if layout.before != nil {
+		before = *layout.before
+	}
+	if layout.after != nil {
+		after = *layout.after
+	}
+	if layout.line != nil {
+		line = *layout.line
+	}
+	if layout.lineRule != nil {
+		lineRule = *layout.lineRule
+	}
+	if p.spacingBeforeSet {
+		before = p.spacingBefore
+	}
+	if p.spacingAfterSet {
+		after = p.spacingAfter
+	}
+	if p.spacingLineSet {
+		line = p.spacingLine
+	}
+	if p.spacingLineRuleSet && p.spacingLineRule != "" {
+		lineRule = p.spacingLineRule
+	}
+	return before, after, line, lineRule
+}
+
+// styleLayout returns the paragraph properties inherited from the paragraph's style
+func (p *Paragraph) styleLayout() paragraphLayout {
+	if p.owner == nil {
+		return paragraphLayout{}
+	}
+	sheet := p.owner.styleSheet()
+	if sheet == nil {
+		return paragraphLayout{}
+	}
+	return sheet.paragraphLayout(p.style)
+}
+
+// SetNumbering applies numbering to the paragraph using the specified numbering ID and level.
+// The list level supplies the indentation unless SetIndentation overrides it.
 func (p *Paragraph) SetNumbering(numID, level int) {
 	p.numberingApplied = true
 	p.numberingID = numID
@@ -285,10 +682,13 @@ func (p *Paragraph) ClearRuns() {
 	p.runs = nil
 }
 
-// Text returns the combined text of all runs in the paragraph
+// Text returns the combined text of all runs in the paragraph. Runs marked as tracked deletions are skipped.
 func (p *Paragraph) Text() string {
 	var text strings.Builder
 	for _, run := range p.runs {
+		if run.IsDeletion() {
+			continue
+		}
 		text.WriteString(run.Text())
 	}
 	return text.String()
@@ -306,6 +706,9 @@ func (p *Paragraph) Clear() {
 	p.indentRightSet = false
 	p.indentFirstLineSet = false
 	p.indentHangingSet = false
+	p.indentLeftChars = nil
+	p.indentFirstLineChars = nil
+	p.indentHangingChars = nil
 	p.spacingBefore = 0
 	p.spacingAfter = 0
 	p.spacingLine = 0
@@ -319,6 +722,10 @@ func (p *Paragraph) Clear() {
 	p.keepLines = nil
 	p.pageBreakBefore = nil
 	p.widowControl = nil
+	p.rightToLeft = nil
+	p.snapToGrid = nil
+	p.autoSpaceLatin = nil
+	p.autoSpaceNumbers = nil
 	p.borders = make(map[ParagraphBorderSide]*ParagraphBorder)
 	p.bordersDefined = false
 	p.shading = nil
@@ -327,51 +734,102 @@ func (p *Paragraph) Clear() {
 	}
 }
 
+// inlineWrapper is a w:smartTag or w:customXml element read around runs. The library does
+// not model it; the start tag with its properties child and the end tag are kept as read.
+type inlineWrapper struct {
+	start  string
+	end    string
+	parent *inlineWrapper
+}
+
+// chain returns the wrapper and its enclosing wrappers, outermost first
+func (w *inlineWrapper) chain() []*inlineWrapper {
+	var chain []*inlineWrapper
+	for ; w != nil; w = w.parent {
+		chain = append([]*inlineWrapper{w}, chain...)
+	}
+	return chain
+}
+
 // ToXML converts the paragraph to WordprocessingML XML
 func (p *Paragraph) ToXML() string {
 	var runsXML strings.Builder
+	// consecutive runs read from the same smartTag or customXml share one wrapper element
+	var open []*inlineWrapper
 	for _, run := range p.runs {
+		chain := run.wrapper.chain()
+		common := 0
+		for common < len(open) && common < len(chain) && open[common] == chain[common] {
+			common++
+		}
+		for i := len(open) - 1; i >= common; i-- {
+			runsXML.WriteString(open[i].end)
+		}
+		for _, wrapper := range chain[common:] {
+			runsXML.WriteString(wrapper.start)
+		}
+		open = chain
 		runsXML.WriteString(run.ToXML())
 	}
+	for i := len(open) - 1; i >= 0; i-- {
+		runsXML.WriteString(open[i].end)
+	}
 
 	var pPr string
-	if p.style != "" || p.alignment != WDAlignParagraphLeft || p.numberingApplied || p.hasSpacing() || p.hasIndentation() || p.hasTabStops() || p.hasBorders() || p.hasShading() || p.hasKeepSettings() || len(p.markRunProperties) > 0 || p.section != nil {
+	if p.style != "" || p.hasAlignment() || p.numberingApplied || p.hasSpacing() || p.hasIndentation() || p.hasTabStops() || p.hasBorders() || p.hasShading() || p.hasKeepSettings() || p.rightToLeft != nil || p.snapToGrid != nil || p.autoSpaceLatin != nil || p.autoSpaceNumbers != nil || len(p.markRunProperties) > 0 || p.section != nil {
 		var pPrContent strings.Builder
 
+		// Children follow the CT_PPr sequence; Word rejects out-of-order properties
 		if p.style != "" {
 			pPrContent.WriteString(fmt.Sprintf(`<w:pStyle w:val="%s"/>`, p.style))
 		}
 
-		if p.alignment != WDAlignParagraphLeft {
-			pPrContent.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, p.alignment))
+		if p.hasKeepSettings() {
+			pPrContent.WriteString(p.keepSettingsXML())
 		}
 
 		if p.numberingApplied {
 			pPrContent.WriteString(fmt.Sprintf(`<w:numPr><w:ilvl w:val="%d"/><w:numId w:val="%d"/></w:numPr>`, p.numberingLevel, p.numberingID))
 		}
 
-		if p.hasSpacing() {
-			pPrContent.WriteString(p.spacingXML())
+		if p.hasBorders() {
+			pPrContent.WriteString(p.bordersXML())
 		}
 
-		if p.hasIndentation() {
-			pPrContent.WriteString(p.indentationXML())
+		if p.hasShading() {
+			pPrContent.WriteString(p.shadingXML())
 		}
 
 		if p.hasTabStops() {
 			pPrContent.WriteString(p.tabsXML())
 		}
 
-		if p.hasBorders() {
-			pPrContent.WriteString(p.bordersXML())
+		if p.autoSpaceLatin != nil {
+			pPrContent.WriteString(onOffXML("w:autoSpaceDE", *p.autoSpaceLatin))
 		}
 
-		if p.hasShading() {
-			pPrContent.WriteString(p.shadingXML())
+		if p.autoSpaceNumbers != nil {
+			pPrContent.WriteString(onOffXML("w:autoSpaceDN", *p.autoSpaceNumbers))
 		}
 
-		if p.hasKeepSettings() {
-			pPrContent.WriteString(p.keepSettingsXML())
+		if p.rightToLeft != nil {
+			pPrContent.WriteString(onOffXML("w:bidi", *p.rightToLeft))
+		}
+
+		if p.snapToGrid != nil {
+			pPrContent.WriteString(onOffXML("w:snapToGrid", *p.snapToGrid))
+		}
+
+		if p.hasSpacing() {
+			pPrContent.WriteString(p.spacingXML())
+		}
+
+		if p.hasIndentation() {
+			pPrContent.WriteString(p.indentationXML())
+		}
+
+		if p.hasAlignment() {
+			pPrContent.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, p.alignment))
 		}
 
 		if len(p.markRunProperties) > 0 {
@@ -390,7 +848,15 @@ func (p *Paragraph) ToXML() string {
 		pPr = fmt.Sprintf(`<w:pPr>%s</w:pPr>`, pPrContent.String())
 	}
 
-	return fmt.Sprintf(`<w:p>%s%s</w:p>`, pPr, runsXML.String())
+	var attrs string
+	if p.paraID != "" {
+		attrs += fmt.Sprintf(` w14:paraId="%s"`, xmlEscapeAttribute(p.paraID))
+	}
+	if p.textID != "" {
+		attrs += fmt.Sprintf(` w14:textId="%s"`, xmlEscapeAttribute(p.textID))
+	}
+
+	return fmt.Sprintf(`<w:p%s>%s%s</w:p>`, attrs, pPr, runsXML.String())
 }
 
 func (p *Paragraph) hasSpacing() bool {
@@ -422,7 +888,8 @@ func (p *Paragraph) spacingXML() string {
 func (p *Paragraph) hasIndentation() bool {
 	// Consider attributes explicitly set, even if value is zero
 	return p.indentLeftSet || p.indentRightSet || p.indentFirstLineSet || p.indentHangingSet ||
-		p.indentLeft != 0 || p.indentRight != 0 || p.indentFirstLine != 0 || p.indentHanging != 0
+		p.indentLeft != 0 || p.indentRight != 0 || p.indentFirstLine != 0 || p.indentHanging != 0 ||
+		p.indentLeftChars != nil || p.indentFirstLineChars != nil || p.indentHangingChars != nil
 }
 
 func (p *Paragraph) indentationXML() string {
@@ -439,6 +906,15 @@ func (p *Paragraph) indentationXML() string {
 	if p.indentHangingSet {
 		attrs = append(attrs, fmt.Sprintf(`w:hanging="%d"`, p.indentHanging))
 	}
+	if p.indentLeftChars != nil {
+		attrs = append(attrs, fmt.Sprintf(`w:leftChars="%d"`, *p.indentLeftChars))
+	}
+	if p.indentFirstLineChars != nil {
+		attrs = append(attrs, fmt.Sprintf(`w:firstLineChars="%d"`, *p.indentFirstLineChars))
+	}
+	if p.indentHangingChars != nil {
+		attrs = append(attrs, fmt.Sprintf(`w:hangingChars="%d"`, *p.indentHangingChars))
+	}
 	if len(attrs) == 0 {
 		return ""
 	}
@@ -517,6 +993,85 @@ func (p *Paragraph) ClearWidowControl() {
 	p.widowControl = nil
 }
 
+// SetRightToLeft sets the paragraph reading direction (w:bidi) for Arabic, Hebrew and other
+// right-to-left scripts. In a right-to-left paragraph Word reads alignment, indentation and
+// tab stops from the right edge: the default WDAlignParagraphLeft aligns text to the right
+// margin, WDAlignParagraphRight to the left margin, and left indents and tab positions are
+// measured from the right.
+func (p *Paragraph) SetRightToLeft(enabled bool) {
+	p.rightToLeft = boolPtr(enabled)
+}
+
+// RightToLeft reports whether the paragraph uses right-to-left reading direction
+func (p *Paragraph) RightToLeft() bool {
+	if p.rightToLeft == nil {
+		return false
+	}
+	return *p.rightToLeft
+}
+
+// ClearRightToLeft clears the reading direction override, inheriting it from the style
+func (p *Paragraph) ClearRightToLeft() {
+	p.rightToLeft = nil
+}
+
+// SetSnapToGrid sets whether the paragraph's lines align to the section's document grid.
+// Passing false lets the paragraph keep its own line spacing in a grid-based layout.
+func (p *Paragraph) SetSnapToGrid(enabled bool) {
+	p.snapToGrid = boolPtr(enabled)
+}
+
+// SnapToGrid reports whether the paragraph snaps to the document grid, which is the default.
+func (p *Paragraph) SnapToGrid() bool {
+	if p.snapToGrid == nil {
+		return true
+	}
+	return *p.snapToGrid
+}
+
+// ClearSnapToGrid clears the snap-to-grid override, reverting to the default
+func (p *Paragraph) ClearSnapToGrid() {
+	p.snapToGrid = nil
+}
+
+// SetAutoSpaceLatin sets whether space is added automatically between East Asian and Latin
+// text (w:autoSpaceDE)
+func (p *Paragraph) SetAutoSpaceLatin(enabled bool) {
+	p.autoSpaceLatin = boolPtr(enabled)
+}
+
+// AutoSpaceLatin reports whether East Asian and Latin text are spaced apart, which is the default.
+func (p *Paragraph) AutoSpaceLatin() bool {
+	if p.autoSpaceLatin == nil {
+		return true
+	}
+	return *p.autoSpaceLatin
+}
+
+// ClearAutoSpaceLatin clears the East Asian and Latin spacing override, reverting to the default
+func (p *Paragraph) ClearAutoSpaceLatin() {
+	p.autoSpaceLatin = nil
+}
+
+// SetAutoSpaceNumbers sets whether space is added automatically between East Asian text and
+// numbers (w:autoSpaceDN)
+func (p *Paragraph) SetAutoSpaceNumbers(enabled bool) {
+	p.autoSpaceNumbers = boolPtr(enabled)
+}
+
+// AutoSpaceNumbers reports whether East Asian text and numbers are spaced apart, which is the default.
+func (p *Paragraph) AutoSpaceNumbers() bool {
+	if p.autoSpaceNumbers == nil {
+		return true
+	}
+	return *p.autoSpaceNumbers
+}
+
+// ClearAutoSpaceNumbers clears the East Asian and number spacing override, reverting to the default
+func (p *Paragraph) ClearAutoSpaceNumbers() {
+	p.autoSpaceNumbers = nil
+}
+
 // AddTabStop adds a tab stop to the paragraph
 func (p *Paragraph) AddTabStop(position int, alignment WDTabAlignment, leader WDTabLeader) {
 	align := alignment
@@ -631,7 +1186,14 @@ func (p *Paragraph) shadingXML() string {
 	if color == "" {
 		color = "auto"
 	}
-	return fmt.Sprintf(`<w:shd w:val="%s" w:color="%s" w:fill="%s"/>`, pattern, color, fill)
+	var theme string
+	if p.shading.ThemeColor != "" {
+		theme += fmt.Sprintf(` w:themeColor="%s"`, xmlEscapeAttribute(p.shading.ThemeColor))
+	}
+	if p.shading.ThemeFill != "" {
+		theme += fmt.Sprintf(` w:themeFill="%s"`, xmlEscapeAttribute(p.shading.ThemeFill))
+	}
+	return fmt.Sprintf(`<w:shd w:val="%s" w:color="%s"%s w:fill="%s"/>`, pattern, color, theme, fill)
 }
 
 func (p *Paragraph) tabsXML() string {
@@ -706,25 +1268,45 @@ type Run struct {
 	underline       WDUnderline
 	size            int // font size in half-points
 	color           string
+	themeColor      string
+	themeTint       string
+	themeShade      string
 	font            string
+	eastAsiaFont    string // w:rFonts/@w:eastAsia
+	fontHint        string // w:rFonts/@w:hint
 	highlight       WDColorIndex
+	sizeSet         bool // sizeSet to highlightSet mark values set explicitly, not inherited
+	colorSet        bool
+	fontSet         bool
+	highlightSet    bool
 	breakType       BreakType // Type of break to add after this run
 	hasBreak        bool      // Whether this run has a break
 	hyperlinkURL    string
 	hyperlinkAnchor string
-	strike          bool
-	doubleStrike    bool
-	smallCaps       bool
-	allCaps         bool
-	shadow          bool
-	outline         bool
-	emboss          bool
-	imprint         bool
-	picture         *Picture
-	charSpacing     *int
-	kern            *int
-	baselineShift   *int
-	spacePreserve   bool
+	// hyperlinkHistory adds the link to the followed-links list when clicked (w:history)
+	hyperlinkHistory bool
+	strike           bool
+	doubleStrike     bool
+	smallCaps        *bool
+	allCaps          *bool
+	shadow           bool
+	outline          bool
+	emboss           bool
+	imprint          bool
+	noProof          bool
+	border           *RunBorder
+	picture          *Picture
+	chart            *Chart
+	pict             string // raw w:pict element (VML shapes such as watermarks), preserved verbatim
+	charSpacing      *int
+	kern             *int
+	baselineShift    *int
+	spacePreserve    bool
+	multiline        bool // newlines in text are written as line breaks (SetMultilineText)
+	revision         *Revision
+	wrapper          *inlineWrapper // smartTag or customXml element around the run, preserved verbatim
+	// fieldInstruction marks the run as a complex field; the run text is the cached result.
+	fieldInstruction string
 }
 
 // NewRun creates a new run with the specified text
@@ -744,14 +1326,29 @@ func (r *Run) Text() string {
 	return r.text
 }
 
-// SetText sets the text content of the run
+// SetText sets the text content of the run. The text is written literally; use
+// SetMultilineText to turn newlines into line breaks.
 func (r *Run) SetText(text string) {
 	r.text = text
+	r.multiline = false
 	if needsSpacePreserve(text) {
 		r.spacePreserve = true
 	}
 }
 
+// SetMultilineText sets the run text, turning each newline ("\n" or "\r\n") into a line
+// break so multi-line strings keep their layout instead of collapsing onto one line.
+func (r *Run) SetMultilineText(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	r.text = strings.ReplaceAll(text, "\r", "\n")
+	r.multiline = true
+	for _, line := range strings.Split(r.text, "\n") {
+		if needsSpacePreserve(line) {
+			r.spacePreserve = true
+		}
+	}
+}
+
 // SetSpacePreserve overrides automatic detection and forces xml:space="preserve" when true.
 func (r *Run) SetSpacePreserve(preserve bool) {
 	r.spacePreserve = preserve
@@ -782,14 +1379,26 @@ func (r *Run) SetDoubleStrikethrough(doubleStrike bool) {
 	r.doubleStrike = doubleStrike
 }
 
-// SetSmallCaps toggles small caps formatting
+// SetSmallCaps toggles small caps formatting. Passing false emits an explicit override that
+// cancels small caps inherited from a style; use ClearSmallCaps to remove the setting.
 func (r *Run) SetSmallCaps(smallCaps bool) {
-	r.smallCaps = smallCaps
+	r.smallCaps = boolPtr(smallCaps)
+}
+
+// ClearSmallCaps removes the small caps setting so the run inherits it from its style
+func (r *Run) ClearSmallCaps() {
+	r.smallCaps = nil
 }
 
-// SetAllCaps toggles all caps formatting
+// SetAllCaps toggles all caps formatting. Passing false emits an explicit override that
+// cancels all caps inherited from a style; use ClearAllCaps to remove the setting.
 func (r *Run) SetAllCaps(allCaps bool) {
-	r.allCaps = allCaps
+	r.allCaps = boolPtr(allCaps)
+}
+
+// ClearAllCaps removes the all caps setting so the run inherits it from its style
+func (r *Run) ClearAllCaps() {
+	r.allCaps = nil
 }
 
 // SetShadow toggles text shadow effect
@@ -812,6 +1421,16 @@ func (r *Run) SetImprint(imprint bool) {
 	r.imprint = imprint
 }
 
+// SetNoProof toggles whether spelling and grammar checking is suppressed for the run
+func (r *Run) SetNoProof(noProof bool) {
+	r.noProof = noProof
+}
+
+// SetMonospace sets the run font to a monospaced family (Courier New), e.g. for code
+func (r *Run) SetMonospace() {
+	r.SetFont(monospaceFont)
+}
+
 // SetUnderline sets the underline formatting
 func (r *Run) SetUnderline(underline WDUnderline) {
 	r.underline = underline
@@ -819,26 +1438,189 @@ func (r *Run) SetUnderline(underline WDUnderline) {
 
 // SetSize sets the font size in points
 func (r *Run) SetSize(size int) {
-	r.size = size * 2 // Convert to half-points
+	r.setSizeRaw(size * 2) // Convert to half-points
+}
+
+// SetSizeHalfPoints sets the font size in half-points, the unit stored in the file (21
+// for 10.5pt)
+func (r *Run) SetSizeHalfPoints(halfPoints int) {
+	r.setSizeRaw(halfPoints)
+}
+
+// SetSizePoints sets a font size that may include a half point, such as 10.5. Sizes are
+// rounded to the nearest half point.
+func (r *Run) SetSizePoints(points float64) {
+	r.setSizeRaw(int(math.Round(points * 2)))
 }
 
 func (r *Run) setSizeRaw(halfPoints int) {
 	r.size = halfPoints
+	r.sizeSet = true
+}
+
+// HasSize reports whether the font size was set explicitly rather than inherited
+func (r *Run) HasSize() bool {
+	return r.sizeSet
 }
 
-// SetColor sets the text color
+// ClearSize removes the explicit font size so the run inherits it again
+func (r *Run) ClearSize() {
+	r.size = 22
+	r.sizeSet = false
+}
+
+// SetColor sets the text color as a hex value (e.g. "FF0000") or "auto". An empty color
+// removes the explicit color, like ClearColor.
 func (r *Run) SetColor(color string) {
+	if color == "" {
+		r.ClearColor()
+		return
+	}
 	r.color = color
+	r.colorSet = true
+}
+
+// SetColorAuto sets the text color explicitly to automatic (w:val="auto"), letting Word
+// pick black or white against the background. Unlike an unset color it overrides a color
+// inherited from the style.
+func (r *Run) SetColorAuto() {
+	r.SetColor("auto")
+}
+
+// HasColor reports whether a text color, automatic included, was set explicitly
+func (r *Run) HasColor() bool {
+	return r.colorSet
+}
+
+// ClearColor removes the explicit text color so the run inherits it again. A theme color
+// is kept; use ClearThemeColor to remove it.
+func (r *Run) ClearColor() {
+	r.color = "auto"
+	r.colorSet = false
 }
 
-// SetFont sets the font family
+// SetThemeColor sets the text color to a theme color (e.g. "accent1", "text2"). tint and shade
+// are optional hex bytes ("99", "BF") lightening or darkening the theme color; pass "" to omit.
+func (r *Run) SetThemeColor(name, tint, shade string) {
+	r.themeColor = name
+	r.themeTint = tint
+	r.themeShade = shade
+}
+
+// ThemeColor returns the theme color name with its tint and shade, if set.
+func (r *Run) ThemeColor() (name, tint, shade string) {
+	return r.themeColor, r.themeTint, r.themeShade
+}
+
+// ClearThemeColor removes the theme color, leaving the explicit color in place.
+func (r *Run) ClearThemeColor() {
+	r.themeColor = ""
+	r.themeTint = ""
+	r.themeShade = ""
+}
+
+// SetFont sets the font family. An empty font removes the explicit font, like ClearFont.
 func (r *Run) SetFont(font string) {
+	if font == "" {
+		r.ClearFont()
+		return
+	}
 	r.font = font
+	r.fontSet = true
+}
+
+// HasFont reports whether the font family was set explicitly rather than inherited
+func (r *Run) HasFont() bool {
+	return r.fontSet
+}
+
+// ClearFont removes the explicit font family so the run inherits it again
+func (r *Run) ClearFont() {
+	r.font = "Calibri"
+	r.fontSet = false
+}
+
+// SetEastAsiaFont sets the font used for East Asian characters, leaving the Latin font
+// alone. An empty font removes the override.
+func (r *Run) SetEastAsiaFont(font string) {
+	r.eastAsiaFont = font
 }
 
-// SetHighlight sets the highlight color
+// EastAsiaFont returns the font used for East Asian characters, if set
+func (r *Run) EastAsiaFont() string {
+	return r.eastAsiaFont
+}
+
+// SetFontHint sets which font slot Word uses for characters shared by several scripts
+// (w:rFonts/@w:hint): "default", "eastAsia" or "cs". Mixed Latin/CJK runs depend on it to
+// render punctuation and quotes in the East Asian font. An empty hint removes it.
+func (r *Run) SetFontHint(hint string) {
+	r.fontHint = hint
+}
+
+// FontHint returns the font hint of the run, if set
+func (r *Run) FontHint() string {
+	return r.fontHint
+}
+
+// SetHighlight sets the highlight color. WDColorIndexAuto removes the highlight, while
+// WDColorIndexNone writes an explicit "no highlight" that overrides the style.
 func (r *Run) SetHighlight(highlight WDColorIndex) {
+	if highlight == WDColorIndexAuto || highlight == "" {
+		r.ClearHighlight()
+		return
+	}
 	r.highlight = highlight
+	r.highlightSet = true
+}
+
+// HasHighlight reports whether a highlight, WDColorIndexNone included, was set explicitly
+func (r *Run) HasHighlight() bool {
+	return r.highlightSet
+}
+
+// ClearHighlight removes the explicit highlight so the run inherits it again
+func (r *Run) ClearHighlight() {
+	r.highlight = WDColorIndexAuto
+	r.highlightSet = false
+}
+
+// SetBorder draws a border around the run's text. Unlike a paragraph border, which boxes
+// whole lines, it only surrounds the run. An empty style removes the border.
+func (r *Run) SetBorder(border RunBorder) {
+	if border.Style == "" {
+		r.border = nil
+		return
+	}
+	r.border = &border
+}
+
+// Border returns the run border, if any
+func (r *Run) Border() (*RunBorder, bool) {
+	if r.border == nil {
+		return nil, false
+	}
+	border := *r.border
+	return &border, true
+}
+
+// ClearBorder removes the run border
+func (r *Run) ClearBorder() {
+	r.border = nil
+}
+
+func (r *Run) borderXML() string {
+	attrs := []string{fmt.Sprintf(`w:val="%s"`, xmlEscapeAttribute(r.border.Style))}
+	if r.border.Size > 0 {
+		attrs = append(attrs, fmt.Sprintf(`w:sz="%d"`, r.border.Size))
+	}
+	attrs = append(attrs, fmt.Sprintf(`w:space="%d"`, r.border.Space))
+	color := r.border.Color
+	if color == "" {
+		color = "auto"
+	}
+	attrs = append(attrs, fmt.Sprintf(`w:color="%s"`, xmlEscapeAttribute(color)))
+	return fmt.Sprintf(`<w:bdr %s/>`, strings.Join(attrs, " "))
 }
 
 // SetHyperlink sets an external hyperlink for the run
@@ -853,6 +1635,17 @@ func (r *Run) SetHyperlinkAnchor(anchor string) {
 	r.hyperlinkURL = ""
 }
 
+// SetHyperlinkHistory controls whether following the run's hyperlink adds it to the list of
+// visited links, which makes consumers render it in the FollowedHyperlink style.
+func (r *Run) SetHyperlinkHistory(history bool) {
+	r.hyperlinkHistory = history
+}
+
+// HyperlinkHistory reports whether following the hyperlink is recorded as visited
+func (r *Run) HyperlinkHistory() bool {
+	return r.hyperlinkHistory
+}
+
 // HasHyperlink reports whether the run is a hyperlink
 func (r *Run) HasHyperlink() bool {
 	return r.hyperlinkURL != "" || r.hyperlinkAnchor != ""
@@ -935,13 +1728,58 @@ func (r *Run) Picture() *Picture {
 	return r.picture
 }
 
+// HasChart reports whether the run contains an inline chart
+func (r *Run) HasChart() bool {
+	return r.chart != nil
+}
+
+// Chart returns the chart embedded in the run, if any
+func (r *Run) Chart() *Chart {
+	return r.chart
+}
+
 // AddPicture embeds an image into the run. Width and height are specified in EMUs.
 // Pass zero for either dimension to preserve the image's aspect ratio using the source size.
 func (r *Run) AddPicture(path string, widthEMU, heightEMU int64) (*Picture, error) {
+	return r.AddPictureWithOptions(path, widthEMU, heightEMU, PictureOptions{})
+}
+
+// AddPictureWithOptions embeds an image like AddPicture, deriving any size left at zero
+// from the source image at the resolution given in opts.
+func (r *Run) AddPictureWithOptions(path string, widthEMU, heightEMU int64, opts PictureOptions) (*Picture, error) {
+	if r.owner == nil {
+		return nil, fmt.Errorf("%w: run is not attached to a document", ErrNotAttached)
+	}
+	picture, err := r.owner.addPictureFromFile(path, widthEMU, heightEMU, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.picture = picture
+	return picture, nil
+}
+
+// AddPictureFromBytes embeds image data held in memory into the run. name is a file name
+// whose extension gives the image format (such as "chart.png"); sizes work as for
+// AddPictureWithOptions.
+func (r *Run) AddPictureFromBytes(data []byte, name string, widthEMU, heightEMU int64, opts PictureOptions) (*Picture, error) {
+	if r.owner == nil {
+		return nil, fmt.Errorf("%w: run is not attached to a document", ErrNotAttached)
+	}
+	picture, err := r.owner.addPictureFromBytes(data, name, widthEMU, heightEMU, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.picture = picture
+	return picture, nil
+}
+
+// AddLinkedPicture places a picture referencing an external image into the run.
+// Width and height are specified in EMUs and must both be positive.
+func (r *Run) AddLinkedPicture(url string, widthEMU, heightEMU int64) (*Picture, error) {
 	if r.owner == nil {
-		return nil, fmt.Errorf("run is not attached to a document")
+		return nil, fmt.Errorf("%w: run is not attached to a document", ErrNotAttached)
 	}
-	picture, err := r.owner.addPictureFromFile(path, widthEMU, heightEMU)
+	picture, err := r.owner.addLinkedPicture(url, widthEMU, heightEMU)
 	if err != nil {
 		return nil, err
 	}
@@ -977,12 +1815,12 @@ func (r *Run) IsDoubleStrikethrough() bool {
 
 // IsSmallCaps reports whether the run uses small caps
 func (r *Run) IsSmallCaps() bool {
-	return r.smallCaps
+	return r.smallCaps != nil && *r.smallCaps
 }
 
 // IsAllCaps reports whether the run uses all caps
 func (r *Run) IsAllCaps() bool {
-	return r.allCaps
+	return r.allCaps != nil && *r.allCaps
 }
 
 // HasShadow reports whether the run has a shadow effect
@@ -1005,21 +1843,68 @@ func (r *Run) IsImprinted() bool {
 	return r.imprint
 }
 
+// NoProof reports whether spelling and grammar checking is suppressed for the run
+func (r *Run) NoProof() bool {
+	return r.noProof
+}
+
 // Underline returns the underline style of the run
 func (r *Run) Underline() WDUnderline {
 	return r.underline
 }
 
-// Size returns the font size in points
+// Size returns the font size in whole points, rounding a half-point size down. Use
+// SizePoints or SizeHalfPoints for sizes such as 10.5pt.
 func (r *Run) Size() int {
 	return r.size / 2
 }
 
+// SizePoints returns the font size in points, including any half point
+func (r *Run) SizePoints() float64 {
+	return float64(r.size) / 2
+}
+
+// SizeHalfPoints returns the font size in half-points
+func (r *Run) SizeHalfPoints() int {
+	return r.size
+}
+
 // Color returns the text color of the run
 func (r *Run) Color() string {
 	return r.color
 }
 
+func (r *Run) colorXML() string {
+	attrs := fmt.Sprintf(`w:val="%s"`, r.color)
+	if r.themeColor != "" {
+		attrs += fmt.Sprintf(` w:themeColor="%s"`, xmlEscapeAttribute(r.themeColor))
+		if r.themeTint != "" {
+			attrs += fmt.Sprintf(` w:themeTint="%s"`, xmlEscapeAttribute(r.themeTint))
+		}
+		if r.themeShade != "" {
+			attrs += fmt.Sprintf(` w:themeShade="%s"`, xmlEscapeAttribute(r.themeShade))
+		}
+	}
+	return fmt.Sprintf(`<w:color %s/>`, attrs)
+}
+
+// fontsXML returns the w:rFonts element of the run. The Latin font is left out when it is
+// not set explicitly so that a hint or East Asian font alone does not pin it.
+func (r *Run) fontsXML() string {
+	var attrs strings.Builder
+	if r.fontSet {
+		font := xmlEscapeAttribute(r.font)
+		attrs.WriteString(fmt.Sprintf(` w:ascii="%s" w:hAnsi="%s"`, font, font))
+	}
+	if r.eastAsiaFont != "" {
+		attrs.WriteString(fmt.Sprintf(` w:eastAsia="%s"`, xmlEscapeAttribute(r.eastAsiaFont)))
+	}
+	if r.fontHint != "" {
+		attrs.WriteString(fmt.Sprintf(` w:hint="%s"`, xmlEscapeAttribute(r.fontHint)))
+	}
+	return "<w:rFonts" + attrs.String() + "/>"
+}
+
 // Font returns the font family of the run
 func (r *Run) Font() string {
 	return r.font
@@ -1040,8 +1925,8 @@ func (r *Run) BreakType() BreakType {
 	return r.breakType
 }
 
-// ToXML converts the run to WordprocessingML XML
-func (r *Run) ToXML() string {
+// propertiesXML returns the w:rPr element of the run, or "" when it has no properties
+func (r *Run) propertiesXML() string {
 	var rPr strings.Builder
 
 	if r.bold {
@@ -1060,12 +1945,12 @@ func (r *Run) ToXML() string {
 		rPr.WriteString("<w:dstrike/>")
 	}
 
-	if r.smallCaps {
-		rPr.WriteString("<w:smallCaps/>")
+	if r.smallCaps != nil {
+		rPr.WriteString(onOffXML("w:smallCaps", *r.smallCaps))
 	}
 
-	if r.allCaps {
-		rPr.WriteString("<w:caps/>")
+	if r.allCaps != nil {
+		rPr.WriteString(onOffXML("w:caps", *r.allCaps))
 	}
 
 	if r.shadow {
@@ -1084,27 +1969,35 @@ func (r *Run) ToXML() string {
 		rPr.WriteString("<w:imprint/>")
 	}
 
+	if r.noProof {
+		rPr.WriteString("<w:noProof/>")
+	}
+
 	if r.underline != WDUnderlineNone {
 		rPr.WriteString(fmt.Sprintf(`<w:u w:val="%s"/>`, r.underline))
 	}
 
-	if r.size != 22 {
+	if r.sizeSet {
 		rPr.WriteString(fmt.Sprintf(`<w:sz w:val="%d"/>`, r.size))
 		rPr.WriteString(fmt.Sprintf(`<w:szCs w:val="%d"/>`, r.size))
 	}
 
-	if r.color != "auto" {
-		rPr.WriteString(fmt.Sprintf(`<w:color w:val="%s"/>`, r.color))
+	if r.colorSet || r.themeColor != "" {
+		rPr.WriteString(r.colorXML())
 	}
 
-	if r.font != "Calibri" {
-		rPr.WriteString(fmt.Sprintf(`<w:rFonts w:ascii="%s" w:hAnsi="%s"/>`, r.font, r.font))
+	if r.fontSet || r.eastAsiaFont != "" || r.fontHint != "" {
+		rPr.WriteString(r.fontsXML())
 	}
 
-	if r.highlight != WDColorIndexAuto {
+	if r.highlightSet {
 		rPr.WriteString(fmt.Sprintf(`<w:highlight w:val="%s"/>`, r.highlight))
 	}
 
+	if r.border != nil {
+		rPr.WriteString(r.borderXML())
+	}
+
 	if r.charSpacing != nil {
 		rPr.WriteString(fmt.Sprintf(`<w:spacing w:val="%d"/>`, *r.charSpacing))
 	}
@@ -1117,21 +2010,44 @@ func (r *Run) ToXML() string {
 		rPr.WriteString(fmt.Sprintf(`<w:position w:val="%d"/>`, *r.baselineShift))
 	}
 
-	var rPrXML string
-	if rPr.Len() > 0 {
-		rPrXML = fmt.Sprintf("<w:rPr>%s</w:rPr>", rPr.String())
+	if rPr.Len() == 0 {
+		return ""
 	}
+	return "<w:rPr>" + rPr.String() + "</w:rPr>"
+}
+
+// ToXML converts the run to WordprocessingML XML
+func (r *Run) ToXML() string {
+	rPrXML := r.propertiesXML()
 
 	var content strings.Builder
 
 	if r.text != "" {
-		escaped := strings.ReplaceAll(r.text, "&", "&amp;")
-		escaped = strings.ReplaceAll(escaped, "<", "&lt;")
-		escaped = strings.ReplaceAll(escaped, ">", "&gt;")
-		if r.spacePreserve || needsSpacePreserve(r.text) {
-			content.WriteString(fmt.Sprintf(`<w:t xml:space="preserve">%s</w:t>`, escaped))
-		} else {
-			content.WriteString(fmt.Sprintf(`<w:t>%s</w:t>`, escaped))
+		textTag := "w:t"
+		if r.IsDeletion() {
+			textTag = "w:delText"
+		}
+		// Newlines cannot be represented inside w:t, so each line of multiline text becomes
+		// its own text element separated by line breaks.
+		lines := []string{r.text}
+		if r.multiline {
+			lines = strings.Split(r.text, "\n")
+		}
+		for i, line := range lines {
+			if i > 0 {
+				content.WriteString("<w:br/>")
+			}
+			if line == "" {
+				continue
+			}
+			escaped := strings.ReplaceAll(line, "&", "&amp;")
+			escaped = strings.ReplaceAll(escaped, "<", "&lt;")
+			escaped = strings.ReplaceAll(escaped, ">", "&gt;")
+			if r.spacePreserve || needsSpacePreserve(line) {
+				content.WriteString(fmt.Sprintf(`<%s xml:space="preserve">%s</%s>`, textTag, escaped, textTag))
+			} else {
+				content.WriteString(fmt.Sprintf(`<%s>%s</%s>`, textTag, escaped, textTag))
+			}
 		}
 	}
 
@@ -1139,6 +2055,14 @@ func (r *Run) ToXML() string {
 		content.WriteString(r.picture.toXML())
 	}
 
+	if r.chart != nil {
+		content.WriteString(r.chart.toXML())
+	}
+
+	if r.pict != "" {
+		content.WriteString(r.pict)
+	}
+
 	if r.hasBreak {
 		switch r.breakType {
 		case BreakTypePage:
@@ -1154,7 +2078,16 @@ func (r *Run) ToXML() string {
 		content.WriteString("<w:t/>")
 	}
 
-	runXML := fmt.Sprintf(`<w:r>%s%s</w:r>`, rPrXML, content.String())
+	var runXML string
+	if r.fieldInstruction != "" {
+		runXML = r.fieldXML(rPrXML, content.String())
+	} else {
+		runXML = fmt.Sprintf(`<w:r>%s%s</w:r>`, rPrXML, content.String())
+	}
+
+	if r.revision != nil {
+		runXML = r.revision.wrap(runXML)
+	}
 
 	if r.HasHyperlink() {
 		return r.wrapWithHyperlink(runXML)
@@ -1173,6 +2106,9 @@ func (r *Run) wrapWithHyperlink(runXML string) string {
 	if r.hyperlinkAnchor != "" {
 		attrs = append(attrs, fmt.Sprintf(`w:anchor="%s"`, r.hyperlinkAnchor))
 	}
+	if r.hyperlinkHistory {
+		attrs = append(attrs, `w:history="1"`)
+	}
 
 	attrStr := ""
 	if len(attrs) > 0 {
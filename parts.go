@@ -8,6 +8,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // DocumentPart represents the main document part of a Word document
@@ -15,6 +16,8 @@ type documentElement struct {
 	paragraph *Paragraph
 	table     *Table
 	section   *Section
+	// source locates the element in the document.xml it was parsed from (nil when added later)
+	source *elementSource
 }
 
 type DocumentPart struct {
@@ -25,19 +28,40 @@ type DocumentPart struct {
 	sections       []*Section
 	bodyElements   []documentElement
 	drawingCounter int
-	headers        []*Header
-	footers        []*Footer
-	headerByRelID  map[string]*Header
-	footerByRelID  map[string]*Footer
-	headerByTarget map[string]*Header
-	footerByTarget map[string]*Footer
+	// revisionCounter tracks the highest w:ins/w:del id so new revisions get unique ids.
+	revisionCounter int
+	headers         []*Header
+	footers         []*Footer
+	headerByRelID   map[string]*Header
+	footerByRelID   map[string]*Footer
+	headerByTarget  map[string]*Header
+	footerByTarget  map[string]*Footer
+	// rootAttrs holds the namespace declarations and mc:Ignorable of the parsed w:document
+	// element so regenerated XML keeps content from other namespaces valid.
+	rootAttrs []xml.Attr
+	// styles caches the parsed styles part for effective property lookups; stylesData is the
+	// part data it was parsed from.
+	styles     *styleSheet
+	stylesData []byte
+	// original holds the offsets of the parsed document.xml and the state of surgical
+	// editing, see Document.SetSurgicalEdit.
+	original originalDocument
+	// background is the w:background element (page color) written before the body
+	background *pageBackground
+}
+
+// pageBackground is the document background. raw holds the element as read, which may carry
+// a VML fill; it is dropped when the color is set.
+type pageBackground struct {
+	color string
+	raw   string
 }
 
 // NewDocumentPart creates a new document part
 func NewDocumentPart() *DocumentPart {
 	// Create default document XML
 	docXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:w10="urn:schemas-microsoft-com:office:word">
   <w:body>
     <w:sectPr>
       <w:pgSz w:w="11906" w:h="16838"/>
@@ -65,12 +89,14 @@ func (dp *DocumentPart) loadFromXML() error {
 	dp.sections = make([]*Section, 0)
 	dp.bodyElements = make([]documentElement, 0)
 	dp.drawingCounter = 0
+	dp.revisionCounter = 0
 	dp.headers = make([]*Header, 0)
 	dp.footers = make([]*Footer, 0)
 	dp.headerByRelID = make(map[string]*Header)
 	dp.footerByRelID = make(map[string]*Footer)
 	dp.headerByTarget = make(map[string]*Header)
 	dp.footerByTarget = make(map[string]*Footer)
+	dp.background = nil
 
 	if dp.Part == nil || len(dp.Part.Data) == 0 {
 		return nil
@@ -78,8 +104,10 @@ func (dp *DocumentPart) loadFromXML() error {
 
 	decoder := xml.NewDecoder(bytes.NewReader(dp.Part.Data))
 	decoder.Strict = false
+	dp.original = originalDocument{data: dp.Part.Data, bodyStart: -1, bodyEnd: -1, backgroundStart: -1}
 
 	for {
+		offset := int(decoder.InputOffset())
 		tok, err := decoder.Token()
 		if err != nil {
 			if err == io.EOF {
@@ -91,27 +119,48 @@ func (dp *DocumentPart) loadFromXML() error {
 		switch t := tok.(type) {
 		case xml.StartElement:
 			switch t.Name.Local {
+			case "document":
+				dp.rootAttrs = rootNamespaceAttrs(t)
+				dp.original.rootStart, dp.original.rootEnd = offset, int(decoder.InputOffset())
+			case "background":
+				if t.Name.Space != wordprocessingMLNamespace || dp.original.bodyStart >= 0 {
+					continue
+				}
+				raw, err := collectElementXML(decoder, t)
+				if err != nil {
+					return fmt.Errorf("failed to parse background: %w", err)
+				}
+				dp.background = &pageBackground{color: attrValue(t.Attr, "color"), raw: raw}
+				dp.original.background = raw
+				dp.original.backgroundStart, dp.original.backgroundEnd = offset, int(decoder.InputOffset())
+			case "body":
+				dp.original.bodyOpen = offset
+				dp.original.bodyStart = int(decoder.InputOffset())
 			case "p":
 				paragraph, err := parseParagraph(decoder, t, dp)
 				if err != nil {
 					return fmt.Errorf("failed to parse paragraph: %w", err)
 				}
 				dp.paragraphs = append(dp.paragraphs, paragraph)
-				dp.bodyElements = append(dp.bodyElements, documentElement{paragraph: paragraph})
+				dp.bodyElements = append(dp.bodyElements, documentElement{paragraph: paragraph, source: dp.original.source(offset, decoder)})
 			case "tbl":
 				table, err := parseTable(decoder, t, dp)
 				if err != nil {
 					return fmt.Errorf("failed to parse table: %w", err)
 				}
 				dp.tables = append(dp.tables, table)
-				dp.bodyElements = append(dp.bodyElements, documentElement{table: table})
+				dp.bodyElements = append(dp.bodyElements, documentElement{table: table, source: dp.original.source(offset, decoder)})
 			case "sectPr":
 				section, err := parseSectionProperties(decoder, t, dp)
 				if err != nil {
 					return fmt.Errorf("failed to parse section: %w", err)
 				}
 				dp.sections = append(dp.sections, section)
-				dp.bodyElements = append(dp.bodyElements, documentElement{section: section})
+				dp.bodyElements = append(dp.bodyElements, documentElement{section: section, source: dp.original.source(offset, decoder)})
+			}
+		case xml.EndElement:
+			if t.Name.Local == "body" {
+				dp.original.bodyEnd = offset
 			}
 		}
 	}
@@ -128,16 +177,34 @@ func (dp *DocumentPart) loadFromXML() error {
 func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart) (*Paragraph, error) {
 	paragraph := NewParagraph()
 	paragraph.owner = dp
+	for _, attr := range start.Attr {
+		if attr.Name.Space != wordML2010Namespace {
+			continue
+		}
+		switch attr.Name.Local {
+		case "paraId":
+			paragraph.paraID = attr.Value
+		case "textId":
+			paragraph.textID = attr.Value
+		}
+	}
 
 	var (
-		currentRun      *Run
-		textBuffer      strings.Builder
-		inText          bool
-		hyperlinkURL    string
-		hyperlinkAnchor string
+		currentRun       *Run
+		textBuffer       strings.Builder
+		inText           bool
+		hyperlinkURL     string
+		hyperlinkAnchor  string
+		hyperlinkHistory bool
+		revision         *Revision
+		wrapper          *inlineWrapper
+		field            fieldParser
+		// pendingLineBreaks counts text-wrapping breaks that follow run text; they become
+		// "\n" if more text follows in the same run, the last one a trailing break otherwise.
+		pendingLineBreaks int
 	)
 
-	applyHyperlinkContext := func(run *Run) {
+	applyRunContext := func(run *Run) {
 		if run == nil {
 			return
 		}
@@ -147,6 +214,20 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 		} else if hyperlinkAnchor != "" {
 			run.SetHyperlinkAnchor(hyperlinkAnchor)
 		}
+		if hyperlinkURL != "" || hyperlinkAnchor != "" {
+			run.SetHyperlinkHistory(hyperlinkHistory)
+		}
+		if revision != nil {
+			copy := *revision
+			run.revision = &copy
+		}
+		run.wrapper = wrapper
+	}
+
+	newFieldRun := func() *Run {
+		run := NewRun("")
+		applyRunContext(run)
+		return run
 	}
 
 	for {
@@ -228,10 +309,15 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 						paragraph.spacingLineSet = true
 					}
 				}
-				if val := attrValue(t.Attr, "lineRule"); val != "" {
+				if val := attrValue(t.Attr, "lineRule"); isLineRule(val) {
 					paragraph.spacingLineRule = val
 					paragraph.spacingLineRuleSet = true
 				}
+				// an auto rule without a usable line value means single spacing
+				if paragraph.spacingLineRule == lineRuleAuto && paragraph.spacingLine <= 0 {
+					paragraph.spacingLine = singleLineSpacing
+					paragraph.spacingLineSet = true
+				}
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
@@ -260,6 +346,15 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 						paragraph.indentHangingSet = true
 					}
 				}
+				if v, err := strconv.Atoi(attrValue(t.Attr, "leftChars")); err == nil {
+					paragraph.indentLeftChars = intPtr(v)
+				}
+				if v, err := strconv.Atoi(attrValue(t.Attr, "firstLineChars")); err == nil {
+					paragraph.indentFirstLineChars = intPtr(v)
+				}
+				if v, err := strconv.Atoi(attrValue(t.Attr, "hangingChars")); err == nil {
+					paragraph.indentHangingChars = intPtr(v)
+				}
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
@@ -272,6 +367,29 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
+			case "autoSpaceDE":
+				paragraph.autoSpaceLatin = parseOnOff(t.Attr)
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "autoSpaceDN":
+				paragraph.autoSpaceNumbers = parseOnOff(t.Attr)
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "bidi":
+				paragraph.rightToLeft = parseOnOff(t.Attr)
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "snapToGrid":
+				// the run property of the same name is not modelled
+				if currentRun == nil {
+					paragraph.snapToGrid = parseOnOff(t.Attr)
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
 			case "keepLines":
 				paragraph.keepLines = parseOnOff(t.Attr)
 				if err := skipElement(decoder, t); err != nil {
@@ -295,9 +413,26 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 				for _, stop := range stops {
 					paragraph.AddTabStop(stop.Position, stop.Alignment, stop.Leader)
 				}
+			case "smartTag", "customXml":
+				if currentRun == nil && t.Name.Space == wordprocessingMLNamespace {
+					var start, end strings.Builder
+					writeStartElement(&start, t)
+					writeEndElement(&end, t.End())
+					wrapper = &inlineWrapper{start: start.String(), end: end.String(), parent: wrapper}
+				}
+			case "smartTagPr", "customXmlPr":
+				if wrapper != nil && currentRun == nil {
+					raw, err := collectElementXML(decoder, t)
+					if err != nil {
+						return nil, err
+					}
+					wrapper.start += raw
+					continue
+				}
 			case "hyperlink":
 				hyperlinkURL = ""
 				hyperlinkAnchor = attrValue(t.Attr, "anchor")
+				hyperlinkHistory = parseBinaryFlag(attrValue(t.Attr, "history"))
 				if relID := attrValue(t.Attr, "id"); relID != "" && dp != nil {
 					if target, mode, ok := dp.relationshipTarget(relID); ok {
 						if strings.EqualFold(mode, "External") {
@@ -308,10 +443,46 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 					}
 				}
 				// Continue parsing child runs within the hyperlink
+			case "ins", "del":
+				if currentRun != nil {
+					if err := skipElement(decoder, t); err != nil {
+						return nil, err
+					}
+					break
+				}
+				revision = parseRevisionAttributes(t, dp)
+				// Continue parsing child runs within the tracked change
+			case "fldChar":
+				switch attrValue(t.Attr, "fldCharType") {
+				case "begin":
+					field.begin()
+				case "separate":
+					field.separate(currentRun != nil)
+				case "end":
+					field.end()
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "instrText":
+				text, err := collectCharData(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				if field.depth == 1 && !field.inResult {
+					field.instruction.WriteString(text)
+				}
+			case "fldSimple":
+				field.begin()
+				if field.depth == 1 {
+					field.instruction.WriteString(attrValue(t.Attr, "instr"))
+					field.separate(false)
+				}
+				// Continue parsing the cached result runs within the simple field
 			case "r":
 				currentRun = NewRun("")
-				applyHyperlinkContext(currentRun)
-			case "t":
+				applyRunContext(currentRun)
+			case "t", "delText":
 				textBuffer.Reset()
 				inText = true
 				if currentRun != nil {
@@ -346,14 +517,14 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 				}
 			case "smallCaps":
 				if currentRun != nil {
-					currentRun.SetSmallCaps(true)
+					currentRun.smallCaps = parseOnOff(t.Attr)
 				}
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
 			case "caps":
 				if currentRun != nil {
-					currentRun.SetAllCaps(true)
+					currentRun.allCaps = parseOnOff(t.Attr)
 				}
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
@@ -386,6 +557,15 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
+			case "noProof":
+				if currentRun != nil {
+					if v := parseOnOff(t.Attr); v != nil {
+						currentRun.SetNoProof(*v)
+					}
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
 			case "u":
 				if currentRun != nil {
 					underline := attrValue(t.Attr, "val")
@@ -399,6 +579,9 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 					if val := attrValue(t.Attr, "val"); val != "" {
 						currentRun.SetColor(val)
 					}
+					if theme := attrValue(t.Attr, "themeColor"); theme != "" {
+						currentRun.SetThemeColor(theme, attrValue(t.Attr, "themeTint"), attrValue(t.Attr, "themeShade"))
+					}
 				}
 			case "rFonts":
 				if currentRun != nil {
@@ -409,6 +592,8 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 					if font != "" {
 						currentRun.SetFont(font)
 					}
+					currentRun.SetEastAsiaFont(attrValue(t.Attr, "eastAsia"))
+					currentRun.SetFontHint(attrValue(t.Attr, "hint"))
 				}
 			case "sz":
 				if currentRun != nil {
@@ -431,9 +616,19 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 						currentRun.SetHighlight(WDColorIndex(val))
 					}
 				}
+			case "bdr":
+				if currentRun != nil {
+					border := parseBorderAttributes(t.Attr)
+					currentRun.SetBorder(RunBorder{Style: border.Style, Color: border.Color, Size: border.Size, Space: border.Space})
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
 			case "shd":
 				if currentRun == nil {
 					paragraph.SetShading(attrValue(t.Attr, "val"), attrValue(t.Attr, "fill"), attrValue(t.Attr, "color"))
+					paragraph.shading.ThemeFill = attrValue(t.Attr, "themeFill")
+					paragraph.shading.ThemeColor = attrValue(t.Attr, "themeColor")
 				}
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
@@ -464,25 +659,42 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 			case "br":
 				if currentRun == nil {
 					currentRun = NewRun("")
-					applyHyperlinkContext(currentRun)
+					applyRunContext(currentRun)
+				}
+				breakType := mapBreakType(attrValue(t.Attr, "type"))
+				if breakType == BreakTypeText && currentRun.text != "" {
+					pendingLineBreaks++
+				} else {
+					currentRun.AddBreak(breakType)
 				}
-				currentRun.AddBreak(mapBreakType(attrValue(t.Attr, "type")))
 			case "drawing":
 				if currentRun == nil {
 					currentRun = NewRun("")
-					applyHyperlinkContext(currentRun)
+					applyRunContext(currentRun)
 				}
-				picture, err := parseDrawing(decoder, t, dp)
+				picture, chart, err := parseDrawing(decoder, t, dp)
 				if err != nil {
 					return nil, err
 				}
-				if picture != nil {
+				if chart != nil {
+					currentRun.chart = chart
+				} else if picture != nil {
 					currentRun.picture = picture
 				}
+			case "pict":
+				if currentRun == nil {
+					currentRun = NewRun("")
+					applyRunContext(currentRun)
+				}
+				raw, err := collectElementXML(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				currentRun.pict = raw
 			case "AlternateContent":
 				if currentRun == nil {
 					currentRun = NewRun("")
-					applyHyperlinkContext(currentRun)
+					applyRunContext(currentRun)
 				}
 				picture, err := parseAlternateContent(decoder, t, dp)
 				if err != nil {
@@ -502,35 +714,93 @@ func parseParagraph(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPa
 			}
 		case xml.EndElement:
 			switch t.Name.Local {
-			case "t":
+			case "t", "delText":
 				if currentRun != nil {
 					existing := currentRun.Text()
+					multiline := currentRun.multiline || pendingLineBreaks > 0
+					existing += strings.Repeat("\n", pendingLineBreaks)
+					pendingLineBreaks = 0
 					currentRun.SetText(existing + textBuffer.String())
+					currentRun.multiline = multiline
 				}
 				inText = false
 			case "r":
+				if currentRun != nil && pendingLineBreaks > 0 {
+					if pendingLineBreaks > 1 {
+						currentRun.text += strings.Repeat("\n", pendingLineBreaks-1)
+						currentRun.multiline = true
+					}
+					currentRun.AddBreak(BreakTypeText)
+					pendingLineBreaks = 0
+				}
 				if currentRun != nil {
-					paragraph.runs = append(paragraph.runs, currentRun)
+					if field.active() {
+						field.runs = append(field.runs, currentRun)
+						if field.ending {
+							paragraph.runs = append(paragraph.runs, field.finish(newFieldRun))
+						}
+					} else {
+						paragraph.runs = append(paragraph.runs, currentRun)
+					}
 				}
 				currentRun = nil
+			case "fldSimple":
+				field.end()
+				if field.ending {
+					paragraph.runs = append(paragraph.runs, field.finish(newFieldRun))
+				}
 			case "hyperlink":
 				hyperlinkURL = ""
 				hyperlinkAnchor = ""
+				hyperlinkHistory = false
+			case "smartTag", "customXml":
+				if wrapper != nil && currentRun == nil && t.Name.Space == wordprocessingMLNamespace {
+					wrapper = wrapper.parent
+				}
+			case "ins", "del":
+				revision = nil
 			case "p":
+				if field.active() {
+					// Fields spanning paragraphs (e.g. TOC) are kept as their plain result runs
+					paragraph.runs = append(paragraph.runs, field.runs...)
+				}
 				return paragraph, nil
 			}
 		}
 	}
 }
 
-func parseDrawing(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart) (*Picture, error) {
+func parseRevisionAttributes(start xml.StartElement, dp *DocumentPart) *Revision {
+	revision := &Revision{
+		Type:   RevisionType(start.Name.Local),
+		Author: attrValue(start.Attr, "author"),
+	}
+	if val := attrValue(start.Attr, "id"); val != "" {
+		if id, err := strconv.Atoi(val); err == nil {
+			revision.ID = id
+			if dp != nil && id > dp.revisionCounter {
+				dp.revisionCounter = id
+			}
+		}
+	}
+	if val := attrValue(start.Attr, "date"); val != "" {
+		if date, err := time.Parse(time.RFC3339, val); err == nil {
+			revision.Date = date
+		}
+	}
+	return revision
+}
+
+// parseDrawing reads an inline drawing, which holds either a picture or a chart.
+func parseDrawing(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart) (*Picture, *Chart, error) {
 	picture := &Picture{docPart: dp}
+	chartRelID := ""
 	depth := 1
 
 	for depth > 0 {
 		tok, err := decoder.Token()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		switch t := tok.(type) {
@@ -560,10 +830,23 @@ func parseDrawing(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart
 				if descr := attrValue(t.Attr, "descr"); descr != "" {
 					picture.description = descr
 				}
+				picture.hidden = parseBinaryFlag(attrValue(t.Attr, "hidden"))
+			case "cNvGraphicFramePr":
+				// a frame without locks leaves the aspect ratio free
+				picture.aspectUnlocked = true
+			case "graphicFrameLocks":
+				picture.aspectUnlocked = !parseBinaryFlag(attrValue(t.Attr, "noChangeAspect"))
 			case "blip":
 				if relID := attrValue(t.Attr, "embed"); relID != "" {
 					picture.relID = relID
 				}
+				if relID := attrValue(t.Attr, "link"); relID != "" {
+					picture.linkRelID = relID
+				}
+			case "chart":
+				if t.Name.Space == drawingMLChartNamespace {
+					chartRelID = attrValue(t.Attr, "id")
+				}
 			}
 		case xml.EndElement:
 			depth--
@@ -578,12 +861,46 @@ func parseDrawing(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart
 			picture.target = target
 		}
 	}
+	if picture.linkRelID != "" && dp != nil {
+		if target, _, ok := dp.relationshipTarget(picture.linkRelID); ok {
+			picture.linkTarget = target
+		}
+	}
 
 	if dp != nil && picture.docPrID > dp.drawingCounter {
 		dp.drawingCounter = picture.docPrID
 	}
 
-	return picture, nil
+	if chartRelID != "" && picture.relID == "" && picture.linkRelID == "" {
+		return nil, parseChartDrawing(picture, chartRelID, dp), nil
+	}
+	return picture, nil, nil
+}
+
+// parseChartDrawing turns the frame of a chart drawing into a Chart, reading the chart type
+// and data from the referenced chart part when available.
+func parseChartDrawing(frame *Picture, relID string, dp *DocumentPart) *Chart {
+	chart := &Chart{
+		docPart:   dp,
+		relID:     relID,
+		widthEMU:  frame.widthEMU,
+		heightEMU: frame.heightEMU,
+		docPrID:   frame.docPrID,
+		name:      frame.name,
+	}
+	if dp == nil {
+		return chart
+	}
+	if target, _, ok := dp.relationshipTarget(relID); ok {
+		chart.target = target
+		if part, ok := dp.pkg.parts[resolveRelationshipTarget(dp.Part.URI, target)]; ok {
+			if chartType, data, err := parseChartPart(part.Data); err == nil {
+				chart.chartType = chartType
+				chart.data = data
+			}
+		}
+	}
+	return chart
 }
 
 func parseAlternateContent(decoder *xml.Decoder, start xml.StartElement, dp *DocumentPart) (*Picture, error) {
@@ -605,7 +922,7 @@ func extractPictureFromContainer(decoder *xml.Decoder, start xml.StartElement, d
 		case xml.StartElement:
 			switch t.Name.Local {
 			case "drawing":
-				picture, err := parseDrawing(decoder, t, dp)
+				picture, _, err := parseDrawing(decoder, t, dp)
 				if err != nil {
 					return nil, err
 				}
@@ -849,7 +1166,11 @@ func parseTableRow(decoder *xml.Decoder, start xml.StartElement, table *Table, d
 				}
 				row.cells = append(row.cells, cell)
 			case "trPr":
-				if err := skipElement(decoder, t); err != nil {
+				if err := parseTableRowProperties(decoder, t, row); err != nil {
+					return nil, err
+				}
+			case "tblPrEx":
+				if err := parseTablePropertyExceptions(decoder, t, row); err != nil {
 					return nil, err
 				}
 			default:
@@ -865,6 +1186,114 @@ func parseTableRow(decoder *xml.Decoder, start xml.StartElement, table *Table, d
 	}
 }
 
+func parseTablePropertyExceptions(decoder *xml.Decoder, start xml.StartElement, row *TableRow) error {
+	exceptions := &TablePropertyExceptions{}
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tblBorders":
+				borders, err := parseTableBorders(decoder, t)
+				if err != nil {
+					return err
+				}
+				exceptions.Borders = make(map[TableBorderSide]TableBorder)
+				for side, border := range borders {
+					if border != nil {
+						exceptions.Borders[side] = *border
+					}
+				}
+			case "tblCellMar":
+				margins, err := parseTableCellMargins(decoder, t)
+				if err != nil {
+					return err
+				}
+				exceptions.CellMargins = margins
+			case "jc":
+				exceptions.Alignment = TableAlignment(attrValue(t.Attr, "val"))
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			default:
+				raw, err := collectElementXML(decoder, t)
+				if err != nil {
+					return err
+				}
+				if row.exceptionsRaw == nil {
+					row.exceptionsRaw = make(map[string]string)
+				}
+				row.exceptionsRaw[t.Name.Local] = raw
+			}
+		case xml.EndElement:
+			if t.Name.Local == start.Name.Local {
+				row.exceptions = exceptions
+				return nil
+			}
+		}
+	}
+}
+
+func parseTableRowProperties(decoder *xml.Decoder, start xml.StartElement, row *TableRow) error {
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "gridBefore", "gridAfter":
+				if v, err := strconv.Atoi(attrValue(t.Attr, "val")); err == nil && v > 0 {
+					if t.Name.Local == "gridBefore" {
+						row.gridBefore = v
+					} else {
+						row.gridAfter = v
+					}
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			case "wBefore", "wAfter":
+				if v, err := strconv.Atoi(attrValue(t.Attr, "w")); err == nil {
+					if t.Name.Local == "wBefore" {
+						row.widthBefore = v
+					} else {
+						row.widthAfter = v
+					}
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			case "tblHeader":
+				row.repeatHeader = *parseOnOff(t.Attr)
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			default:
+				raw, err := collectElementXML(decoder, t)
+				if err != nil {
+					return err
+				}
+				if t.Name.Local == "cnfStyle" || t.Name.Local == "divId" {
+					row.rawPropertiesBefore = append(row.rawPropertiesBefore, raw)
+				} else {
+					row.rawPropertiesAfter = append(row.rawPropertiesAfter, raw)
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == start.Name.Local {
+				return nil
+			}
+		}
+	}
+}
+
 func parseTableCell(decoder *xml.Decoder, start xml.StartElement, row *TableRow, dp *DocumentPart) (*TableCell, error) {
 	cell := &TableCell{
 		row:        row,
@@ -990,6 +1419,12 @@ func parseTableCellProperties(decoder *xml.Decoder, start xml.StartElement, cell
 				if err := skipElement(decoder, t); err != nil {
 					return err
 				}
+			case "tcMar":
+				margins, err := parseTableCellMargins(decoder, t)
+				if err != nil {
+					return err
+				}
+				cell.margins = margins
 			default:
 				if err := skipElement(decoder, t); err != nil {
 					return err
@@ -1064,6 +1499,36 @@ func parseSectionProperties(decoder *xml.Decoder, start xml.StartElement, dp *Do
 				if err := skipElement(decoder, t); err != nil {
 					return nil, err
 				}
+			case "pgNumType":
+				section.pageNumberFormat = attrValue(t.Attr, "fmt")
+				if v, err := strconv.Atoi(attrValue(t.Attr, "start")); err == nil {
+					section.pageNumberStart = &v
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "cols":
+				if v, err := strconv.Atoi(attrValue(t.Attr, "num")); err == nil {
+					section.columnCount = v
+				}
+				if v, err := strconv.Atoi(attrValue(t.Attr, "space")); err == nil {
+					section.columnSpace = v
+				}
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
+			case "docGrid":
+				section.docGridType = attrValue(t.Attr, "type")
+				if v, err := strconv.Atoi(attrValue(t.Attr, "linePitch")); err == nil {
+					section.docGridLinePitch = v
+				}
+				if v, err := strconv.Atoi(attrValue(t.Attr, "charSpace")); err == nil {
+					section.docGridCharSpace = v
+				}
+				section.docGridSet = true
+				if err := skipElement(decoder, t); err != nil {
+					return nil, err
+				}
 			case "headerReference":
 				typeVal := HeaderType(attrValue(t.Attr, "type"))
 				if typeVal == "" {
@@ -1118,11 +1583,11 @@ func (dp *DocumentPart) headerFromRelationship(relID string) (*Header, error) {
 		return header, nil
 	}
 	if dp.pkg == nil {
-		return nil, fmt.Errorf("document part is not associated with a package")
+		return nil, fmt.Errorf("%w: document part is not associated with a package", ErrNotAttached)
 	}
 	target, _, ok := dp.relationshipTarget(relID)
 	if !ok {
-		return nil, fmt.Errorf("relationship %s not found", relID)
+		return nil, fmt.Errorf("%w: relationship %s not found", ErrRelationshipNotFound, relID)
 	}
 	fullPath := resolveRelationshipTarget(dp.Part.URI, target)
 	if header, ok := dp.headerByTarget[fullPath]; ok {
@@ -1131,7 +1596,7 @@ func (dp *DocumentPart) headerFromRelationship(relID string) (*Header, error) {
 	}
 	part, exists := dp.pkg.parts[fullPath]
 	if !exists {
-		return nil, fmt.Errorf("header part %s not found", fullPath)
+		return nil, fmt.Errorf("%w: header part %s not found", ErrPartNotFound, fullPath)
 	}
 	header := newHeader(dp, part)
 	if err := header.loadFromXML(); err != nil {
@@ -1148,11 +1613,11 @@ func (dp *DocumentPart) footerFromRelationship(relID string) (*Footer, error) {
 		return footer, nil
 	}
 	if dp.pkg == nil {
-		return nil, fmt.Errorf("document part is not associated with a package")
+		return nil, fmt.Errorf("%w: document part is not associated with a package", ErrNotAttached)
 	}
 	target, _, ok := dp.relationshipTarget(relID)
 	if !ok {
-		return nil, fmt.Errorf("relationship %s not found", relID)
+		return nil, fmt.Errorf("%w: relationship %s not found", ErrRelationshipNotFound, relID)
 	}
 	fullPath := resolveRelationshipTarget(dp.Part.URI, target)
 	if footer, ok := dp.footerByTarget[fullPath]; ok {
@@ -1161,7 +1626,7 @@ func (dp *DocumentPart) footerFromRelationship(relID string) (*Footer, error) {
 	}
 	part, exists := dp.pkg.parts[fullPath]
 	if !exists {
-		return nil, fmt.Errorf("footer part %s not found", fullPath)
+		return nil, fmt.Errorf("%w: footer part %s not found", ErrPartNotFound, fullPath)
 	}
 	footer := newFooter(dp, part)
 	if err := footer.loadFromXML(); err != nil {
@@ -1175,7 +1640,7 @@ func (dp *DocumentPart) footerFromRelationship(relID string) (*Footer, error) {
 
 func (dp *DocumentPart) createHeaderPart() (*Header, string, error) {
 	if dp == nil || dp.pkg == nil {
-		return nil, "", fmt.Errorf("document part is not associated with a package")
+		return nil, "", fmt.Errorf("%w: document part is not associated with a package", ErrNotAttached)
 	}
 	part := dp.pkg.newHeaderPart()
 	header := newHeader(dp, part)
@@ -1189,7 +1654,7 @@ func (dp *DocumentPart) createHeaderPart() (*Header, string, error) {
 
 func (dp *DocumentPart) createFooterPart() (*Footer, string, error) {
 	if dp == nil || dp.pkg == nil {
-		return nil, "", fmt.Errorf("document part is not associated with a package")
+		return nil, "", fmt.Errorf("%w: document part is not associated with a package", ErrNotAttached)
 	}
 	part := dp.pkg.newFooterPart()
 	footer := newFooter(dp, part)
@@ -1260,11 +1725,57 @@ func parseTableProperties(decoder *xml.Decoder, start xml.StartElement, table *T
 				if err := skipElement(decoder, t); err != nil {
 					return err
 				}
+			case "tblCellSpacing":
+				spacingVal := 0
+				if val := attrValue(t.Attr, "w"); val != "" {
+					if parsed, convErr := strconv.Atoi(val); convErr == nil {
+						spacingVal = parsed
+					}
+				}
+				table.SetCellSpacing(spacingVal, attrValue(t.Attr, "type"))
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
 			case "tblStyle":
 				table.style = attrValue(t.Attr, "val")
 				if err := skipElement(decoder, t); err != nil {
 					return err
 				}
+			case "tblpPr":
+				number := func(name string) int {
+					value, _ := strconv.Atoi(attrValue(t.Attr, name))
+					return value
+				}
+				table.SetFloatingPosition(TablePosition{
+					HorizontalAnchor: attrValue(t.Attr, "horzAnchor"),
+					VerticalAnchor:   attrValue(t.Attr, "vertAnchor"),
+					X:                number("tblpX"),
+					Y:                number("tblpY"),
+					XSpec:            attrValue(t.Attr, "tblpXSpec"),
+					YSpec:            attrValue(t.Attr, "tblpYSpec"),
+					LeftFromText:     number("leftFromText"),
+					RightFromText:    number("rightFromText"),
+					TopFromText:      number("topFromText"),
+					BottomFromText:   number("bottomFromText"),
+				})
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			case "tblOverlap":
+				table.overlap = attrValue(t.Attr, "val")
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			case "tblCaption":
+				table.caption = attrValue(t.Attr, "val")
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
+			case "tblDescription":
+				table.description = attrValue(t.Attr, "val")
+				if err := skipElement(decoder, t); err != nil {
+					return err
+				}
 			case "tblLayout":
 				table.layout = attrValue(t.Attr, "type")
 				if err := skipElement(decoder, t); err != nil {
@@ -1437,6 +1948,27 @@ func skipElement(decoder *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+// collectCharData returns the concatenated character data of an element and consumes it.
+func collectCharData(decoder *xml.Decoder, start xml.StartElement) (string, error) {
+	var builder strings.Builder
+	depth := 1
+	for depth > 0 {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			builder.Write(t)
+		}
+	}
+	return builder.String(), nil
+}
+
 func collectElementXML(decoder *xml.Decoder, start xml.StartElement) (string, error) {
 	var builder strings.Builder
 	writeStartElement(&builder, start)
@@ -1448,6 +1980,13 @@ func collectElementXML(decoder *xml.Decoder, start xml.StartElement) (string, er
 		}
 		switch t := tok.(type) {
 		case xml.StartElement:
+			// proofing marks go stale as soon as the text is edited, so they are dropped
+			if t.Name.Space == wordprocessingMLNamespace && t.Name.Local == "proofErr" {
+				if err := decoder.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
 			writeStartElement(&builder, t)
 			depth++
 		case xml.EndElement:
@@ -1474,6 +2013,16 @@ var namespacePrefixes = map[string]string{
 	"http://schemas.openxmlformats.org/drawingml/2006/picture":               "pic",
 	"http://schemas.openxmlformats.org/drawingml/2006/chart":                 "c",
 	"http://www.w3.org/XML/1998/namespace":                                   "xml",
+	"urn:schemas-microsoft-com:vml":                                          "v",
+	"urn:schemas-microsoft-com:office:office":                                "o",
+	"urn:schemas-microsoft-com:office:word":                                  "w10",
+	"http://schemas.openxmlformats.org/markup-compatibility/2006":            "mc",
+	"http://schemas.openxmlformats.org/officeDocument/2006/math":             "m",
+	wordML2010Namespace: "w14",
+	"http://schemas.microsoft.com/office/word/2012/wordml":                "w15",
+	"http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing": "wp14",
+	"http://schemas.microsoft.com/office/word/2010/wordprocessingShape":   "wps",
+	"http://schemas.microsoft.com/office/word/2010/wordprocessingGroup":   "wpg",
 }
 
 func resolvePrefix(namespace string) string {
@@ -1651,6 +2200,36 @@ func (dp *DocumentPart) AddParagraph(text ...string) *Paragraph {
 	return paragraph
 }
 
+// InsertParagraphAtIndex inserts a paragraph so that it becomes the body element at
+// bodyIndex, counting paragraphs, tables and section properties alike. bodyIndex may equal
+// the number of body elements to append the paragraph.
+func (dp *DocumentPart) InsertParagraphAtIndex(bodyIndex int, text string) (*Paragraph, error) {
+	if bodyIndex < 0 || bodyIndex > len(dp.bodyElements) {
+		return nil, fmt.Errorf("%w: body index %d out of range", ErrIndexOutOfRange, bodyIndex)
+	}
+	paragraph := NewParagraph()
+	paragraph.owner = dp
+	if text != "" {
+		paragraph.AddRun(text)
+	}
+
+	// keep the paragraphs list in body order
+	position := 0
+	for _, elem := range dp.bodyElements[:bodyIndex] {
+		if elem.paragraph != nil {
+			position++
+		}
+	}
+	dp.paragraphs = append(dp.paragraphs[:position], append([]*Paragraph{paragraph}, dp.paragraphs[position:]...)...)
+	dp.bodyElements = append(dp.bodyElements[:bodyIndex],
+		append([]documentElement{{paragraph: paragraph}}, dp.bodyElements[bodyIndex:]...)...)
+
+	// Update the XML data
+	dp.updateXMLData()
+
+	return paragraph, nil
+}
+
 // AddTable adds a new table to the document
 func (dp *DocumentPart) AddTable(rows, cols int) *Table {
 	table := NewTable(rows, cols)
@@ -1686,15 +2265,113 @@ func (dp *DocumentPart) Tables() []*Table {
 	return dp.tables
 }
 
+// AllParagraphs returns every paragraph of the document: body paragraphs and table cell
+// paragraphs (including nested tables) in document order, followed by the paragraphs of
+// each distinct header and footer referenced by the document's sections.
+func (dp *DocumentPart) AllParagraphs() []*Paragraph {
+	paragraphs := make([]*Paragraph, 0, len(dp.paragraphs))
+	for _, element := range dp.bodyElements {
+		switch {
+		case element.paragraph != nil:
+			paragraphs = append(paragraphs, element.paragraph)
+		case element.table != nil:
+			paragraphs = appendTableParagraphs(paragraphs, element.table)
+		}
+	}
+	dp.visitHeadersAndFooters(func(header *Header) {
+		paragraphs = appendStoryParagraphs(paragraphs, header.bodyElements)
+	}, func(footer *Footer) {
+		paragraphs = appendStoryParagraphs(paragraphs, footer.bodyElements)
+	})
+	return paragraphs
+}
+
+// visitHeadersAndFooters calls visitHeader and visitFooter once for each distinct header
+// and footer referenced by the document's sections, in section order.
+func (dp *DocumentPart) visitHeadersAndFooters(visitHeader func(*Header), visitFooter func(*Footer)) {
+	seenHeaders := make(map[*Header]bool)
+	seenFooters := make(map[*Footer]bool)
+	for _, section := range dp.allSections() {
+		for _, headerType := range []HeaderType{HeaderTypeDefault, HeaderTypeFirst, HeaderTypeEven} {
+			ref := section.headerRefs[headerType]
+			if ref == nil || ref.header == nil || seenHeaders[ref.header] {
+				continue
+			}
+			seenHeaders[ref.header] = true
+			visitHeader(ref.header)
+		}
+		for _, footerType := range []FooterType{FooterTypeDefault, FooterTypeFirst, FooterTypeEven} {
+			ref := section.footerRefs[footerType]
+			if ref == nil || ref.footer == nil || seenFooters[ref.footer] {
+				continue
+			}
+			seenFooters[ref.footer] = true
+			visitFooter(ref.footer)
+		}
+	}
+}
+
+// allTables returns the top-level tables of the body, headers and footers
+func (dp *DocumentPart) allTables() []*Table {
+	tables := append([]*Table(nil), dp.Tables()...)
+	dp.visitHeadersAndFooters(func(header *Header) {
+		tables = append(tables, header.tables...)
+	}, func(footer *Footer) {
+		tables = append(tables, footer.tables...)
+	})
+	return tables
+}
+
+func appendStoryParagraphs(paragraphs []*Paragraph, elements []documentElement) []*Paragraph {
+	for _, element := range elements {
+		if element.paragraph != nil {
+			paragraphs = append(paragraphs, element.paragraph)
+		} else if element.table != nil {
+			paragraphs = appendTableParagraphs(paragraphs, element.table)
+		}
+	}
+	return paragraphs
+}
+
+func appendTableParagraphs(paragraphs []*Paragraph, table *Table) []*Paragraph {
+	for _, row := range table.rows {
+		if row == nil {
+			continue
+		}
+		for _, cell := range row.cells {
+			if cell == nil {
+				continue
+			}
+			paragraphs = append(paragraphs, cell.paragraphs...)
+			for _, nested := range cell.tables {
+				paragraphs = appendTableParagraphs(paragraphs, nested)
+			}
+		}
+	}
+	return paragraphs
+}
+
 // Sections returns all sections in the document
 func (dp *DocumentPart) Sections() []*Section {
 	return dp.sections
 }
 
+// allSections returns the sections ended by paragraph section breaks, in document order,
+// followed by the body-level sections.
+func (dp *DocumentPart) allSections() []*Section {
+	sections := make([]*Section, 0, len(dp.sections))
+	for _, element := range dp.bodyElements {
+		if element.paragraph != nil && element.paragraph.section != nil {
+			sections = append(sections, element.paragraph.section)
+		}
+	}
+	return append(sections, dp.sections...)
+}
+
 // InsertTableAfterParagraph inserts a table immediately after the specified paragraph
 func (dp *DocumentPart) InsertTableAfterParagraph(paragraph *Paragraph, rows, cols int) (*Table, error) {
 	if paragraph == nil {
-		return nil, fmt.Errorf("paragraph cannot be nil")
+		return nil, fmt.Errorf("%w: paragraph cannot be nil", ErrInvalidArgument)
 	}
 
 	// Find the index of the paragraph in bodyElements
@@ -1707,7 +2384,7 @@ func (dp *DocumentPart) InsertTableAfterParagraph(paragraph *Paragraph, rows, co
 	}
 
 	if paragraphIndex == -1 {
-		return nil, fmt.Errorf("paragraph not found in document")
+		return nil, fmt.Errorf("%w: paragraph is not in the document", ErrNotFound)
 	}
 
 	// Create new table
@@ -1727,10 +2404,95 @@ func (dp *DocumentPart) InsertTableAfterParagraph(paragraph *Paragraph, rows, co
 	return table, nil
 }
 
+// SplitSectionAt ends the section containing paragraph at that paragraph by attaching a
+// paragraph-level section break. The returned section describes the content up to and
+// including the paragraph and starts out with the same layout and start type as the section
+// it was split from; startType is applied to the section after the break, since it describes
+// how that section begins.
+func (dp *DocumentPart) SplitSectionAt(paragraph *Paragraph, startType SectionStartType) (*Section, error) {
+	if paragraph == nil {
+		return nil, fmt.Errorf("%w: paragraph cannot be nil", ErrInvalidArgument)
+	}
+	if paragraph.section != nil {
+		return nil, fmt.Errorf("%w: paragraph already ends a section", ErrInvalidArgument)
+	}
+
+	paragraphIndex := -1
+	for i, elem := range dp.bodyElements {
+		if elem.paragraph == paragraph {
+			paragraphIndex = i
+			break
+		}
+	}
+	if paragraphIndex == -1 {
+		return nil, fmt.Errorf("%w: paragraph is not in the document", ErrNotFound)
+	}
+
+	following := dp.sectionFollowing(paragraphIndex)
+	section := following.copyLayout(following.startType)
+	following.SetStartType(startType)
+	section.setOwner(dp)
+	paragraph.section = section
+
+	dp.updateXMLData()
+	return section, nil
+}
+
+// finalSection returns the body-level section, which lays out the content after the last
+// section break, creating it when the body has none.
+func (dp *DocumentPart) finalSection() *Section {
+	if len(dp.sections) == 0 {
+		section := NewSection(SectionStartContinuous)
+		section.setOwner(dp)
+		dp.sections = append(dp.sections, section)
+	}
+	return dp.sections[len(dp.sections)-1]
+}
+
+// SectionOf returns the section containing the paragraph, which may also be inside a body
+// table, or nil when the paragraph is not in the document body. A paragraph carrying a
+// section break belongs to the section it ends.
+func (dp *DocumentPart) SectionOf(paragraph *Paragraph) *Section {
+	if paragraph == nil {
+		return nil
+	}
+	for i, elem := range dp.bodyElements {
+		if elem.paragraph == paragraph {
+			return dp.sectionFollowing(i)
+		}
+		if elem.table == nil {
+			continue
+		}
+		for _, cellParagraph := range appendTableParagraphs(nil, elem.table) {
+			if cellParagraph == paragraph {
+				return dp.sectionFollowing(i)
+			}
+		}
+	}
+	return nil
+}
+
+// sectionFollowing returns the section whose break appears at or after the body element index.
+func (dp *DocumentPart) sectionFollowing(index int) *Section {
+	for i := index; i < len(dp.bodyElements); i++ {
+		elem := dp.bodyElements[i]
+		if elem.paragraph != nil && elem.paragraph.section != nil {
+			return elem.paragraph.section
+		}
+		if elem.section != nil {
+			return elem.section
+		}
+	}
+	if len(dp.sections) > 0 {
+		return dp.sections[len(dp.sections)-1]
+	}
+	return NewSection(SectionStartContinuous)
+}
+
 // RemoveParagraph removes the specified paragraph from the document
 func (dp *DocumentPart) RemoveParagraph(paragraph *Paragraph) error {
 	if paragraph == nil {
-		return fmt.Errorf("paragraph cannot be nil")
+		return fmt.Errorf("%w: paragraph cannot be nil", ErrInvalidArgument)
 	}
 
 	// Find and remove from paragraphs slice
@@ -1743,7 +2505,7 @@ func (dp *DocumentPart) RemoveParagraph(paragraph *Paragraph) error {
 	}
 
 	if paragraphIndex == -1 {
-		return fmt.Errorf("paragraph not found in document")
+		return fmt.Errorf("%w: paragraph is not in the document", ErrNotFound)
 	}
 
 	// Remove from paragraphs slice
@@ -1766,7 +2528,7 @@ func (dp *DocumentPart) RemoveParagraph(paragraph *Paragraph) error {
 // RemoveTable removes the specified table from the document
 func (dp *DocumentPart) RemoveTable(table *Table) error {
 	if table == nil {
-		return fmt.Errorf("table cannot be nil")
+		return fmt.Errorf("%w: table cannot be nil", ErrInvalidArgument)
 	}
 
 	// Find and remove from tables slice
@@ -1779,7 +2541,7 @@ func (dp *DocumentPart) RemoveTable(table *Table) error {
 	}
 
 	if tableIndex == -1 {
-		return fmt.Errorf("table not found in document")
+		return fmt.Errorf("%w: table is not in the document", ErrNotFound)
 	}
 
 	// Remove from tables slice
@@ -1802,7 +2564,7 @@ func (dp *DocumentPart) RemoveTable(table *Table) error {
 // RemoveSection removes the specified section from the document
 func (dp *DocumentPart) RemoveSection(section *Section) error {
 	if section == nil {
-		return fmt.Errorf("section cannot be nil")
+		return fmt.Errorf("%w: section cannot be nil", ErrInvalidArgument)
 	}
 
 	// Find and remove from sections slice
@@ -1815,7 +2577,7 @@ func (dp *DocumentPart) RemoveSection(section *Section) error {
 	}
 
 	if sectionIndex == -1 {
-		return fmt.Errorf("section not found in document")
+		return fmt.Errorf("%w: section is not in the document", ErrNotFound)
 	}
 
 	// Remove from sections slice
@@ -1836,16 +2598,34 @@ func (dp *DocumentPart) RemoveSection(section *Section) error {
 }
 
 func (dp *DocumentPart) updateXMLData() {
+	if data, ok := dp.surgicalXMLData(); ok {
+		dp.Part.Data = data
+		return
+	}
+
 	var bodyContent strings.Builder
 
 	hasSectionMarkers := false
+	// the body-level sectPr must close the body, even when content was appended after it
+	var bodySection *Section
 	for _, element := range dp.bodyElements {
 		if element.paragraph != nil {
 			bodyContent.WriteString(element.paragraph.ToXML())
 		} else if element.table != nil {
 			bodyContent.WriteString(element.table.ToXML())
 		} else if element.section != nil {
-			bodyContent.WriteString(element.section.ToXML())
+			bodySection = element.section
+			hasSectionMarkers = true
+		}
+	}
+	if bodySection != nil {
+		bodyContent.WriteString(bodySection.ToXML())
+	}
+
+	// A section break in the final paragraph already defines the last section, so a trailing
+	// body sectPr would add a second, conflicting definition.
+	if n := len(dp.bodyElements); n > 0 {
+		if last := dp.bodyElements[n-1].paragraph; last != nil && last.section != nil {
 			hasSectionMarkers = true
 		}
 	}
@@ -1860,15 +2640,85 @@ func (dp *DocumentPart) updateXMLData() {
 	}
 
 	docXML := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
-<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
-  <w:body>
-    %s
-  </w:body>
-</w:document>`, bodyContent.String())
+%s%s<w:body>%s</w:body></w:document>`, dp.rootStartElement(), dp.backgroundXML(), bodyContent.String())
 
 	dp.Part.Data = []byte(docXML)
 }
 
+// backgroundXML returns the w:background element, or "" when the document has no background
+func (dp *DocumentPart) backgroundXML() string {
+	if dp.background == nil {
+		return ""
+	}
+	if dp.background.raw != "" {
+		return dp.background.raw
+	}
+	return `<w:background w:color="` + xmlEscapeAttribute(dp.background.color) + `"/>`
+}
+
+// defaultRootNamespaces are always declared on the regenerated w:document element.
+var defaultRootNamespaces = []struct{ prefix, uri string }{
+	{"w", "http://schemas.openxmlformats.org/wordprocessingml/2006/main"},
+	{"r", "http://schemas.openxmlformats.org/officeDocument/2006/relationships"},
+	{"v", "urn:schemas-microsoft-com:vml"},
+	{"o", "urn:schemas-microsoft-com:office:office"},
+	{"w10", "urn:schemas-microsoft-com:office:word"},
+	{"w14", wordML2010Namespace},
+}
+
+const markupCompatibilityNamespace = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+
+// wordML2010Namespace holds Word 2010 extensions such as the w14:paraId paragraph stamps
+const wordML2010Namespace = "http://schemas.microsoft.com/office/word/2010/wordml"
+
+func rootNamespaceAttrs(start xml.StartElement) []xml.Attr {
+	var attrs []xml.Attr
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == markupCompatibilityNamespace && attr.Name.Local == "Ignorable") {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+func (dp *DocumentPart) rootStartElement() string {
+	var builder strings.Builder
+	builder.WriteString("<w:document")
+	declared := make(map[string]bool)
+	for _, ns := range defaultRootNamespaces {
+		builder.WriteString(fmt.Sprintf(` xmlns:%s="%s"`, ns.prefix, ns.uri))
+		declared[ns.prefix] = true
+	}
+	ignorable := ""
+	mcPrefix := ""
+	for _, attr := range dp.rootAttrs {
+		if attr.Name.Space != "xmlns" {
+			ignorable = attr.Value
+			continue
+		}
+		if attr.Value == markupCompatibilityNamespace {
+			mcPrefix = attr.Name.Local
+		}
+		// Passthrough XML is written with the canonical prefix, so declare it as well.
+		prefixes := []string{attr.Name.Local}
+		if canonical := resolvePrefix(attr.Value); canonical != "" && canonical != attr.Name.Local {
+			prefixes = append(prefixes, canonical)
+		}
+		for _, prefix := range prefixes {
+			if declared[prefix] {
+				continue
+			}
+			declared[prefix] = true
+			builder.WriteString(fmt.Sprintf(` xmlns:%s="%s"`, prefix, escapeAttribute(attr.Value)))
+		}
+	}
+	if ignorable != "" && mcPrefix != "" {
+		builder.WriteString(fmt.Sprintf(` %s:Ignorable="%s"`, mcPrefix, escapeAttribute(ignorable)))
+	}
+	builder.WriteString(">")
+	return builder.String()
+}
+
 func (dp *DocumentPart) ensureHyperlinkRelationship(url string) string {
 	if dp == nil || dp.pkg == nil {
 		return ""
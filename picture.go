@@ -42,11 +42,16 @@ type Picture struct {
 	docPart     *DocumentPart
 	relID       string
 	target      string
+	linkRelID   string
+	linkTarget  string
 	widthEMU    int64
 	heightEMU   int64
 	docPrID     int
 	name        string
 	description string
+	hidden      bool
+	// aspectUnlocked clears the noChangeAspect lock, which new pictures carry
+	aspectUnlocked bool
 }
 
 // WidthEMU returns the picture width in English Metric Units (EMUs).
@@ -59,6 +64,35 @@ func (p *Picture) HeightEMU() int64 {
 	return p.heightEMU
 }
 
+// SetSize updates the picture dimensions in EMUs. Passing zero for one dimension keeps the
+// current aspect ratio; passing zero for both leaves the size unchanged.
+func (p *Picture) SetSize(widthEMU, heightEMU int64) {
+	switch {
+	case widthEMU <= 0 && heightEMU <= 0:
+		return
+	case widthEMU <= 0:
+		widthEMU = scaleEMU(heightEMU, p.widthEMU, p.heightEMU)
+	case heightEMU <= 0:
+		heightEMU = scaleEMU(widthEMU, p.heightEMU, p.widthEMU)
+	}
+	p.widthEMU = widthEMU
+	p.heightEMU = heightEMU
+}
+
+// SetSizeInches updates the picture dimensions in inches. Zero values behave as in SetSize.
+func (p *Picture) SetSizeInches(width, height float64) {
+	p.SetSize(InchesToEMU(width), InchesToEMU(height))
+}
+
+// ScaleBy multiplies both picture dimensions by factor. Non-positive factors are ignored.
+func (p *Picture) ScaleBy(factor float64) {
+	if factor <= 0 {
+		return
+	}
+	p.widthEMU = int64(math.Round(float64(p.widthEMU) * factor))
+	p.heightEMU = int64(math.Round(float64(p.heightEMU) * factor))
+}
+
 // RelationshipID returns the relationship ID referencing the image part.
 func (p *Picture) RelationshipID() string {
 	return p.relID
@@ -69,6 +103,17 @@ func (p *Picture) Target() string {
 	return p.target
 }
 
+// IsLinked reports whether the picture references an external image (r:link) instead of,
+// or in addition to, an embedded image part.
+func (p *Picture) IsLinked() bool {
+	return p.linkRelID != ""
+}
+
+// LinkTarget returns the external URL or path of a linked picture.
+func (p *Picture) LinkTarget() string {
+	return p.linkTarget
+}
+
 // Name returns the docPr name for this picture.
 func (p *Picture) Name() string {
 	return p.name
@@ -79,26 +124,54 @@ func (p *Picture) Description() string {
 	return p.description
 }
 
+// SetLockAspectRatio controls whether consumers such as Word keep the aspect ratio when the
+// picture is resized interactively. New pictures are locked.
+func (p *Picture) SetLockAspectRatio(locked bool) {
+	p.aspectUnlocked = !locked
+}
+
+// LockAspectRatio reports whether the picture's aspect ratio is locked.
+func (p *Picture) LockAspectRatio() bool {
+	return !p.aspectUnlocked
+}
+
+// SetHidden controls whether the picture is hidden (the docPr hidden flag).
+func (p *Picture) SetHidden(hidden bool) {
+	p.hidden = hidden
+}
+
+// Hidden reports whether the picture is hidden.
+func (p *Picture) Hidden() bool {
+	return p.hidden
+}
+
 // ImageData returns the raw bytes of the embedded image.
 func (p *Picture) ImageData() ([]byte, error) {
 	if p == nil || p.docPart == nil || p.docPart.pkg == nil {
-		return nil, fmt.Errorf("picture is detached from document")
+		return nil, fmt.Errorf("%w: picture is detached from document", ErrNotAttached)
 	}
-	target := strings.TrimPrefix(p.target, "/")
-	for strings.HasPrefix(target, "../") {
-		target = strings.TrimPrefix(target, "../")
-	}
-	uri := target
-	if !strings.HasPrefix(uri, "word/") {
-		uri = path.Join("word", uri)
+	if p.relID == "" && p.IsLinked() {
+		return nil, fmt.Errorf("%w: picture is linked to external image %s", ErrPartNotFound, p.linkTarget)
 	}
+	uri := resolveRelationshipTarget(p.docPart.Part.URI, p.target)
 	part, ok := p.docPart.pkg.parts[uri]
 	if !ok {
-		return nil, fmt.Errorf("image part %s not found", uri)
+		return nil, fmt.Errorf("%w: image part %s not found", ErrPartNotFound, uri)
 	}
 	return part.Data, nil
 }
 
+// NativeSizeEMU returns the intrinsic size of the embedded image in EMUs, at the default
+// image resolution, regardless of the size the picture is displayed at. It lets callers
+// resize a reopened picture while preserving the image's aspect ratio.
+func (p *Picture) NativeSizeEMU() (int64, int64, error) {
+	data, err := p.ImageData()
+	if err != nil {
+		return 0, 0, err
+	}
+	return decodeImageDimensionsEMU(data)
+}
+
 func (p *Picture) toXML() string {
 	if p == nil {
 		return ""
@@ -112,13 +185,28 @@ func (p *Picture) toXML() string {
 	builder.WriteString(`<w:drawing>`)
 	builder.WriteString(`<wp:inline xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" distT="0" distB="0" distL="0" distR="0">`)
 	builder.WriteString(fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, p.widthEMU, p.heightEMU))
-	builder.WriteString(fmt.Sprintf(`<wp:docPr id="%d" name="%s" descr="%s"/>`, p.docPrID, escapeXML(name), escapeXML(descr)))
-	builder.WriteString(`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`)
+	hidden := ""
+	if p.hidden {
+		hidden = ` hidden="1"`
+	}
+	builder.WriteString(fmt.Sprintf(`<wp:docPr id="%d" name="%s" descr="%s"%s/>`, p.docPrID, escapeXML(name), escapeXML(descr), hidden))
+	if p.aspectUnlocked {
+		builder.WriteString(`<wp:cNvGraphicFramePr/>`)
+	} else {
+		builder.WriteString(`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`)
+	}
 	builder.WriteString(`<a:graphic>`)
 	builder.WriteString(`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`)
 	builder.WriteString(`<pic:pic>`)
 	builder.WriteString(`<pic:nvPicPr><pic:cNvPr id="0" name=""/><pic:cNvPicPr/></pic:nvPicPr>`)
-	builder.WriteString(`<pic:blipFill><a:blip r:embed="` + escapeXML(p.relID) + `"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`)
+	builder.WriteString(`<pic:blipFill><a:blip`)
+	if p.relID != "" {
+		builder.WriteString(` r:embed="` + escapeXML(p.relID) + `"`)
+	}
+	if p.linkRelID != "" {
+		builder.WriteString(` r:link="` + escapeXML(p.linkRelID) + `"`)
+	}
+	builder.WriteString(`/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`)
 	builder.WriteString(`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="`)
 	builder.WriteString(fmt.Sprintf("%d", p.widthEMU))
 	builder.WriteString(`" cy="`)
@@ -143,20 +231,37 @@ func escapeXML(value string) string {
 	return replacer.Replace(value)
 }
 
+// PictureOptions controls how an embedded picture is sized from its source image.
+type PictureOptions struct {
+	DPI int // resolution of the source image when deriving a size from its pixels, defaults to 96
+}
+
+func (o PictureOptions) dpi() int {
+	if o.DPI <= 0 {
+		return defaultImageDPI
+	}
+	return o.DPI
+}
+
 func decodeImageDimensionsEMU(data []byte) (int64, int64, error) {
+	return decodeImageDimensionsAtDPI(data, defaultImageDPI)
+}
+
+// decodeImageDimensionsAtDPI returns the size of the image in EMUs for a source that has
+// dpi pixels per inch
+func decodeImageDimensionsAtDPI(data []byte, dpi int) (int64, int64, error) {
 	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
 	}
 	if cfg.Width <= 0 || cfg.Height <= 0 {
-		return 0, 0, fmt.Errorf("invalid image dimensions")
+		return 0, 0, fmt.Errorf("%w: invalid image dimensions", ErrUnsupportedImage)
 	}
-	emusPerPixel := EMUsPerInch / defaultImageDPI
-	if emusPerPixel <= 0 {
-		emusPerPixel = 9525
+	if dpi <= 0 {
+		dpi = defaultImageDPI
 	}
-	widthEMU := int64(cfg.Width) * int64(emusPerPixel)
-	heightEMU := int64(cfg.Height) * int64(emusPerPixel)
+	widthEMU := int64(cfg.Width) * EMUsPerInch / int64(dpi)
+	heightEMU := int64(cfg.Height) * EMUsPerInch / int64(dpi)
 	return widthEMU, heightEMU, nil
 }
 
@@ -182,20 +287,63 @@ func PointsToEMU(points float64) int64 {
 	return int64(math.Round(points * float64(EMUsPerPoint)))
 }
 
-func (dp *DocumentPart) addPictureFromFile(path string, widthEMU, heightEMU int64) (*Picture, error) {
+func (dp *DocumentPart) addLinkedPicture(url string, widthEMU, heightEMU int64) (*Picture, error) {
 	if dp == nil || dp.pkg == nil {
-		return nil, fmt.Errorf("paragraph is not attached to a document package")
+		return nil, fmt.Errorf("%w: paragraph is not attached to a document package", ErrNotAttached)
+	}
+	if url == "" {
+		return nil, fmt.Errorf("%w: linked picture target must not be empty", ErrInvalidArgument)
+	}
+	if widthEMU <= 0 || heightEMU <= 0 {
+		return nil, fmt.Errorf("%w: linked picture width and height must be positive EMU values", ErrInvalidArgument)
+	}
+
+	relID := dp.pkg.ensureRelationshipWithMode(dp.Part.URI, RelTypeImage, url, "External")
+	docPrID := dp.nextDrawingID()
+	picture := &Picture{
+		docPart:     dp,
+		linkRelID:   relID,
+		linkTarget:  url,
+		widthEMU:    widthEMU,
+		heightEMU:   heightEMU,
+		docPrID:     docPrID,
+		name:        fmt.Sprintf("Picture %d", docPrID),
+		description: path.Base(url),
+	}
+	return picture, nil
+}
+
+func (dp *DocumentPart) addPictureFromFile(path string, widthEMU, heightEMU int64, opts PictureOptions) (*Picture, error) {
+	if dp == nil || dp.pkg == nil {
+		return nil, fmt.Errorf("%w: paragraph is not attached to a document package", ErrNotAttached)
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read image %s: %w", path, err)
 	}
+	return dp.addPictureFromBytes(data, filepath.Base(path), widthEMU, heightEMU, opts)
+}
+
+// addPictureFromBytes embeds image data whose format is given by the extension of name
+func (dp *DocumentPart) addPictureFromBytes(data []byte, name string, widthEMU, heightEMU int64, opts PictureOptions) (*Picture, error) {
+	if dp == nil || dp.pkg == nil {
+		return nil, fmt.Errorf("%w: paragraph is not attached to a document package", ErrNotAttached)
+	}
+	return dp.addPictureToPart(dp.Part.URI, data, name, widthEMU, heightEMU, opts)
+}
+
+// addPictureToPart embeds image data for a picture placed in the part at sourceURI, such as
+// a header or footer, whose relationships then reference the image
+func (dp *DocumentPart) addPictureToPart(sourceURI string, data []byte, name string, widthEMU, heightEMU int64, opts PictureOptions) (*Picture, error) {
+	if dp == nil || dp.pkg == nil {
+		return nil, fmt.Errorf("%w: paragraph is not attached to a document package", ErrNotAttached)
+	}
 
-	ext := strings.ToLower(filepath.Ext(path))
+	ext := strings.ToLower(filepath.Ext(name))
 	contentType, ok := imageContentTypes[ext]
 	if !ok {
-		return nil, fmt.Errorf("unsupported image format: %s", ext)
+		return nil, fmt.Errorf("%w: unsupported image format: %s", ErrUnsupportedImage, ext)
 	}
 
 	var (
@@ -205,7 +353,7 @@ func (dp *DocumentPart) addPictureFromFile(path string, widthEMU, heightEMU int6
 	)
 
 	if widthEMU <= 0 || heightEMU <= 0 {
-		defaultWidthEMU, defaultHeightEMU, dimErr = decodeImageDimensionsEMU(data)
+		defaultWidthEMU, defaultHeightEMU, dimErr = decodeImageDimensionsAtDPI(data, opts.dpi())
 	}
 
 	switch {
@@ -217,18 +365,18 @@ func (dp *DocumentPart) addPictureFromFile(path string, widthEMU, heightEMU int6
 		heightEMU = defaultHeightEMU
 	case widthEMU <= 0:
 		if dimErr != nil || defaultWidthEMU == 0 || defaultHeightEMU == 0 {
-			return nil, fmt.Errorf("unable to determine picture width automatically; specify both width and height")
+			return nil, fmt.Errorf("%w: unable to determine picture width automatically; specify both width and height", ErrInvalidArgument)
 		}
 		widthEMU = scaleEMU(heightEMU, defaultWidthEMU, defaultHeightEMU)
 	case heightEMU <= 0:
 		if dimErr != nil || defaultWidthEMU == 0 || defaultHeightEMU == 0 {
-			return nil, fmt.Errorf("unable to determine picture height automatically; specify both width and height")
+			return nil, fmt.Errorf("%w: unable to determine picture height automatically; specify both width and height", ErrInvalidArgument)
 		}
 		heightEMU = scaleEMU(widthEMU, defaultHeightEMU, defaultWidthEMU)
 	}
 
 	if widthEMU <= 0 || heightEMU <= 0 {
-		return nil, fmt.Errorf("picture width and height must be positive EMU values")
+		return nil, fmt.Errorf("%w: picture width and height must be positive EMU values", ErrInvalidArgument)
 	}
 
 	partURI, err := dp.pkg.addImagePart(data, ext, contentType)
@@ -236,10 +384,10 @@ func (dp *DocumentPart) addPictureFromFile(path string, widthEMU, heightEMU int6
 		return nil, err
 	}
 	target := strings.TrimPrefix(partURI, "word/")
-	relID := dp.pkg.ensureRelationship(dp.Part.URI, RelTypeImage, target)
+	relID := dp.pkg.ensureRelationship(sourceURI, RelTypeImage, target)
 	docPrID := dp.nextDrawingID()
 
-	base := strings.TrimSuffix(filepath.Base(path), ext)
+	base := strings.TrimSuffix(filepath.Base(name), ext)
 	picture := &Picture{
 		docPart:     dp,
 		relID:       relID,
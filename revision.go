@@ -0,0 +1,71 @@
+package docx
+
+import (
+	"fmt"
+	"time"
+)
+
+// RevisionType identifies the kind of tracked change applied to a run.
+type RevisionType string
+
+const (
+	RevisionInsertion RevisionType = "ins"
+	RevisionDeletion  RevisionType = "del"
+)
+
+// Revision describes a tracked change (w:ins or w:del) wrapping a run.
+type Revision struct {
+	Type   RevisionType
+	ID     int
+	Author string
+	Date   time.Time
+}
+
+// SetRevision marks the run as a tracked insertion or deletion made by author at date.
+// Pass a zero date to omit the timestamp.
+func (r *Run) SetRevision(revType RevisionType, author string, date time.Time) {
+	id := 0
+	if r.owner != nil {
+		id = r.owner.nextRevisionID()
+	}
+	r.revision = &Revision{Type: revType, ID: id, Author: author, Date: date}
+}
+
+// Revision returns the tracked change information for the run, if any.
+func (r *Run) Revision() (*Revision, bool) {
+	if r.revision == nil {
+		return nil, false
+	}
+	return r.revision, true
+}
+
+// ClearRevision removes the tracked change marker, turning the run into regular content.
+func (r *Run) ClearRevision() {
+	r.revision = nil
+}
+
+// IsInsertion reports whether the run is a tracked insertion.
+func (r *Run) IsInsertion() bool {
+	return r.revision != nil && r.revision.Type == RevisionInsertion
+}
+
+// IsDeletion reports whether the run is a tracked deletion.
+func (r *Run) IsDeletion() bool {
+	return r.revision != nil && r.revision.Type == RevisionDeletion
+}
+
+func (rev *Revision) wrap(content string) string {
+	attrs := fmt.Sprintf(` w:id="%d"`, rev.ID)
+	if rev.Author != "" {
+		attrs += fmt.Sprintf(` w:author="%s"`, escapeXML(rev.Author))
+	}
+	if !rev.Date.IsZero() {
+		attrs += fmt.Sprintf(` w:date="%s"`, rev.Date.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf(`<w:%s%s>%s</w:%s>`, rev.Type, attrs, content, rev.Type)
+}
+
+func (dp *DocumentPart) nextRevisionID() int {
+	dp.revisionCounter++
+	return dp.revisionCounter
+}
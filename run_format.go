@@ -0,0 +1,168 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// RunFormat describes run formatting declaratively, e.g. when it comes from configuration.
+// Nil fields are left unchanged by ApplyFormat.
+type RunFormat struct {
+	Bold          *bool
+	Italic        *bool
+	Underline     *WDUnderline
+	Strikethrough *bool
+	SmallCaps     *bool
+	AllCaps       *bool
+	Size          *int     // font size in points
+	SizePoints    *float64 // font size including half points (e.g. 10.5); overrides Size
+	Color         *string
+	Font          *string
+	Highlight     *WDColorIndex
+	NoProof       *bool
+}
+
+// ApplyFormat sets every non-nil field of format on the run
+func (r *Run) ApplyFormat(format RunFormat) {
+	if format.Bold != nil {
+		r.SetBold(*format.Bold)
+	}
+	if format.Italic != nil {
+		r.SetItalic(*format.Italic)
+	}
+	if format.Underline != nil {
+		r.SetUnderline(*format.Underline)
+	}
+	if format.Strikethrough != nil {
+		r.SetStrikethrough(*format.Strikethrough)
+	}
+	if format.SmallCaps != nil {
+		r.SetSmallCaps(*format.SmallCaps)
+	}
+	if format.AllCaps != nil {
+		r.SetAllCaps(*format.AllCaps)
+	}
+	if format.Size != nil {
+		r.SetSize(*format.Size)
+	}
+	if format.SizePoints != nil {
+		r.SetSizePoints(*format.SizePoints)
+	}
+	if format.Color != nil {
+		r.SetColor(*format.Color)
+	}
+	if format.Font != nil {
+		r.SetFont(*format.Font)
+	}
+	if format.Highlight != nil {
+		r.SetHighlight(*format.Highlight)
+	}
+	if format.NoProof != nil {
+		r.SetNoProof(*format.NoProof)
+	}
+}
+
+// AddRunWithFormat creates a new run with the specified text and applies format to it
+func (p *Paragraph) AddRunWithFormat(text string, format RunFormat) *Run {
+	run := p.AddRun(text)
+	run.ApplyFormat(format)
+	return run
+}
+
+// SetMarkFormat sets the run properties of the paragraph mark (w:pPr/w:rPr), replacing any
+// read from the document. The mark formatting applies to the pilcrow and determines the
+// height of an empty paragraph, e.g. a small font size keeps a spacer paragraph thin.
+func (p *Paragraph) SetMarkFormat(format RunFormat) {
+	run := NewRun("")
+	run.ApplyFormat(format)
+	p.markRunProperties = p.markRunProperties[:0]
+	if rPr := run.propertiesXML(); rPr != "" {
+		p.markRunProperties = append(p.markRunProperties, rPr)
+	}
+}
+
+// MarkFormat returns the paragraph mark formatting expressible as a RunFormat; ok is false
+// when the paragraph mark has no run properties
+func (p *Paragraph) MarkFormat() (format RunFormat, ok bool) {
+	if len(p.markRunProperties) == 0 {
+		return RunFormat{}, false
+	}
+	markup := `<w:p xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:r>` + strings.Join(p.markRunProperties, "") + `</w:r></w:p>`
+	decoder := xml.NewDecoder(strings.NewReader(markup))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return RunFormat{}, false
+		}
+		if start, isStart := tok.(xml.StartElement); isStart {
+			parsed, err := parseParagraph(decoder, start, nil)
+			if err != nil || len(parsed.runs) == 0 {
+				return RunFormat{}, false
+			}
+			return parsed.runs[0].format(), true
+		}
+	}
+}
+
+// ClearMarkFormat removes the run properties of the paragraph mark
+func (p *Paragraph) ClearMarkFormat() {
+	p.markRunProperties = p.markRunProperties[:0]
+}
+
+// FormatEquals reports whether the two runs have the same character formatting, i.e. they
+// would serialize the same run properties. Text, hyperlinks and content such as pictures
+// or breaks are not compared.
+func (r *Run) FormatEquals(other *Run) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	return r.propertiesXML() == other.propertiesXML()
+}
+
+// Equals reports whether the two runs have the same text and formatting
+func (r *Run) Equals(other *Run) bool {
+	return r.FormatEquals(other) && (r == nil || r.text == other.text)
+}
+
+// format returns the run's formatting as a RunFormat, leaving unset properties nil
+func (r *Run) format() RunFormat {
+	var format RunFormat
+	if r.bold {
+		format.Bold = boolPtr(true)
+	}
+	if r.italic {
+		format.Italic = boolPtr(true)
+	}
+	if r.underline != WDUnderlineNone {
+		underline := r.underline
+		format.Underline = &underline
+	}
+	if r.strike {
+		format.Strikethrough = boolPtr(true)
+	}
+	format.SmallCaps = r.smallCaps
+	format.AllCaps = r.allCaps
+	if r.sizeSet {
+		format.Size = intPtr(r.Size())
+		if r.size%2 != 0 {
+			points := r.SizePoints()
+			format.SizePoints = &points
+		}
+	}
+	if r.colorSet {
+		color := r.color
+		format.Color = &color
+	}
+	if r.fontSet {
+		font := r.font
+		format.Font = &font
+	}
+	if r.highlightSet {
+		highlight := r.highlight
+		format.Highlight = &highlight
+	}
+	if r.noProof {
+		format.NoProof = boolPtr(true)
+	}
+	return format
+}
@@ -2,6 +2,7 @@ package docx
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 )
@@ -21,6 +22,19 @@ type Section struct {
 	// orientation is the explicit WordprocessingML orientation attribute ("portrait"|"landscape").
 	// If empty, it will be inferred from pageWidth/pageHeight when serializing.
 	orientation string
+	// docGrid holds the document grid (w:docGrid) used by East Asian layouts.
+	docGridType      string
+	docGridLinePitch int
+	docGridCharSpace int
+	docGridSet       bool
+	// columnCount and columnSpace describe evenly spaced text columns (w:cols); a count of
+	// zero or one means a single column.
+	columnCount int
+	columnSpace int // in twentieths of a point
+	// pageNumberFormat and pageNumberStart are the w:pgNumType format and restart value; a
+	// nil start continues the numbering of the previous section.
+	pageNumberFormat string
+	pageNumberStart  *int
 }
 
 // NewSection creates a new section with the specified start type
@@ -42,6 +56,37 @@ func (s *Section) setOwner(owner *DocumentPart) {
 	s.owner = owner
 }
 
+// copyLayout returns a new section with the given start type that shares this section's
+// page size, margins, orientation, columns, document grid, page number format and
+// header/footer references.
+func (s *Section) copyLayout(startType SectionStartType) *Section {
+	section := NewSection(startType)
+	section.owner = s.owner
+	section.pageWidth = s.pageWidth
+	section.pageHeight = s.pageHeight
+	section.marginTop = s.marginTop
+	section.marginRight = s.marginRight
+	section.marginBottom = s.marginBottom
+	section.marginLeft = s.marginLeft
+	section.orientation = s.orientation
+	section.docGridType = s.docGridType
+	section.docGridLinePitch = s.docGridLinePitch
+	section.docGridCharSpace = s.docGridCharSpace
+	section.docGridSet = s.docGridSet
+	section.columnCount = s.columnCount
+	section.columnSpace = s.columnSpace
+	section.pageNumberFormat = s.pageNumberFormat
+	for key, ref := range s.headerRefs {
+		copy := *ref
+		section.headerRefs[key] = &copy
+	}
+	for key, ref := range s.footerRefs {
+		copy := *ref
+		section.footerRefs[key] = &copy
+	}
+	return section
+}
+
 // SetPageSize sets the page size in twentieths of a point
 func (s *Section) SetPageSize(width, height int) {
 	s.pageWidth = width
@@ -56,6 +101,92 @@ func (s *Section) SetMargins(top, right, bottom, left int) {
 	s.marginLeft = left
 }
 
+// inchesToTwips converts inches to twentieths of a point
+func inchesToTwips(inches float64) int {
+	return int(math.Round(inches * 1440))
+}
+
+// SetDocGrid configures the document grid used to align East Asian text. gridType is
+// "default", "lines", "linesAndChars" or "snapToChars"; linePitch is the line pitch in
+// twentieths of a point and charSpace the character spacing adjustment. Zero values are
+// omitted.
+func (s *Section) SetDocGrid(gridType string, linePitch, charSpace int) {
+	s.docGridType = gridType
+	s.docGridLinePitch = linePitch
+	s.docGridCharSpace = charSpace
+	s.docGridSet = true
+}
+
+// DocGrid returns the document grid settings if present.
+func (s *Section) DocGrid() (gridType string, linePitch, charSpace int, ok bool) {
+	if !s.docGridSet {
+		return "", 0, 0, false
+	}
+	return s.docGridType, s.docGridLinePitch, s.docGridCharSpace, true
+}
+
+// ClearDocGrid removes the document grid settings.
+func (s *Section) ClearDocGrid() {
+	s.docGridType = ""
+	s.docGridLinePitch = 0
+	s.docGridCharSpace = 0
+	s.docGridSet = false
+}
+
+// SetColumns lays the section's text out in count evenly sized columns separated by space
+// twentieths of a point. A count of one or less restores a single column.
+func (s *Section) SetColumns(count, space int) {
+	if count <= 1 {
+		s.columnCount = 0
+		s.columnSpace = 0
+		return
+	}
+	if space < 0 {
+		space = 0
+	}
+	s.columnCount = count
+	s.columnSpace = space
+}
+
+// Columns returns the number of text columns and the space between them. A single-column
+// section reports a count of 1.
+func (s *Section) Columns() (count, space int) {
+	if s.columnCount <= 1 {
+		return 1, s.columnSpace
+	}
+	return s.columnCount, s.columnSpace
+}
+
+// SetPageNumberFormat sets how page numbers in this section are displayed (a w:numFmt value
+// such as "decimal", "lowerRoman" or "upperLetter"; empty keeps Word's default) and the
+// number of the section's first page. A negative start continues the numbering of the
+// previous section, e.g. for a roman-numbered front matter followed by a body restarting
+// at 1.
+func (s *Section) SetPageNumberFormat(format string, start int) {
+	s.pageNumberFormat = format
+	if start < 0 {
+		s.pageNumberStart = nil
+		return
+	}
+	s.pageNumberStart = &start
+}
+
+// PageNumberFormat returns the page number format and start value of the section. start is
+// -1 when the numbering continues from the previous section; ok is false when neither is set.
+func (s *Section) PageNumberFormat() (format string, start int, ok bool) {
+	start = -1
+	if s.pageNumberStart != nil {
+		start = *s.pageNumberStart
+	}
+	return s.pageNumberFormat, start, s.pageNumberFormat != "" || s.pageNumberStart != nil
+}
+
+// ClearPageNumberFormat removes the page number format and start value
+func (s *Section) ClearPageNumberFormat() {
+	s.pageNumberFormat = ""
+	s.pageNumberStart = nil
+}
+
 // SetStartType sets how this section starts
 func (s *Section) SetStartType(startType SectionStartType) {
 	s.startType = startType
@@ -83,13 +214,13 @@ func (s *Section) FooterOfType(footerType FooterType) (*Footer, error) {
 
 func (s *Section) headerOfType(headerType HeaderType) (*Header, error) {
 	if s == nil {
-		return nil, fmt.Errorf("section is nil")
+		return nil, fmt.Errorf("%w: section is nil", ErrInvalidArgument)
 	}
 	if ref, ok := s.headerRefs[headerType]; ok && ref != nil {
 		return ref.header, nil
 	}
 	if s.owner == nil {
-		return nil, fmt.Errorf("section has no owner document part")
+		return nil, fmt.Errorf("%w: section has no owner document part", ErrNotAttached)
 	}
 	header, relID, err := s.owner.createHeaderPart()
 	if err != nil {
@@ -107,13 +238,13 @@ func (s *Section) headerOfType(headerType HeaderType) (*Header, error) {
 
 func (s *Section) footerOfType(footerType FooterType) (*Footer, error) {
 	if s == nil {
-		return nil, fmt.Errorf("section is nil")
+		return nil, fmt.Errorf("%w: section is nil", ErrInvalidArgument)
 	}
 	if ref, ok := s.footerRefs[footerType]; ok && ref != nil {
 		return ref.footer, nil
 	}
 	if s.owner == nil {
-		return nil, fmt.Errorf("section has no owner document part")
+		return nil, fmt.Errorf("%w: section has no owner document part", ErrNotAttached)
 	}
 	footer, relID, err := s.owner.createFooterPart()
 	if err != nil {
@@ -194,15 +325,16 @@ func (s *Section) footerReferenceElements() []string {
 // ToXML converts the section to WordprocessingML XML
 func (s *Section) ToXML() string {
 	var elements []string
-	if s.startType != SectionStartContinuous {
-		elements = append(elements, fmt.Sprintf(`<w:type w:val="%s"/>`, s.startType))
-	}
+	// Header and footer references lead the section properties; w:type must follow them.
 	if headerElems := s.headerReferenceElements(); len(headerElems) > 0 {
 		elements = append(elements, headerElems...)
 	}
 	if footerElems := s.footerReferenceElements(); len(footerElems) > 0 {
 		elements = append(elements, footerElems...)
 	}
+	if s.startType != SectionStartContinuous {
+		elements = append(elements, fmt.Sprintf(`<w:type w:val="%s"/>`, s.startType))
+	}
 	// Emit explicit orientation when set, otherwise infer from page size.
 	orient := ""
 	if s.orientation != "" {
@@ -212,10 +344,45 @@ func (s *Section) ToXML() string {
 	}
 	elements = append(elements, fmt.Sprintf(`<w:pgSz w:w="%d" w:h="%d"%s/>`, s.pageWidth, s.pageHeight, orient))
 	elements = append(elements, fmt.Sprintf(`<w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d"/>`, s.marginTop, s.marginRight, s.marginBottom, s.marginLeft))
+	if s.pageNumberFormat != "" || s.pageNumberStart != nil {
+		elements = append(elements, s.pageNumberTypeXML())
+	}
+	if s.columnCount > 1 {
+		elements = append(elements, fmt.Sprintf(`<w:cols w:space="%d" w:num="%d"/>`, s.columnSpace, s.columnCount))
+	}
+	if s.docGridSet {
+		elements = append(elements, s.docGridXML())
+	}
 
-	return fmt.Sprintf(`<w:sectPr>
-  %s
-</w:sectPr>`, strings.Join(elements, "\n  "))
+	return "<w:sectPr>" + strings.Join(elements, "") + "</w:sectPr>"
+}
+
+func (s *Section) pageNumberTypeXML() string {
+	attrs := make([]string, 0, 2)
+	if s.pageNumberFormat != "" {
+		attrs = append(attrs, fmt.Sprintf(`w:fmt="%s"`, xmlEscapeAttribute(s.pageNumberFormat)))
+	}
+	if s.pageNumberStart != nil {
+		attrs = append(attrs, fmt.Sprintf(`w:start="%d"`, *s.pageNumberStart))
+	}
+	return fmt.Sprintf(`<w:pgNumType %s/>`, strings.Join(attrs, " "))
+}
+
+func (s *Section) docGridXML() string {
+	attrs := make([]string, 0, 3)
+	if s.docGridType != "" {
+		attrs = append(attrs, fmt.Sprintf(`w:type="%s"`, xmlEscapeAttribute(s.docGridType)))
+	}
+	if s.docGridLinePitch != 0 {
+		attrs = append(attrs, fmt.Sprintf(`w:linePitch="%d"`, s.docGridLinePitch))
+	}
+	if s.docGridCharSpace != 0 {
+		attrs = append(attrs, fmt.Sprintf(`w:charSpace="%d"`, s.docGridCharSpace))
+	}
+	if len(attrs) == 0 {
+		return `<w:docGrid/>`
+	}
+	return fmt.Sprintf(`<w:docGrid %s/>`, strings.Join(attrs, " "))
 }
 
 // Comments represents a collection of comments in a document
@@ -257,8 +424,19 @@ func (c *Comments) AddComment(text, author, initials string) *Comment {
 
 // Settings represents document settings
 type Settings struct {
-	defaultTabStop int
-	zoom           int
+	defaultTabStop  int
+	zoom            int
+	autoHyphenation bool
+	hyphenationZone int
+
+	// defaultTabStopSet and zoomSet mark values set explicitly; otherwise the elements
+	// already in the settings part are kept as they are
+	defaultTabStopSet bool
+	zoomSet           bool
+
+	noPunctuationKerning   bool
+	strictKinsoku          bool
+	displayBackgroundShape bool
 }
 
 // NewSettings creates new document settings
@@ -272,11 +450,70 @@ func NewSettings() *Settings {
 // SetDefaultTabStop sets the default tab stop in twentieths of a point
 func (s *Settings) SetDefaultTabStop(tabStop int) {
 	s.defaultTabStop = tabStop
+	s.defaultTabStopSet = true
 }
 
-// SetZoom sets the zoom percentage
+// SetZoom sets the zoom percentage, replacing a preset zoom such as best fit
 func (s *Settings) SetZoom(zoom int) {
 	s.zoom = zoom
+	s.zoomSet = true
+}
+
+// SetAutoHyphenation turns automatic hyphenation of the document on or off
+func (s *Settings) SetAutoHyphenation(enabled bool) {
+	s.autoHyphenation = enabled
+}
+
+// AutoHyphenation reports whether the document is hyphenated automatically
+func (s *Settings) AutoHyphenation() bool {
+	return s.autoHyphenation
+}
+
+// SetHyphenationZone sets the distance from the right margin, in twentieths of a point,
+// within which words are hyphenated. Zero leaves the application default.
+func (s *Settings) SetHyphenationZone(twips int) {
+	if twips < 0 {
+		twips = 0
+	}
+	s.hyphenationZone = twips
+}
+
+// HyphenationZone returns the hyphenation zone in twentieths of a point, or 0 when unset
+func (s *Settings) HyphenationZone() int {
+	return s.hyphenationZone
+}
+
+// SetNoPunctuationKerning turns off kerning of punctuation characters in East Asian text
+func (s *Settings) SetNoPunctuationKerning(enabled bool) {
+	s.noPunctuationKerning = enabled
+}
+
+// NoPunctuationKerning reports whether punctuation kerning is turned off
+func (s *Settings) NoPunctuationKerning() bool {
+	return s.noPunctuationKerning
+}
+
+// SetKinsoku applies the strict kinsoku (line breaking) rules for Japanese text, which
+// forbid more characters from starting or ending a line (w:strictFirstAndLastChars).
+// Per-paragraph kinsoku is on by default in Word and is not affected.
+func (s *Settings) SetKinsoku(strict bool) {
+	s.strictKinsoku = strict
+}
+
+// Kinsoku reports whether the strict kinsoku rules are applied
+func (s *Settings) Kinsoku() bool {
+	return s.strictKinsoku
+}
+
+// SetDisplayBackgroundShape turns on or off showing the page background in print layout
+// (w:displayBackgroundShape). Word ignores the page color while it is off.
+func (s *Settings) SetDisplayBackgroundShape(enabled bool) {
+	s.displayBackgroundShape = enabled
+}
+
+// DisplayBackgroundShape reports whether the page background is shown
+func (s *Settings) DisplayBackgroundShape() bool {
+	return s.displayBackgroundShape
 }
 
 // Styles represents a collection of document styles
@@ -0,0 +1,297 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+)
+
+// settingsElementOrder lists the children of w:settings in schema order. It is used to
+// place elements the library writes among those already present in the part.
+var settingsElementOrder = []string{
+	"writeProtection", "view", "zoom", "removePersonalInformation", "removeDateAndTime",
+	"doNotDisplayPageBoundaries", "displayBackgroundShape", "printPostScriptOverText",
+	"printFractionalCharacterWidth", "printFormsData", "embedTrueTypeFonts", "embedSystemFonts",
+	"saveSubsetFonts", "saveFormsData", "mirrorMargins", "alignBordersAndEdges",
+	"bordersDoNotSurroundHeader", "bordersDoNotSurroundFooter", "gutterAtTop",
+	"hideSpellingErrors", "hideGrammaticalErrors", "activeWritingStyle", "proofState",
+	"formsDesign", "attachedTemplate", "linkStyles", "stylePaneFormatFilter",
+	"stylePaneSortMethod", "documentType", "mailMerge", "revisionView", "trackRevisions",
+	"doNotTrackMoves", "doNotTrackFormatting", "documentProtection", "autoFormatOverride",
+	"styleLockTheme", "styleLockQFSet", "defaultTabStop", "autoHyphenation",
+	"consecutiveHyphenLimit", "hyphenationZone", "doNotHyphenateCaps", "showEnvelope",
+	"summaryLength", "clickAndTypeStyle", "defaultTableStyle", "evenAndOddHeaders",
+	"bookFoldRevPrinting", "bookFold", "bookFoldPrinting", "drawingGridHorizontalSpacing",
+	"drawingGridVerticalSpacing", "displayHorizontalDrawingGridEvery",
+	"displayVerticalDrawingGridEvery", "doNotUseMarginsForDrawingGridOrigin",
+	"drawingGridHorizontalOrigin", "drawingGridVerticalOrigin", "doNotShadeFormData",
+	"noPunctuationKerning", "characterSpacingControl", "printTwoOnOne",
+	"strictFirstAndLastChars", "noLineBreaksAfter", "noLineBreaksBefore", "savePreviewPicture",
+	"doNotValidateAgainstSchema", "saveInvalidXml", "ignoreMixedContent",
+	"alwaysShowPlaceholderText", "doNotDemarcateInvalidXml", "saveXmlDataOnly",
+	"useXSLTWhenSaving", "saveThroughXslt", "showXMLTags", "alwaysMergeEmptyNamespace",
+	"updateFields", "hdrShapeDefaults", "footnotePr", "endnotePr", "compat", "docVars",
+	"rsids", "mathPr", "attachedSchema", "themeFontLang", "clrSchemeMapping",
+	"doNotIncludeSubdocsInStats", "doNotAutoCompressPictures", "forceUpgrade", "captions",
+	"readModeInkLockDown", "smartTagType", "schemaLibrary", "shapeDefaults",
+	"doNotEmbedSmartTags", "decimalSymbol", "listSeparator",
+}
+
+// settingsElement is a w:settings child managed by Settings. An empty markup removes the
+// element from the part.
+type settingsElement struct {
+	name   string
+	markup string
+}
+
+// elements returns the settings children written on save, using prefix for the
+// WordprocessingML namespace. Zoom and the default tab stop are only written once set, so
+// the elements of an opened part keep their attributes.
+func (s *Settings) elements(prefix string) []settingsElement {
+	tag := func(name, attrs string) string {
+		return "<" + prefix + name + attrs + "/>"
+	}
+	val := func(value int) string {
+		return " " + prefix + `val="` + strconv.Itoa(value) + `"`
+	}
+
+	flag := func(name string, enabled bool) settingsElement {
+		if !enabled {
+			return settingsElement{name: name}
+		}
+		return settingsElement{name: name, markup: tag(name, "")}
+	}
+
+	hyphenationZone := settingsElement{name: "hyphenationZone"}
+	if s.hyphenationZone > 0 {
+		hyphenationZone.markup = tag("hyphenationZone", val(s.hyphenationZone))
+	}
+	elements := []settingsElement{
+		flag("displayBackgroundShape", s.displayBackgroundShape),
+		flag("autoHyphenation", s.autoHyphenation),
+		hyphenationZone,
+		flag("noPunctuationKerning", s.noPunctuationKerning),
+		flag("strictFirstAndLastChars", s.strictKinsoku),
+	}
+	if s.zoomSet {
+		elements = append(elements, settingsElement{name: "zoom", markup: tag("zoom", " "+prefix+`percent="`+strconv.Itoa(s.zoom)+`"`)})
+	}
+	if s.defaultTabStopSet {
+		elements = append(elements, settingsElement{name: "defaultTabStop", markup: tag("defaultTabStop", val(s.defaultTabStop))})
+	}
+	return elements
+}
+
+// parseSettings reads the values modelled by Settings from a settings part
+func parseSettings(data []byte) (*Settings, error) {
+	var parsed struct {
+		Zoom *struct {
+			Percent string `xml:"percent,attr"`
+		} `xml:"zoom"`
+		DefaultTabStop *struct {
+			Val string `xml:"val,attr"`
+		} `xml:"defaultTabStop"`
+		DisplayBackground    *onOffElement `xml:"displayBackgroundShape"`
+		AutoHyphenation      *onOffElement `xml:"autoHyphenation"`
+		NoPunctuationKerning *onOffElement `xml:"noPunctuationKerning"`
+		StrictKinsoku        *onOffElement `xml:"strictFirstAndLastChars"`
+		HyphenationZone      *struct {
+			Val string `xml:"val,attr"`
+		} `xml:"hyphenationZone"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+
+	settings := NewSettings()
+	if parsed.Zoom != nil {
+		if zoom, err := strconv.Atoi(parsed.Zoom.Percent); err == nil {
+			settings.zoom = zoom
+		}
+	}
+	if parsed.DefaultTabStop != nil {
+		if tabStop, err := strconv.Atoi(parsed.DefaultTabStop.Val); err == nil {
+			settings.defaultTabStop = tabStop
+		}
+	}
+	settings.displayBackgroundShape = parsed.DisplayBackground.enabled()
+	settings.autoHyphenation = parsed.AutoHyphenation.enabled()
+	settings.noPunctuationKerning = parsed.NoPunctuationKerning.enabled()
+	settings.strictKinsoku = parsed.StrictKinsoku.enabled()
+	if parsed.HyphenationZone != nil {
+		if zone, err := strconv.Atoi(parsed.HyphenationZone.Val); err == nil {
+			settings.hyphenationZone = zone
+		}
+	}
+	return settings, nil
+}
+
+// onOffElement unmarshals a CT_OnOff settings element
+type onOffElement struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+}
+
+func (e *onOffElement) enabled() bool {
+	if e == nil {
+		return false
+	}
+	value := parseOnOff(e.Attrs)
+	return value != nil && *value
+}
+
+// applySettings rewrites the managed children of a settings part, keeping every other
+// element byte for byte
+func applySettings(data []byte, settings *Settings) ([]byte, error) {
+	type child struct {
+		name       string
+		start, end int64
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		children     []child
+		prefix       string
+		contentStart int64 = -1
+		contentEnd   int64 = -1
+		depth        int
+		childStart   int64
+		childName    string
+	)
+	for {
+		offset := decoder.InputOffset()
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Space != "" {
+					prefix = t.Name.Space + ":"
+				}
+				contentStart = decoder.InputOffset()
+			} else if depth == 1 {
+				childStart, childName = offset, t.Name.Local
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 1 {
+				children = append(children, child{name: childName, start: childStart, end: decoder.InputOffset()})
+			} else if depth == 0 {
+				contentEnd = offset
+			}
+		}
+	}
+	if contentStart < 0 || contentEnd < contentStart {
+		return nil, errors.New("settings part has no root element")
+	}
+	if contentEnd == contentStart && bytes.HasSuffix(bytes.TrimSpace(data[:contentStart]), []byte("/>")) {
+		return nil, errors.New("settings part has an empty root element")
+	}
+
+	orderIndex := make(map[string]int, len(settingsElementOrder))
+	for i, name := range settingsElementOrder {
+		orderIndex[name] = i
+	}
+	managed := make(map[string]string)
+	for _, element := range settings.elements(prefix) {
+		managed[element.name] = element.markup
+	}
+
+	var content [][]byte
+	var names []string
+	written := make(map[string]bool)
+	for _, c := range children {
+		markup, ok := managed[c.name]
+		if !ok {
+			content = append(content, data[c.start:c.end])
+			names = append(names, c.name)
+			continue
+		}
+		if written[c.name] || markup == "" {
+			continue
+		}
+		written[c.name] = true
+		content = append(content, []byte(markup))
+		names = append(names, c.name)
+	}
+	for _, element := range settings.elements(prefix) {
+		if written[element.name] || element.markup == "" {
+			continue
+		}
+		position := len(content)
+		for i, name := range names {
+			if index, known := orderIndex[name]; known && index > orderIndex[element.name] {
+				position = i
+				break
+			}
+		}
+		content = append(content[:position], append([][]byte{[]byte(element.markup)}, content[position:]...)...)
+		names = append(names[:position], append([]string{element.name}, names[position:]...)...)
+	}
+
+	var result bytes.Buffer
+	result.Write(data[:contentStart])
+	for _, markup := range content {
+		result.Write(markup)
+	}
+	result.Write(data[contentEnd:])
+	return result.Bytes(), nil
+}
+
+func (p *Package) settingsURI(docURI string) string {
+	for _, rel := range p.relations[docURI] {
+		if rel.Type == RelTypeSettings {
+			return resolveRelationshipTarget(docURI, rel.Target)
+		}
+	}
+	return path.Join(path.Dir(docURI), "settings.xml")
+}
+
+// loadSettings reads the document settings, falling back to defaults when the part is
+// missing or unreadable
+func loadSettings(pkg *Package, docPart *DocumentPart) *Settings {
+	if docPart == nil {
+		return NewSettings()
+	}
+	part, ok := pkg.parts[pkg.settingsURI(docPart.Part.URI)]
+	if !ok || len(part.Data) == 0 {
+		return NewSettings()
+	}
+	settings, err := parseSettings(part.Data)
+	if err != nil {
+		return NewSettings()
+	}
+	return settings
+}
+
+// updateSettingsPart writes the document settings into the settings part, creating the
+// part when the package has none
+func (d *Document) updateSettingsPart() error {
+	if d.settings == nil || d.docPart == nil {
+		return nil
+	}
+	uri := d.pkg.settingsURI(d.docPart.Part.URI)
+	part, ok := d.pkg.parts[uri]
+	if !ok {
+		part = NewSettingsPart().Part
+		part.URI = uri
+		d.pkg.parts[uri] = part
+		d.pkg.contentTypes["/"+uri] = ContentTypeWMLSettings
+		d.pkg.ensureRelationship(d.docPart.Part.URI, RelTypeSettings, path.Base(uri))
+	}
+	data, err := applySettings(part.Data, d.settings)
+	if err != nil {
+		return fmt.Errorf("failed to update settings part %s: %w", uri, err)
+	}
+	part.Data = data
+	return nil
+}
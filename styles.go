@@ -0,0 +1,236 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// StyleNames returns the IDs of the paragraph styles defined in the document's styles part,
+// in definition order. These are the values SetStyle and HasStyle take (e.g. "Heading1"),
+// not the display names shown in Word (e.g. "heading 1").
+func (d *Document) StyleNames() ([]string, error) {
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, style := range styles {
+		if style.Type == "paragraph" {
+			names = append(names, style.ID)
+		}
+	}
+	return names, nil
+}
+
+// HasStyle reports whether the styles part defines a style with the given ID (the value
+// passed to SetStyle, e.g. "Heading1"), of any type. A styles part that cannot be parsed
+// defines no styles; StyleNames reports the parse error.
+func (d *Document) HasStyle(id string) bool {
+	styles, _ := d.styleDefinitions()
+	for _, style := range styles {
+		if style.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) styleDefinitions() ([]*Style, error) {
+	if d.docPart == nil {
+		return nil, fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	part, ok := d.pkg.parts[d.pkg.stylesURI(d.docPart.Part.URI)]
+	if !ok || len(part.Data) == 0 {
+		return nil, nil
+	}
+	return parseStyleDefinitions(part.Data)
+}
+
+func (p *Package) stylesURI(docURI string) string {
+	for _, rel := range p.relations[docURI] {
+		if rel.Type == RelTypeStyles {
+			return resolveRelationshipTarget(docURI, rel.Target)
+		}
+	}
+	return "word/styles.xml"
+}
+
+func parseStyleDefinitions(data []byte) ([]*Style, error) {
+	var parsed struct {
+		Styles []struct {
+			Type string `xml:"type,attr"`
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+		} `xml:"style"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse styles: %w", err)
+	}
+
+	styles := make([]*Style, 0, len(parsed.Styles))
+	for _, style := range parsed.Styles {
+		styles = append(styles, &Style{ID: style.ID, Name: style.Name.Val, Type: style.Type})
+	}
+	return styles, nil
+}
+
+// paragraphLayout holds the paragraph properties resolved from styles; nil fields are not
+// specified.
+type paragraphLayout struct {
+	alignment *WDAlignParagraph
+	before    *int
+	after     *int
+	line      *int
+	lineRule  *string
+}
+
+// apply overrides the fields of l specified by other
+func (l *paragraphLayout) apply(other paragraphLayout) {
+	if other.alignment != nil {
+		l.alignment = other.alignment
+	}
+	if other.before != nil {
+		l.before = other.before
+	}
+	if other.after != nil {
+		l.after = other.after
+	}
+	if other.line != nil {
+		l.line = other.line
+	}
+	if other.lineRule != nil {
+		l.lineRule = other.lineRule
+	}
+}
+
+type styleLayout struct {
+	basedOn string
+	layout  paragraphLayout
+}
+
+// styleSheet is the subset of the styles part needed to resolve effective paragraph properties
+type styleSheet struct {
+	defaults              paragraphLayout
+	paragraphStyles       map[string]*styleLayout
+	defaultParagraphStyle string
+}
+
+// paragraphLayout resolves the properties of a paragraph with the given style ID: document
+// defaults, then the basedOn chain from its root down to the style. An empty or unknown ID
+// uses the default paragraph style.
+func (s *styleSheet) paragraphLayout(styleID string) paragraphLayout {
+	if _, ok := s.paragraphStyles[styleID]; !ok {
+		styleID = s.defaultParagraphStyle
+	}
+	var chain []*styleLayout
+	seen := make(map[string]bool)
+	for id := styleID; id != "" && !seen[id]; {
+		seen[id] = true
+		style, ok := s.paragraphStyles[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, style)
+		id = style.basedOn
+	}
+
+	layout := s.defaults
+	for i := len(chain) - 1; i >= 0; i-- {
+		layout.apply(chain[i].layout)
+	}
+	return layout
+}
+
+// styleSheet returns the parsed styles part, reparsing it only when its data has changed.
+// It returns nil when the document has no readable styles part.
+func (dp *DocumentPart) styleSheet() *styleSheet {
+	if dp == nil || dp.pkg == nil {
+		return nil
+	}
+	part, ok := dp.pkg.parts[dp.pkg.stylesURI(dp.Part.URI)]
+	if !ok || len(part.Data) == 0 {
+		return nil
+	}
+	if dp.styles != nil && len(dp.stylesData) == len(part.Data) && &dp.stylesData[0] == &part.Data[0] {
+		return dp.styles
+	}
+	sheet, err := parseStyleSheet(part.Data)
+	if err != nil {
+		return nil
+	}
+	dp.styles = sheet
+	dp.stylesData = part.Data
+	return sheet
+}
+
+type paragraphPropertiesXML struct {
+	Jc *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"jc"`
+	Spacing *struct {
+		Before   *int    `xml:"before,attr"`
+		After    *int    `xml:"after,attr"`
+		Line     *int    `xml:"line,attr"`
+		LineRule *string `xml:"lineRule,attr"`
+	} `xml:"spacing"`
+}
+
+func (p *paragraphPropertiesXML) layout() paragraphLayout {
+	var layout paragraphLayout
+	if p == nil {
+		return layout
+	}
+	if p.Jc != nil && p.Jc.Val != "" {
+		alignment := mapParagraphAlignment(p.Jc.Val)
+		layout.alignment = &alignment
+	}
+	if p.Spacing != nil {
+		layout.before = p.Spacing.Before
+		layout.after = p.Spacing.After
+		layout.line = p.Spacing.Line
+		if rule := p.Spacing.LineRule; rule != nil && isLineRule(*rule) {
+			layout.lineRule = rule
+			if *rule == lineRuleAuto && (layout.line == nil || *layout.line <= 0) {
+				layout.line = intPtr(singleLineSpacing)
+			}
+		}
+	}
+	return layout
+}
+
+func parseStyleSheet(data []byte) (*styleSheet, error) {
+	var parsed struct {
+		DocDefaults struct {
+			PPr *paragraphPropertiesXML `xml:"pPrDefault>pPr"`
+		} `xml:"docDefaults"`
+		Styles []struct {
+			Type    string `xml:"type,attr"`
+			ID      string `xml:"styleId,attr"`
+			Default string `xml:"default,attr"`
+			BasedOn struct {
+				Val string `xml:"val,attr"`
+			} `xml:"basedOn"`
+			PPr *paragraphPropertiesXML `xml:"pPr"`
+		} `xml:"style"`
+	}
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse styles: %w", err)
+	}
+
+	sheet := &styleSheet{
+		defaults:        parsed.DocDefaults.PPr.layout(),
+		paragraphStyles: make(map[string]*styleLayout),
+	}
+	for _, style := range parsed.Styles {
+		if style.Type != "paragraph" || style.ID == "" {
+			continue
+		}
+		sheet.paragraphStyles[style.ID] = &styleLayout{basedOn: style.BasedOn.Val, layout: style.PPr.layout()}
+		if parseBinaryFlag(style.Default) && sheet.defaultParagraphStyle == "" {
+			sheet.defaultParagraphStyle = style.ID
+		}
+	}
+	return sheet, nil
+}
@@ -0,0 +1,308 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// originalDocument records where the body elements of a parsed document.xml start and end,
+// so that surgical editing can splice the untouched ones back in.
+type originalDocument struct {
+	data               []byte
+	rootStart, rootEnd int
+	bodyStart, bodyEnd int
+	count              int
+	// surgical enables the minimal-diff serialization; sourceXML holds the XML the model
+	// generates for each original element as parsed, indexed by elementSource.index.
+	surgical  bool
+	sourceXML []string
+	// background is the w:background element as parsed and backgroundStart its offset, -1
+	// when there is none; bodyOpen is the offset of the w:body start tag.
+	background                     string
+	backgroundStart, backgroundEnd int
+	bodyOpen                       int
+	// sources holds the byte range of every original element, so the markup between them
+	// can be checked when an element is removed or moved
+	sources []elementSource
+}
+
+// elementSource is the byte range of a body element within originalDocument.data
+type elementSource struct {
+	index      int
+	start, end int
+}
+
+// source registers the body element that started at start and ends at the decoder's
+// current offset
+func (o *originalDocument) source(start int, decoder *xml.Decoder) *elementSource {
+	source := &elementSource{index: o.count, start: start, end: int(decoder.InputOffset())}
+	o.sources = append(o.sources, *source)
+	o.count++
+	return source
+}
+
+// SetSurgicalEdit turns minimal-diff saving of the main document part on or off. While it
+// is on, body paragraphs, tables and section properties whose content is unchanged since
+// the document was opened are written back byte for byte, together with the original
+// markup between them (whitespace and elements the library does not model, such as
+// body-level content controls or bookmarks). Only modified or new elements are
+// re-serialized, and every other part is already kept as read. Changes are detected by
+// comparing each element with its state when parsed, so the mode may be enabled at any
+// time before saving. When removing or moving an element would separate markup that opens
+// or closes an enclosing element, such as a content control, the body is regenerated in
+// full instead.
+func (d *Document) SetSurgicalEdit(enabled bool) error {
+	if d.docPart == nil {
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	return d.docPart.setSurgicalEdit(enabled)
+}
+
+// SurgicalEdit reports whether minimal-diff saving is enabled
+func (d *Document) SurgicalEdit() bool {
+	return d.docPart != nil && d.docPart.original.surgical
+}
+
+func (dp *DocumentPart) setSurgicalEdit(enabled bool) error {
+	if !enabled {
+		dp.original.surgical = false
+		dp.original.sourceXML = nil
+		return nil
+	}
+	if dp.original.surgical {
+		return nil
+	}
+
+	// reparse the original bytes so elements edited before the mode was enabled still
+	// compare against their parsed state
+	pristine := &DocumentPart{Part: &Part{URI: dp.Part.URI, Data: dp.original.data}, pkg: dp.pkg}
+	if err := pristine.loadFromXML(); err != nil {
+		return fmt.Errorf("failed to parse original document: %w", err)
+	}
+	sourceXML := make([]string, pristine.original.count)
+	for _, element := range pristine.bodyElements {
+		if element.source != nil {
+			sourceXML[element.source.index] = element.xml()
+		}
+	}
+	dp.original.sourceXML = sourceXML
+	dp.original.surgical = true
+	return nil
+}
+
+// removeOriginalProofingMarks strips proofErr markers from the original document bytes and
+// moves the recorded offsets with them, so surgical editing does not copy the markers back
+func (dp *DocumentPart) removeOriginalProofingMarks() error {
+	data := proofErrPattern.ReplaceAll(dp.original.data, nil)
+	if len(data) == len(dp.original.data) {
+		return nil
+	}
+	stripped := &DocumentPart{Part: &Part{URI: dp.Part.URI, Data: data}, pkg: dp.pkg}
+	if err := stripped.loadFromXML(); err != nil {
+		return fmt.Errorf("failed to parse original document: %w", err)
+	}
+	if stripped.original.count != dp.original.count {
+		return fmt.Errorf("original document has %d body elements after removing proofing marks, expected %d", stripped.original.count, dp.original.count)
+	}
+	for _, element := range dp.bodyElements {
+		if element.source != nil {
+			*element.source = stripped.original.sources[element.source.index]
+		}
+	}
+	original := stripped.original
+	original.surgical, original.sourceXML = dp.original.surgical, dp.original.sourceXML
+	dp.original = original
+	return nil
+}
+
+// xml returns the generated XML of the element
+func (e documentElement) xml() string {
+	switch {
+	case e.paragraph != nil:
+		return e.paragraph.ToXML()
+	case e.table != nil:
+		return e.table.ToXML()
+	case e.section != nil:
+		return e.section.ToXML()
+	}
+	return ""
+}
+
+// surgicalXMLData serializes the body for surgical editing. ok is false when the mode is
+// off or the original document has no usable body, in which case the body is regenerated.
+func (dp *DocumentPart) surgicalXMLData() (data []byte, ok bool) {
+	original := dp.original
+	if !original.surgical || original.bodyStart < 0 || original.bodyEnd < original.bodyStart {
+		return nil, false
+	}
+
+	// the body-level sectPr closes the body, even when content was appended after it
+	elements := make([]documentElement, 0, len(dp.bodyElements)+1)
+	var bodySection *documentElement
+	for i, element := range dp.bodyElements {
+		if element.section != nil {
+			bodySection = &dp.bodyElements[i]
+			continue
+		}
+		if element.paragraph != nil || element.table != nil {
+			elements = append(elements, element)
+		}
+	}
+	lastEndsSection := len(elements) > 0 && elements[len(elements)-1].paragraph != nil && elements[len(elements)-1].paragraph.section != nil
+	switch {
+	case bodySection != nil:
+		elements = append(elements, *bodySection)
+	case !lastEndsSection && len(dp.sections) > 0:
+		elements = append(elements, documentElement{section: dp.sections[len(dp.sections)-1]})
+	case !lastEndsSection:
+		elements = append(elements, documentElement{section: NewSection(SectionStartContinuous)})
+	}
+
+	var body bytes.Buffer
+	var regenerated []string
+	// gapWritten[i] records whether the original markup before element i (or, for the last
+	// entry, after the final element) was copied
+	gapWritten := make([]bool, original.count+1)
+	previous, previousEnd := -1, original.bodyStart
+	for _, element := range elements {
+		source := element.source
+		if source != nil && source.index == previous+1 {
+			body.Write(original.data[previousEnd:source.start])
+			gapWritten[source.index] = true
+		}
+		generated := element.xml()
+		if source != nil && source.index < len(original.sourceXML) && generated == original.sourceXML[source.index] {
+			body.Write(original.data[source.start:source.end])
+		} else {
+			body.WriteString(generated)
+			regenerated = append(regenerated, generated)
+		}
+		if source != nil {
+			previous, previousEnd = source.index, source.end
+		} else {
+			previous = -2
+		}
+	}
+	if previous == original.count-1 {
+		body.Write(original.data[previousEnd:original.bodyEnd])
+		gapWritten[original.count] = true
+	}
+	// dropping markup that opens or closes an enclosing element, such as the sdtContent
+	// around a removed paragraph, would leave the body malformed
+	for i, written := range gapWritten {
+		if !written && !balancedMarkup(original.gap(i)) {
+			return nil, false
+		}
+	}
+
+	if background := dp.backgroundXML(); background != original.background {
+		regenerated = append(regenerated, background)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(original.data[:original.rootStart])
+	// keep the original start tag unless regenerated markup needs a prefix it lacks
+	if dp.declaresPrefixesOf(regenerated) {
+		buf.Write(original.data[original.rootStart:original.rootEnd])
+	} else {
+		buf.WriteString(dp.rootStartElement())
+	}
+	// a changed page background replaces the original element or goes before the body
+	background := dp.backgroundXML()
+	switch {
+	case background == original.background:
+		buf.Write(original.data[original.rootEnd:original.bodyStart])
+	case original.backgroundStart >= 0:
+		buf.Write(original.data[original.rootEnd:original.backgroundStart])
+		buf.WriteString(background)
+		buf.Write(original.data[original.backgroundEnd:original.bodyStart])
+	default:
+		buf.Write(original.data[original.rootEnd:original.bodyOpen])
+		buf.WriteString(background)
+		buf.Write(original.data[original.bodyOpen:original.bodyStart])
+	}
+	buf.Write(body.Bytes())
+	buf.Write(original.data[original.bodyEnd:])
+	return buf.Bytes(), true
+}
+
+// gap returns the original markup before element index, or after the last element when
+// index equals the element count
+func (o *originalDocument) gap(index int) []byte {
+	start, end := o.bodyStart, o.bodyEnd
+	if index > 0 && index-1 < len(o.sources) {
+		start = o.sources[index-1].end
+	}
+	if index < len(o.sources) {
+		end = o.sources[index].start
+	}
+	if start > end {
+		return nil
+	}
+	return o.data[start:end]
+}
+
+// balancedMarkup reports whether every element opened in data is also closed in it
+func balancedMarkup(data []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			return depth == 0
+		}
+		if err != nil {
+			return false
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return false
+			}
+			depth--
+		}
+	}
+}
+
+// declaresPrefixesOf reports whether the parsed root element declares every namespace
+// prefix used by the given fragments, apart from those the fragments declare themselves
+func (dp *DocumentPart) declaresPrefixesOf(fragments []string) bool {
+	declared := map[string]bool{"xml": true, "xmlns": true}
+	for _, attr := range dp.rootAttrs {
+		if attr.Name.Space == "xmlns" {
+			declared[attr.Name.Local] = true
+		}
+	}
+	used := make(map[string]bool)
+	for _, fragment := range fragments {
+		decoder := xml.NewDecoder(strings.NewReader(fragment))
+		for {
+			tok, err := decoder.RawToken()
+			if err != nil {
+				break
+			}
+			start, ok := tok.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			used[start.Name.Space] = true
+			for _, attr := range start.Attr {
+				if attr.Name.Space == "xmlns" {
+					declared[attr.Name.Local] = true
+				}
+				used[attr.Name.Space] = true
+			}
+		}
+	}
+	for prefix := range used {
+		if prefix != "" && !declared[prefix] {
+			return false
+		}
+	}
+	return true
+}
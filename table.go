@@ -1,30 +1,43 @@
 package docx
 
 import (
-	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Table represents a table in a Word document
 type Table struct {
-	rows           []*TableRow
-	owner          *DocumentPart
-	gridColumns    int
-	grid           []int
-	width          int // table width in twentieths of a point (0 for auto)
-	widthType      string
-	indent         int
-	indentType     string
-	indentSet      bool
-	style          string
-	layout         string
-	look           *TableLook
-	alignment      TableAlignment
-	bordersDefined bool
-	borders        map[TableBorderSide]*TableBorder
-	shading        *Shading
-	cellMargins    *TableCellMargins
+	rows            []*TableRow
+	owner           *DocumentPart
+	gridColumns     int
+	grid            []int
+	width           int // table width in twentieths of a point (0 for auto)
+	widthType       string
+	indent          int
+	indentType      string
+	indentSet       bool
+	cellSpacing     int
+	cellSpacingType string
+	cellSpacingSet  bool
+	style           string
+	layout          string
+	look            *TableLook
+	alignment       TableAlignment
+	bordersDefined  bool
+	borders         map[TableBorderSide]*TableBorder
+	shading         *Shading
+	cellMargins     *TableCellMargins
+	position        *TablePosition
+	overlap         string // w:tblOverlap value: "overlap", "never" or "" when unset
+	caption         string
+	description     string
+
+	// cellVerticalAlign is applied to cells added after SetVerticalAlignment
+	cellVerticalAlign WDVerticalAlignment
+	// cellContentSpacing is applied to cells added after SetContentSpacing
+	cellContentSpacing *[2]int
 }
 
 var xmlAttrEscaper = strings.NewReplacer(
@@ -50,7 +63,35 @@ type Shading struct {
 type TableRow struct {
 	table *Table
 	cells []*TableCell
-}
+	// gridBefore/gridAfter are grid columns left empty before the first and after the last
+	// cell; widthBefore/widthAfter their widths in twentieths of a point
+	gridBefore  int
+	gridAfter   int
+	widthBefore int
+	widthAfter  int
+	// rawProperties keeps trPr children the library does not model, split around the
+	// grid elements to preserve the CT_TrPr sequence
+	rawPropertiesBefore []string
+	rawPropertiesAfter  []string
+	// repeatHeader repeats the row at the top of each page the table spans (w:tblHeader)
+	repeatHeader bool
+	// exceptions holds the row's w:tblPrEx overrides; exceptionsRaw keeps its children the
+	// library does not model, keyed by local name
+	exceptions    *TablePropertyExceptions
+	exceptionsRaw map[string]string
+}
+
+// TablePropertyExceptions overrides table properties for a single row (w:tblPrEx), as
+// Word writes for rows of tables that were split, merged or pasted together. Nil or empty
+// fields leave the table's own setting in place.
+type TablePropertyExceptions struct {
+	Borders     map[TableBorderSide]TableBorder
+	CellMargins *TableCellMargins
+	Alignment   TableAlignment
+}
+
+// tablePropertyExceptionsOrder lists the children of w:tblPrEx in schema order
+var tablePropertyExceptionsOrder = []string{"tblW", "jc", "tblCellSpacing", "tblInd", "tblBorders", "shd", "tblLayout", "tblCellMar", "tblLook"}
 
 // TableCell represents a cell in a table
 type TableCell struct {
@@ -63,6 +104,9 @@ type TableCell struct {
 	verticalAlign WDVerticalAlignment // vertical alignment in cell
 	borders       map[TableBorderSide]*TableBorder
 	shading       *Shading
+	margins       *TableCellMargins
+	// contentSpacing holds the before/after spacing applied to paragraphs added to the cell.
+	contentSpacing *[2]int
 }
 
 // TableBorderSide identifies borders on tables and cells.
@@ -96,6 +140,23 @@ type TableLook struct {
 	NoVBand     bool
 }
 
+// TablePosition places a floating table (w:tblpPr). Anchors are "text", "margin" or "page";
+// X and Y are offsets from the anchors in twentieths of a point, and XSpec/YSpec optionally
+// align the table relative to them instead (e.g. "center", "right", "bottom"). The FromText
+// distances keep surrounding text away from the table, also in twentieths of a point.
+type TablePosition struct {
+	HorizontalAnchor string
+	VerticalAnchor   string
+	X                int
+	Y                int
+	XSpec            string
+	YSpec            string
+	LeftFromText     int
+	RightFromText    int
+	TopFromText      int
+	BottomFromText   int
+}
+
 // TableAlignment represents table justification.
 type TableAlignment string
 
@@ -185,6 +246,30 @@ func (t *Table) Row(index int) *TableRow {
 	return t.rows[index]
 }
 
+// CellAt returns the cell covering the logical (grid) position at row and column, taking
+// horizontal spans into account. A cell that continues a vertical merge resolves to the
+// cell that starts the merge. It returns nil when the position is outside the table.
+func (t *Table) CellAt(row, column int) *TableCell {
+	if row < 0 || row >= len(t.rows) || t.rows[row] == nil {
+		return nil
+	}
+	cell, start := t.rows[row].cellAtGridColumn(column)
+	if cell == nil {
+		return nil
+	}
+	for r := row - 1; r >= 0 && cell.verticalMerge == TableVerticalMergeContinue; r-- {
+		if t.rows[r] == nil {
+			break
+		}
+		above, aboveStart := t.rows[r].cellAtGridColumn(start)
+		if above == nil || aboveStart != start {
+			break
+		}
+		cell = above
+	}
+	return cell
+}
+
 // GetRow is an alias for Row() - returns the row at the specified index
 func (t *Table) GetRow(index int) *TableRow {
 	return t.Row(index)
@@ -226,9 +311,13 @@ func (t *Table) AddRow() *TableRow {
 			width:      width,
 			borders:    make(map[TableBorderSide]*TableBorder),
 		}
+		cell.verticalAlign = t.cellVerticalAlign
 		if len(cell.paragraphs) > 0 && cell.paragraphs[0] != nil {
 			cell.paragraphs[0].owner = t.owner
 		}
+		if t.cellContentSpacing != nil {
+			cell.SetContentSpacing(t.cellContentSpacing[0], t.cellContentSpacing[1])
+		}
 		row.cells[i] = cell
 	}
 
@@ -299,9 +388,13 @@ func (t *Table) InsertRowAt(index int) *TableRow {
 			width:      width,
 			borders:    make(map[TableBorderSide]*TableBorder),
 		}
+		cell.verticalAlign = t.cellVerticalAlign
 		if len(cell.paragraphs) > 0 && cell.paragraphs[0] != nil {
 			cell.paragraphs[0].owner = t.owner
 		}
+		if t.cellContentSpacing != nil {
+			cell.SetContentSpacing(t.cellContentSpacing[0], t.cellContentSpacing[1])
+		}
 		row.cells[i] = cell
 	}
 	t.rows = append(t.rows, nil)
@@ -310,6 +403,83 @@ func (t *Table) InsertRowAt(index int) *TableRow {
 	return row
 }
 
+// SetRepeatHeaderRows marks the first n rows as header rows repeated on every page the
+// table spans, e.g. for grouped column labels, and unmarks the others.
+func (t *Table) SetRepeatHeaderRows(n int) {
+	for i, row := range t.rows {
+		if row != nil {
+			row.SetRepeatHeader(i < n)
+		}
+	}
+}
+
+// RepeatHeaderRows returns the number of leading rows that repeat as headers
+func (t *Table) RepeatHeaderRows() int {
+	count := 0
+	for _, row := range t.rows {
+		if row == nil || !row.repeatHeader {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// SortOptions controls how SortRows orders table rows.
+type SortOptions struct {
+	Descending bool // sort from largest to smallest
+	Numeric    bool // compare values as numbers; rows with non-numeric values sort last
+	SkipHeader bool // keep the first row in place
+}
+
+// SortRows reorders the rows by the text of the first paragraph of the cell in the given
+// grid column. The sort is stable, so rows with equal keys keep their relative order.
+// Vertically merged cells are not adjusted and may no longer line up after sorting.
+func (t *Table) SortRows(column int, opts SortOptions) error {
+	columns := t.gridColumns
+	if len(t.grid) > columns {
+		columns = len(t.grid)
+	}
+	if column < 0 || column >= columns {
+		return fmt.Errorf("%w: column index %d out of range", ErrIndexOutOfRange, column)
+	}
+
+	rows := t.rows
+	if opts.SkipHeader && len(rows) > 0 {
+		rows = rows[1:]
+	}
+	keys := make(map[*TableRow]string, len(rows))
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if cell, _ := row.cellAtGridColumn(column); cell != nil && len(cell.paragraphs) > 0 && cell.paragraphs[0] != nil {
+			keys[row] = strings.TrimSpace(cell.paragraphs[0].Text())
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := keys[rows[i]], keys[rows[j]]
+		if opts.Numeric {
+			x, errX := strconv.ParseFloat(a, 64)
+			y, errY := strconv.ParseFloat(b, 64)
+			switch {
+			case errX != nil || errY != nil:
+				return errX == nil && errY != nil
+			case opts.Descending:
+				return x > y
+			default:
+				return x < y
+			}
+		}
+		if opts.Descending {
+			return a > b
+		}
+		return a < b
+	})
+	return nil
+}
+
 // SetBorder configures the border for the specified table side. An empty style clears the border.
 func (t *Table) SetBorder(side TableBorderSide, border TableBorder) {
 	if side == "" {
@@ -401,6 +571,37 @@ func (t *Table) ClearIndent() {
 	t.indentSet = false
 }
 
+// SetCellSpacing sets the spacing between adjacent cells (tblCellSpacing). Values are in
+// twentieths of a point for the default "dxa" type. Unlike cell margins, the spacing opens
+// gaps between cells, producing a grid-with-gaps look.
+func (t *Table) SetCellSpacing(value int, typ string) {
+	t.cellSpacing = value
+	t.cellSpacingType = typ
+	t.cellSpacingSet = true
+	if t.cellSpacingType == "" {
+		t.cellSpacingType = "dxa"
+	}
+}
+
+// CellSpacing returns the spacing between cells and its type if set.
+func (t *Table) CellSpacing() (int, string, bool) {
+	if !t.cellSpacingSet {
+		return 0, "", false
+	}
+	typ := t.cellSpacingType
+	if typ == "" {
+		typ = "dxa"
+	}
+	return t.cellSpacing, typ, true
+}
+
+// ClearCellSpacing removes the spacing between cells.
+func (t *Table) ClearCellSpacing() {
+	t.cellSpacing = 0
+	t.cellSpacingType = ""
+	t.cellSpacingSet = false
+}
+
 // SetStyle applies a table style by id.
 func (t *Table) SetStyle(style string) {
 	t.style = style
@@ -431,6 +632,26 @@ func (t *Table) ClearLayout() {
 	t.layout = ""
 }
 
+// SetCaption sets the table title (w:tblCaption) read by assistive technologies.
+func (t *Table) SetCaption(caption string) {
+	t.caption = caption
+}
+
+// Caption returns the accessibility title of the table.
+func (t *Table) Caption() string {
+	return t.caption
+}
+
+// SetDescription sets the table summary (w:tblDescription) read by assistive technologies.
+func (t *Table) SetDescription(description string) {
+	t.description = description
+}
+
+// Description returns the accessibility summary of the table.
+func (t *Table) Description() string {
+	return t.description
+}
+
 // SetAlignment configures the table justification.
 func (t *Table) SetAlignment(alignment TableAlignment) {
 	t.alignment = alignment
@@ -441,6 +662,26 @@ func (t *Table) Alignment() TableAlignment {
 	return t.alignment
 }
 
+// SetVerticalAlignment sets the vertical alignment of every cell in the table and of cells
+// added later by AddRow or InsertRowAt. Individual cells can still be changed afterwards.
+func (t *Table) SetVerticalAlignment(alignment WDVerticalAlignment) {
+	t.cellVerticalAlign = alignment
+	for _, row := range t.rows {
+		if row == nil {
+			continue
+		}
+		for _, cell := range row.cells {
+			cell.SetVerticalAlignment(alignment)
+		}
+	}
+}
+
+// VerticalAlignment returns the default cell vertical alignment set with
+// SetVerticalAlignment. It is not stored in the file, so opened tables report none.
+func (t *Table) VerticalAlignment() WDVerticalAlignment {
+	return t.cellVerticalAlign
+}
+
 // ClearAlignment removes any table justification setting.
 func (t *Table) ClearAlignment() {
 	t.alignment = ""
@@ -465,6 +706,96 @@ func (t *Table) ClearLook() {
 	t.look = nil
 }
 
+// SetFloatingPosition makes the table float at the given position, so text can wrap
+// around it.
+func (t *Table) SetFloatingPosition(position TablePosition) {
+	copy := position
+	t.position = &copy
+}
+
+// FloatingPosition returns the position of a floating table when present.
+func (t *Table) FloatingPosition() (*TablePosition, bool) {
+	if t.position == nil {
+		return nil, false
+	}
+	return t.position, true
+}
+
+// ClearFloatingPosition turns a floating table back into an inline one.
+func (t *Table) ClearFloatingPosition() {
+	t.position = nil
+}
+
+// SetOverlap controls whether a floating table may overlap other floating tables.
+func (t *Table) SetOverlap(overlap bool) {
+	t.overlap = "never"
+	if overlap {
+		t.overlap = "overlap"
+	}
+}
+
+// Overlap reports whether a floating table may overlap others; ok is false when the
+// table does not set it, in which case overlapping is allowed.
+func (t *Table) Overlap() (overlap bool, ok bool) {
+	if t.overlap == "" {
+		return true, false
+	}
+	return t.overlap != "never", true
+}
+
+// ClearOverlap removes any explicit table overlap setting.
+func (t *Table) ClearOverlap() {
+	t.overlap = ""
+}
+
+// SetAllBorders applies the same border to all four outside edges and both inside rules.
+func (t *Table) SetAllBorders(border TableBorder) {
+	t.SetOutsideBorders(border)
+	t.SetInsideBorders(border)
+}
+
+// SetOutsideBorders applies the border to the top, left, bottom and right edges of the table.
+func (t *Table) SetOutsideBorders(border TableBorder) {
+	for _, side := range []TableBorderSide{TableBorderTop, TableBorderLeft, TableBorderBottom, TableBorderRight} {
+		t.SetBorder(side, border)
+	}
+}
+
+// SetInsideBorders applies the border to the horizontal and vertical rules between cells.
+func (t *Table) SetInsideBorders(border TableBorder) {
+	t.SetBorder(TableBorderInsideH, border)
+	t.SetBorder(TableBorderInsideV, border)
+}
+
+// SetContentSpacing applies TableCell.SetContentSpacing to every cell in the table and to
+// cells added later by AddRow or InsertRowAt.
+func (t *Table) SetContentSpacing(before, after int) {
+	t.cellContentSpacing = &[2]int{before, after}
+	for _, row := range t.rows {
+		if row == nil {
+			continue
+		}
+		for _, cell := range row.cells {
+			cell.SetContentSpacing(before, after)
+		}
+	}
+}
+
+// ContentSpacing returns the default cell content spacing set with SetContentSpacing. It is
+// not stored in the file, so opened tables report none.
+func (t *Table) ContentSpacing() (before, after int, ok bool) {
+	if t.cellContentSpacing == nil {
+		return 0, 0, false
+	}
+	return t.cellContentSpacing[0], t.cellContentSpacing[1], true
+}
+
+// ClearAllBorders hides every table border by setting each side to "none", overriding any
+// borders inherited from the table style. Use ClearBorders to drop the definitions instead.
+func (t *Table) ClearAllBorders() {
+	t.SetAllBorders(TableBorder{Style: "none", Size: 0, Space: 0, Color: "auto"})
+}
+
 // Border returns the configured border for the table on the specified side.
 func (t *Table) Border(side TableBorderSide) (*TableBorder, bool) {
 	if t.borders == nil {
@@ -525,14 +856,14 @@ func (t *Table) ClearCellMargins() {
 // MergeCellsHorizontally merges cells in the specified row between start and end inclusive.
 func (t *Table) MergeCellsHorizontally(rowIndex, start, end int) error {
 	if rowIndex < 0 || rowIndex >= len(t.rows) {
-		return fmt.Errorf("row index %d out of range", rowIndex)
+		return fmt.Errorf("%w: row index %d out of range", ErrIndexOutOfRange, rowIndex)
 	}
 	if start < 0 || end < start {
-		return errors.New("invalid start/end for horizontal merge")
+		return fmt.Errorf("%w: invalid start %d and end %d for horizontal merge", ErrIndexOutOfRange, start, end)
 	}
 	row := t.rows[rowIndex]
 	if end >= len(row.cells) {
-		return fmt.Errorf("end index %d out of range", end)
+		return fmt.Errorf("%w: end index %d out of range", ErrIndexOutOfRange, end)
 	}
 	span := end - start + 1
 	if span <= 1 {
@@ -552,15 +883,15 @@ func (t *Table) MergeCellsHorizontally(rowIndex, start, end int) error {
 // MergeCellsVertically merges cells in the specified column between the start and end row (inclusive).
 func (t *Table) MergeCellsVertically(column, startRow, endRow int) error {
 	if column < 0 {
-		return errors.New("column index must be non-negative")
+		return fmt.Errorf("%w: column index must be non-negative", ErrIndexOutOfRange)
 	}
 	if startRow < 0 || endRow < startRow || endRow >= len(t.rows) {
-		return errors.New("invalid start/end row for vertical merge")
+		return fmt.Errorf("%w: invalid start row %d and end row %d for vertical merge", ErrIndexOutOfRange, startRow, endRow)
 	}
 	for rowIndex := startRow; rowIndex <= endRow; rowIndex++ {
 		row := t.rows[rowIndex]
 		if column >= len(row.cells) {
-			return fmt.Errorf("column index %d out of range for row %d", column, rowIndex)
+			return fmt.Errorf("%w: column index %d out of range for row %d", ErrIndexOutOfRange, column, rowIndex)
 		}
 		cell := row.cells[column]
 		if rowIndex == startRow {
@@ -575,6 +906,141 @@ func (t *Table) MergeCellsVertically(column, startRow, endRow int) error {
 	return nil
 }
 
+// MergeCells merges the rectangular block of cells from (rowStart, colStart) to
+// (rowEnd, colEnd) inclusive, where columns are grid columns. Each row of the block is
+// merged into a single spanning cell and the rows are then merged vertically, leaving the
+// top-left cell as the visible cell. Text from the other cells is appended to it. The block
+// is validated before any cell is changed, so an error leaves the table untouched.
+func (t *Table) MergeCells(rowStart, colStart, rowEnd, colEnd int) error {
+	if rowStart < 0 || rowEnd >= len(t.rows) {
+		return fmt.Errorf("%w: rows %d-%d out of range", ErrIndexOutOfRange, rowStart, rowEnd)
+	}
+	if colStart < 0 {
+		return fmt.Errorf("%w: column index must be non-negative", ErrIndexOutOfRange)
+	}
+	if rowEnd < rowStart || colEnd < colStart {
+		return fmt.Errorf("%w: invalid block rows %d-%d, columns %d-%d for cell merge", ErrIndexOutOfRange, rowStart, rowEnd, colStart, colEnd)
+	}
+
+	// locate the cells covering the block in each row; their edges must match the block
+	first := make([]int, rowEnd-rowStart+1)
+	last := make([]int, rowEnd-rowStart+1)
+	for rowIndex := rowStart; rowIndex <= rowEnd; rowIndex++ {
+		row := t.rows[rowIndex]
+		offset := rowIndex - rowStart
+		first[offset], last[offset] = -1, -1
+		start := row.gridBefore
+		for cellIndex, cell := range row.cells {
+			end := start + cell.GridSpan() - 1
+			if start == colStart {
+				first[offset] = cellIndex
+			}
+			if end == colEnd {
+				last[offset] = cellIndex
+			}
+			start = end + 1
+		}
+		if colEnd >= start {
+			return fmt.Errorf("%w: column %d out of range for row %d", ErrIndexOutOfRange, colEnd, rowIndex)
+		}
+		if first[offset] < 0 || last[offset] < 0 {
+			return fmt.Errorf("%w: cells of row %d span beyond columns %d-%d", ErrInvalidArgument, rowIndex, colStart, colEnd)
+		}
+	}
+
+	anchor := t.rows[rowStart].cells[first[0]]
+	var moved []*Paragraph
+	for rowIndex := rowStart; rowIndex <= rowEnd; rowIndex++ {
+		row := t.rows[rowIndex]
+		offset := rowIndex - rowStart
+		cell := row.cells[first[offset]]
+		if cell != anchor {
+			moved = append(moved, nonEmptyParagraphs(cell.paragraphs)...)
+		}
+		for i := first[offset] + 1; i <= last[offset]; i++ {
+			cell.width += row.cells[i].width
+			moved = append(moved, nonEmptyParagraphs(row.cells[i].paragraphs)...)
+		}
+		row.cells = append(row.cells[:first[offset]+1], row.cells[last[offset]+1:]...)
+		cell.SetGridSpan(colEnd - colStart + 1)
+
+		if rowStart == rowEnd {
+			continue
+		}
+		if cell == anchor {
+			cell.verticalMerge = TableVerticalMergeRestart
+			continue
+		}
+		cell.verticalMerge = TableVerticalMergeContinue
+		cell.SetText("")
+	}
+	anchor.paragraphs = append(anchor.paragraphs, moved...)
+	return nil
+}
+
+// nonEmptyParagraphs returns the paragraphs that contain text
+func nonEmptyParagraphs(paragraphs []*Paragraph) []*Paragraph {
+	var result []*Paragraph
+	for _, paragraph := range paragraphs {
+		if paragraph.Text() != "" {
+			result = append(result, paragraph)
+		}
+	}
+	return result
+}
+
+// Transpose swaps the rows and columns of the table, moving cell content so that the
+// cell at (row, col) ends up at (col, row). Cells keep their widths and the grid is
+// rebuilt from them. Row properties (header repetition, grid offsets, table property
+// exceptions and unmodeled trPr settings) describe the old rows and are reset. Tables
+// containing merged cells, rows of differing lengths or rows with grid offsets cannot be
+// transposed and return an error.
+func (t *Table) Transpose() error {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	cols := -1
+	for rowIndex, row := range t.rows {
+		if row == nil {
+			return fmt.Errorf("row %d is nil", rowIndex)
+		}
+		if cols == -1 {
+			cols = len(row.cells)
+		} else if len(row.cells) != cols {
+			return fmt.Errorf("row %d has %d cells, expected %d", rowIndex, len(row.cells), cols)
+		}
+		if row.gridBefore > 0 || row.gridAfter > 0 {
+			return fmt.Errorf("cannot transpose table with grid offsets in row %d", rowIndex)
+		}
+		for colIndex, cell := range row.cells {
+			if cell.GridSpan() > 1 || cell.verticalMerge != TableVerticalMergeNone {
+				return fmt.Errorf("cannot transpose table with merged cell at row %d, column %d", rowIndex, colIndex)
+			}
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+
+	newCols := len(t.rows)
+	rows := make([]*TableRow, cols)
+	for i := 0; i < cols; i++ {
+		row := &TableRow{table: t, cells: make([]*TableCell, newCols)}
+		for j := 0; j < newCols; j++ {
+			cell := t.rows[j].cells[i]
+			cell.row = row
+			row.cells[j] = cell
+		}
+		rows[i] = row
+	}
+
+	t.rows = rows
+	t.gridColumns = newCols
+	t.grid = nil
+	t.grid = t.columnWidths()
+	return nil
+}
+
 func (t *Table) tblPropertiesXML() string {
 	var builder strings.Builder
 	builder.WriteString("<w:tblPr>")
@@ -582,6 +1048,10 @@ func (t *Table) tblPropertiesXML() string {
 	if t.style != "" {
 		builder.WriteString(fmt.Sprintf(`<w:tblStyle w:val="%s"/>`, xmlEscapeAttribute(t.style)))
 	}
+	builder.WriteString(tablePositionElement(t.position))
+	if t.overlap != "" {
+		builder.WriteString(fmt.Sprintf(`<w:tblOverlap w:val="%s"/>`, xmlEscapeAttribute(t.overlap)))
+	}
 
 	widthType := t.WidthType()
 	widthVal := t.width
@@ -590,6 +1060,14 @@ func (t *Table) tblPropertiesXML() string {
 	}
 	builder.WriteString(fmt.Sprintf(`<w:tblW w:w="%d" w:type="%s"/>`, widthVal, xmlEscapeAttribute(widthType)))
 
+	if t.alignment != "" {
+		builder.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, xmlEscapeAttribute(string(t.alignment))))
+	}
+
+	if spacing, typ, ok := t.CellSpacing(); ok {
+		builder.WriteString(fmt.Sprintf(`<w:tblCellSpacing w:w="%d" w:type="%s"/>`, spacing, xmlEscapeAttribute(typ)))
+	}
+
 	if t.indentSet {
 		typeAttr := t.indentType
 		if typeAttr == "" {
@@ -598,10 +1076,6 @@ func (t *Table) tblPropertiesXML() string {
 		builder.WriteString(fmt.Sprintf(`<w:tblInd w:w="%d" w:type="%s"/>`, t.indent, typeAttr))
 	}
 
-	if t.alignment != "" {
-		builder.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, xmlEscapeAttribute(string(t.alignment))))
-	}
-
 	if t.bordersDefined || len(t.borders) > 0 {
 		builder.WriteString(t.bordersXML())
 	}
@@ -619,6 +1093,12 @@ func (t *Table) tblPropertiesXML() string {
 	if t.hasCellMargins() {
 		builder.WriteString(t.cellMarginsXML())
 	}
+	if t.caption != "" {
+		builder.WriteString(fmt.Sprintf(`<w:tblCaption w:val="%s"/>`, xmlEscapeAttribute(t.caption)))
+	}
+	if t.description != "" {
+		builder.WriteString(fmt.Sprintf(`<w:tblDescription w:val="%s"/>`, xmlEscapeAttribute(t.description)))
+	}
 	builder.WriteString("</w:tblPr>")
 	return builder.String()
 }
@@ -652,24 +1132,29 @@ func (t *Table) hasCellMargins() bool {
 }
 
 func (t *Table) cellMarginsXML() string {
-	if t.cellMargins == nil {
+	return cellMarginsElement("w:tblCellMar", t.cellMargins)
+}
+
+// cellMarginsElement renders margins as a tblCellMar or tcMar element
+func cellMarginsElement(name string, margins *TableCellMargins) string {
+	if margins == nil {
 		return ""
 	}
 	var builder strings.Builder
-	builder.WriteString("<w:tblCellMar>")
-	if t.cellMargins.Top != nil {
-		builder.WriteString(fmt.Sprintf(`<w:top w:w="%d" w:type="dxa"/>`, *t.cellMargins.Top))
+	builder.WriteString("<" + name + ">")
+	if margins.Top != nil {
+		builder.WriteString(fmt.Sprintf(`<w:top w:w="%d" w:type="dxa"/>`, *margins.Top))
 	}
-	if t.cellMargins.Left != nil {
-		builder.WriteString(fmt.Sprintf(`<w:left w:w="%d" w:type="dxa"/>`, *t.cellMargins.Left))
+	if margins.Left != nil {
+		builder.WriteString(fmt.Sprintf(`<w:left w:w="%d" w:type="dxa"/>`, *margins.Left))
 	}
-	if t.cellMargins.Bottom != nil {
-		builder.WriteString(fmt.Sprintf(`<w:bottom w:w="%d" w:type="dxa"/>`, *t.cellMargins.Bottom))
+	if margins.Bottom != nil {
+		builder.WriteString(fmt.Sprintf(`<w:bottom w:w="%d" w:type="dxa"/>`, *margins.Bottom))
 	}
-	if t.cellMargins.Right != nil {
-		builder.WriteString(fmt.Sprintf(`<w:right w:w="%d" w:type="dxa"/>`, *t.cellMargins.Right))
+	if margins.Right != nil {
+		builder.WriteString(fmt.Sprintf(`<w:right w:w="%d" w:type="dxa"/>`, *margins.Right))
 	}
-	builder.WriteString("</w:tblCellMar>")
+	builder.WriteString("</" + name + ">")
 	return builder.String()
 }
 
@@ -741,6 +1226,34 @@ func shadingElement(shading *Shading) string {
 	return fmt.Sprintf(`<w:shd w:val="%s" w:color="%s" w:fill="%s"/>`, pattern, color, fill)
 }
 
+func tablePositionElement(position *TablePosition) string {
+	if position == nil {
+		return ""
+	}
+	var attrs []string
+	distance := func(name string, value int) {
+		if value != 0 {
+			attrs = append(attrs, fmt.Sprintf(`w:%s="%d"`, name, value))
+		}
+	}
+	text := func(name, value string) {
+		if value != "" {
+			attrs = append(attrs, fmt.Sprintf(`w:%s="%s"`, name, xmlEscapeAttribute(value)))
+		}
+	}
+	distance("leftFromText", position.LeftFromText)
+	distance("rightFromText", position.RightFromText)
+	distance("topFromText", position.TopFromText)
+	distance("bottomFromText", position.BottomFromText)
+	text("vertAnchor", position.VerticalAnchor)
+	text("horzAnchor", position.HorizontalAnchor)
+	text("tblpXSpec", position.XSpec)
+	attrs = append(attrs, fmt.Sprintf(`w:tblpX="%d"`, position.X))
+	text("tblpYSpec", position.YSpec)
+	attrs = append(attrs, fmt.Sprintf(`w:tblpY="%d"`, position.Y))
+	return fmt.Sprintf(`<w:tblpPr %s/>`, strings.Join(attrs, " "))
+}
+
 func tableLookElement(look *TableLook) string {
 	if look == nil {
 		return ""
@@ -813,7 +1326,7 @@ func (t *Table) SetColumnWidths(widths ...int) {
 	t.gridColumns = len(widths)
 
 	for _, row := range t.rows {
-		col := 0
+		col := row.gridBefore
 		for _, cell := range row.cells {
 			span := cell.GridSpan()
 			if span < 1 {
@@ -849,7 +1362,7 @@ func (t *Table) columnWidths() []int {
 	filled := make([]bool, t.gridColumns)
 
 	for _, row := range t.rows {
-		col := 0
+		col := row.gridBefore
 		for _, cell := range row.cells {
 			span := cell.GridSpan()
 			if span < 1 {
@@ -918,6 +1431,142 @@ func (tr *TableRow) Cells() []*TableCell {
 	return tr.cells
 }
 
+// SetGridBefore leaves count grid columns empty before the first cell of the row, taking
+// up width twentieths of a point. Rows indented this way keep their cells aligned to the
+// table grid. A count of 0 removes the gap.
+func (tr *TableRow) SetGridBefore(count, width int) {
+	if count <= 0 {
+		count, width = 0, 0
+	}
+	tr.gridBefore = count
+	tr.widthBefore = width
+}
+
+// GridBefore returns the number of empty grid columns before the first cell and their width
+func (tr *TableRow) GridBefore() (count, width int) {
+	return tr.gridBefore, tr.widthBefore
+}
+
+// SetGridAfter leaves count grid columns empty after the last cell of the row, taking up
+// width twentieths of a point. A count of 0 removes the gap.
+func (tr *TableRow) SetGridAfter(count, width int) {
+	if count <= 0 {
+		count, width = 0, 0
+	}
+	tr.gridAfter = count
+	tr.widthAfter = width
+}
+
+// GridAfter returns the number of empty grid columns after the last cell and their width
+func (tr *TableRow) GridAfter() (count, width int) {
+	return tr.gridAfter, tr.widthAfter
+}
+
+// SetRepeatHeader marks the row as a header row repeated at the top of every page the
+// table continues on. Word only repeats header rows that start the table.
+func (tr *TableRow) SetRepeatHeader(repeat bool) {
+	tr.repeatHeader = repeat
+}
+
+// RepeatHeader reports whether the row repeats as a header on every page
+func (tr *TableRow) RepeatHeader() bool {
+	return tr.repeatHeader
+}
+
+// SetPropertyExceptions overrides table borders, cell margins or alignment for this row.
+// Overrides read from the document that the library does not model are kept.
+func (tr *TableRow) SetPropertyExceptions(exceptions TablePropertyExceptions) {
+	tr.exceptions = &exceptions
+}
+
+// PropertyExceptions returns the row's table property overrides, if any
+func (tr *TableRow) PropertyExceptions() (*TablePropertyExceptions, bool) {
+	if tr.exceptions == nil {
+		return nil, false
+	}
+	return tr.exceptions, true
+}
+
+// ClearPropertyExceptions removes every table property override from the row
+func (tr *TableRow) ClearPropertyExceptions() {
+	tr.exceptions = nil
+	tr.exceptionsRaw = nil
+}
+
+func (tr *TableRow) propertyExceptionsXML() string {
+	var props strings.Builder
+	for _, name := range tablePropertyExceptionsOrder {
+		ex := tr.exceptions
+		switch {
+		case name == "jc" && ex != nil && ex.Alignment != "":
+			props.WriteString(fmt.Sprintf(`<w:jc w:val="%s"/>`, xmlEscapeAttribute(string(ex.Alignment))))
+		case name == "tblBorders" && ex != nil && len(ex.Borders) > 0:
+			props.WriteString("<w:tblBorders>")
+			for _, side := range []TableBorderSide{TableBorderTop, TableBorderLeft, TableBorderBottom, TableBorderRight, TableBorderInsideH, TableBorderInsideV} {
+				if border, ok := ex.Borders[side]; ok && border.Style != "" {
+					props.WriteString(borderElement(string(side), &border))
+				}
+			}
+			props.WriteString("</w:tblBorders>")
+		case name == "tblCellMar" && ex != nil && ex.CellMargins != nil:
+			props.WriteString(cellMarginsElement("w:tblCellMar", ex.CellMargins))
+		default:
+			props.WriteString(tr.exceptionsRaw[name])
+		}
+	}
+	if props.Len() == 0 {
+		return ""
+	}
+	return "<w:tblPrEx>" + props.String() + "</w:tblPrEx>"
+}
+
+func (tr *TableRow) trPropertiesXML() string {
+	var props strings.Builder
+	for _, raw := range tr.rawPropertiesBefore {
+		props.WriteString(raw)
+	}
+	if tr.gridBefore > 0 {
+		props.WriteString(fmt.Sprintf(`<w:gridBefore w:val="%d"/>`, tr.gridBefore))
+	}
+	if tr.gridAfter > 0 {
+		props.WriteString(fmt.Sprintf(`<w:gridAfter w:val="%d"/>`, tr.gridAfter))
+	}
+	if tr.gridBefore > 0 && tr.widthBefore > 0 {
+		props.WriteString(fmt.Sprintf(`<w:wBefore w:w="%d" w:type="dxa"/>`, tr.widthBefore))
+	}
+	if tr.gridAfter > 0 && tr.widthAfter > 0 {
+		props.WriteString(fmt.Sprintf(`<w:wAfter w:w="%d" w:type="dxa"/>`, tr.widthAfter))
+	}
+	header := tr.repeatHeader
+	for _, raw := range tr.rawPropertiesAfter {
+		// w:tblHeader follows w:cantSplit and w:trHeight
+		if name := rawElementName(raw); header && name != "cantSplit" && name != "trHeight" {
+			props.WriteString("<w:tblHeader/>")
+			header = false
+		}
+		props.WriteString(raw)
+	}
+	if header {
+		props.WriteString("<w:tblHeader/>")
+	}
+	if props.Len() == 0 {
+		return ""
+	}
+	return "<w:trPr>" + props.String() + "</w:trPr>"
+}
+
+// rawElementName returns the local name of the element at the start of raw markup
+func rawElementName(raw string) string {
+	name := strings.TrimPrefix(raw, "<")
+	if end := strings.IndexAny(name, " />"); end >= 0 {
+		name = name[:end]
+	}
+	if colon := strings.IndexByte(name, ':'); colon >= 0 {
+		name = name[colon+1:]
+	}
+	return name
+}
+
 // Cell returns the cell at the specified index
 func (tr *TableRow) Cell(index int) *TableCell {
 	if index < 0 || index >= len(tr.cells) {
@@ -926,6 +1575,25 @@ func (tr *TableRow) Cell(index int) *TableCell {
 	return tr.cells[index]
 }
 
+// cellAtGridColumn returns the cell spanning the grid column and the grid column it starts at
+func (tr *TableRow) cellAtGridColumn(column int) (*TableCell, int) {
+	if column < 0 {
+		return nil, 0
+	}
+	start := tr.gridBefore
+	if column < start {
+		return nil, 0
+	}
+	for _, cell := range tr.cells {
+		span := cell.GridSpan()
+		if column < start+span {
+			return cell, start
+		}
+		start += span
+	}
+	return nil, 0
+}
+
 // GetCell is an alias for Cell() - returns the cell at the specified index
 func (tr *TableRow) GetCell(index int) *TableCell {
 	return tr.Cell(index)
@@ -939,7 +1607,7 @@ func (tr *TableRow) ToXML() string {
 		cellsXML.WriteString(cell.ToXML())
 	}
 
-	return fmt.Sprintf(`<w:tr>%s</w:tr>`, cellsXML.String())
+	return fmt.Sprintf(`<w:tr>%s%s%s</w:tr>`, tr.propertyExceptionsXML(), tr.trPropertiesXML(), cellsXML.String())
 }
 
 // Paragraphs returns all paragraphs in the cell
@@ -962,11 +1630,45 @@ func (tc *TableCell) AddParagraph(text ...string) *Paragraph {
 	for _, t := range text {
 		paragraph.AddRun(t)
 	}
+	tc.applyContentSpacing(paragraph)
 
 	tc.paragraphs = append(tc.paragraphs, paragraph)
 	return paragraph
 }
 
+// SetContentSpacing sets the spacing before and after (in twentieths of a point) of every
+// paragraph in the cell, including paragraphs added later through AddParagraph or SetText.
+// Use 0, 0 to remove the default paragraph padding in dense tables.
+func (tc *TableCell) SetContentSpacing(before, after int) {
+	tc.contentSpacing = &[2]int{before, after}
+	for _, paragraph := range tc.paragraphs {
+		tc.applyContentSpacing(paragraph)
+	}
+}
+
+// ContentSpacing returns the spacing configured with SetContentSpacing, if any.
+func (tc *TableCell) ContentSpacing() (before, after int, ok bool) {
+	if tc.contentSpacing == nil {
+		return 0, 0, false
+	}
+	return tc.contentSpacing[0], tc.contentSpacing[1], true
+}
+
+// ClearContentSpacing stops applying spacing to new paragraphs. Existing paragraphs keep theirs.
+func (tc *TableCell) ClearContentSpacing() {
+	tc.contentSpacing = nil
+}
+
+func (tc *TableCell) applyContentSpacing(paragraph *Paragraph) {
+	if tc.contentSpacing == nil || paragraph == nil {
+		return
+	}
+	paragraph.spacingBefore = tc.contentSpacing[0]
+	paragraph.spacingAfter = tc.contentSpacing[1]
+	paragraph.spacingBeforeSet = true
+	paragraph.spacingAfterSet = true
+}
+
 // AddTable appends a nested table to the cell content.
 func (tc *TableCell) AddTable(rows, cols int) *Table {
 	table := NewTable(rows, cols)
@@ -998,25 +1700,96 @@ func (t *Table) setOwner(owner *DocumentPart) {
 	}
 }
 
-// Text returns the combined text of all paragraphs in the cell
+// Text returns the text of the cell's paragraphs joined with newlines. Empty paragraphs,
+// such as the default paragraph of a new cell, are skipped.
 func (tc *TableCell) Text() string {
+	return tc.TextJoined("\n")
+}
+
+// TextJoined returns the text of the cell's non-empty paragraphs joined with sep
+func (tc *TableCell) TextJoined(sep string) string {
 	var text strings.Builder
-	for i, paragraph := range tc.paragraphs {
-		if i > 0 {
-			text.WriteString("\n")
+	for _, paragraph := range tc.paragraphs {
+		paragraphText := paragraph.Text()
+		if paragraphText == "" {
+			continue
 		}
-		text.WriteString(paragraph.Text())
+		if text.Len() > 0 {
+			text.WriteString(sep)
+		}
+		text.WriteString(paragraphText)
 	}
 	return text.String()
 }
 
 // SetText clears the cell and sets it to contain a single paragraph with the given text
 func (tc *TableCell) SetText(text string) {
+	paragraph := tc.newParagraph()
+	paragraph.AddRun(text)
+	tc.paragraphs = []*Paragraph{paragraph}
+}
+
+// SetTextMultiline clears the cell and fills it with one paragraph per line, so each line of
+// an address or note gets its own paragraph spacing. Newlines within a line start further
+// paragraphs. Without lines the cell keeps a single empty paragraph.
+func (tc *TableCell) SetTextMultiline(lines ...string) {
+	text := strings.ReplaceAll(strings.Join(lines, "\n"), "\r\n", "\n")
+	tc.paragraphs = nil
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n") {
+		paragraph := tc.newParagraph()
+		paragraph.AddRun(line)
+		tc.paragraphs = append(tc.paragraphs, paragraph)
+	}
+}
+
+// SetTextWithLineBreaks clears the cell and fills it with a single paragraph holding the
+// lines separated by line breaks, which keeps them tight without paragraph spacing.
+func (tc *TableCell) SetTextWithLineBreaks(lines ...string) {
+	paragraph := tc.newParagraph()
+	paragraph.AddRun("").SetMultilineText(strings.Join(lines, "\n"))
+	tc.paragraphs = []*Paragraph{paragraph}
+}
+
+// newParagraph returns an empty paragraph for the cell, with the cell's content spacing
+func (tc *TableCell) newParagraph() *Paragraph {
 	paragraph := NewParagraph()
 	if tc.row != nil && tc.row.table != nil {
 		paragraph.owner = tc.row.table.owner
 	}
-	paragraph.AddRun(text)
+	tc.applyContentSpacing(paragraph)
+	return paragraph
+}
+
+// SetTextKeepFormat replaces the cell text while keeping its formatting: the first paragraph
+// stays with its properties, and the first text run keeps its run properties and receives
+// text. Other paragraphs and runs are removed, and hyperlinks, breaks and field codes on the
+// kept run are dropped. Cell properties such as shading, borders and alignment are not
+// touched. A cell without paragraphs behaves like SetText.
+func (tc *TableCell) SetTextKeepFormat(text string) {
+	if len(tc.paragraphs) == 0 {
+		tc.SetText(text)
+		return
+	}
+	paragraph := tc.paragraphs[0]
+	var kept *Run
+	for _, run := range paragraph.runs {
+		if run.picture == nil && run.chart == nil && run.pict == "" {
+			kept = run
+			break
+		}
+	}
+	if kept == nil {
+		paragraph.runs = nil
+		paragraph.AddRun(text)
+	} else {
+		kept.hyperlinkURL = ""
+		kept.hyperlinkAnchor = ""
+		kept.hyperlinkHistory = false
+		kept.hasBreak = false
+		kept.fieldInstruction = ""
+		kept.SetText(text)
+		paragraph.runs = []*Run{kept}
+	}
 	tc.paragraphs = []*Paragraph{paragraph}
 }
 
@@ -1100,6 +1873,29 @@ func (tc *TableCell) ClearShading() {
 	tc.shading = nil
 }
 
+// SetMargins overrides the table-wide cell margins for this cell (twentieths of a point).
+func (tc *TableCell) SetMargins(top, left, bottom, right int) {
+	tc.margins = &TableCellMargins{
+		Top:    intPtr(top),
+		Left:   intPtr(left),
+		Bottom: intPtr(bottom),
+		Right:  intPtr(right),
+	}
+}
+
+// Margins returns the cell-level margins if set.
+func (tc *TableCell) Margins() (*TableCellMargins, bool) {
+	if tc.margins == nil {
+		return nil, false
+	}
+	return tc.margins, true
+}
+
+// ClearMargins removes the cell-level margins so the table defaults apply.
+func (tc *TableCell) ClearMargins() {
+	tc.margins = nil
+}
+
 // SetBorder configures a border for the cell.
 func (tc *TableCell) SetBorder(side TableBorderSide, border TableBorder) {
 	if side == "" {
@@ -1147,20 +1943,21 @@ func (tc *TableCell) tcPropertiesXML() string {
 	case TableVerticalMergeContinue:
 		builder.WriteString(`<w:vMerge w:val="continue"/>`)
 	}
-	if tc.verticalAlign != WDVerticalAlignmentTop {
-		switch tc.verticalAlign {
-		case WDVerticalAlignmentCenter:
-			builder.WriteString(`<w:vAlign w:val="center"/>`)
-		case WDVerticalAlignmentBottom:
-			builder.WriteString(`<w:vAlign w:val="bottom"/>`)
-		}
-	}
 	if tc.hasBorders() {
 		builder.WriteString(tc.bordersXML())
 	}
 	if tc.hasShading() {
 		builder.WriteString(shadingElement(tc.shading))
 	}
+	if tc.margins != nil {
+		builder.WriteString(cellMarginsElement("w:tcMar", tc.margins))
+	}
+	switch tc.verticalAlign {
+	case WDVerticalAlignmentCenter:
+		builder.WriteString(`<w:vAlign w:val="center"/>`)
+	case WDVerticalAlignmentBottom:
+		builder.WriteString(`<w:vAlign w:val="bottom"/>`)
+	}
 	builder.WriteString("</w:tcPr>")
 	return builder.String()
 }
@@ -0,0 +1,407 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// templateTokenPattern matches a {{key}} placeholder; keys may be dotted paths into nested maps
+var templateTokenPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-]+(?:\.[A-Za-z0-9_\-]+)*)\s*\}\}`)
+
+// TemplateImage is a RenderTemplate value that replaces a picture placeholder with the
+// image file at Path. The placeholder keeps its size.
+type TemplateImage struct {
+	Path string
+}
+
+// RenderTemplate opens the template at templatePath and fills it with data in one step,
+// returning a document detached from the template file, which is never written to; save
+// the result with SaveAs.
+//
+//   - {{key}} tokens in paragraphs, tables, headers and footers are replaced by the text of
+//     data[key], even when Word split the token over several runs. Dotted keys such as
+//     {{customer.name}} look up nested maps. Tokens without a value are left in place.
+//   - A table row holding a {{items.field}} token is repeated for each element of data["items"],
+//     a slice of maps, with the tokens filled from that element. An empty slice removes the row.
+//   - A picture whose alt text (or name) is {{key}} shows the image of a TemplateImage value.
+//
+// Content controls are read as their content, so tokens inside them are filled like any other
+// text, but a control is not repeated for a slice value; put repeated content in a table row.
+func RenderTemplate(templatePath string, data map[string]any) (*Document, error) {
+	doc, err := OpenDocument(templatePath)
+	if err != nil {
+		return nil, err
+	}
+	// every part is loaded into memory, so the template file is no longer needed
+	if err := doc.Close(); err != nil {
+		return nil, err
+	}
+	doc.pkg.zipReader = nil
+	doc.pkg.filePath = ""
+
+	if err := doc.fillTemplate(data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", templatePath, err)
+	}
+	return doc, nil
+}
+
+// fillTemplate applies data to the document as described for RenderTemplate
+func (d *Document) fillTemplate(data map[string]any) error {
+	if d.docPart == nil {
+		return fmt.Errorf("%w: document has no main document part", ErrPartNotFound)
+	}
+	resolve := func(key string) (any, bool) {
+		return lookupTemplateValue(data, key)
+	}
+
+	for _, table := range d.docPart.allTables() {
+		if err := fillTemplateRows(table, resolve); err != nil {
+			return err
+		}
+	}
+	// pictures reference their image through the relationships of the part holding them
+	type story struct {
+		partURI    string
+		paragraphs []*Paragraph
+	}
+	stories := []story{{d.docPart.Part.URI, appendStoryParagraphs(nil, d.docPart.bodyElements)}}
+	d.docPart.visitHeadersAndFooters(func(header *Header) {
+		stories = append(stories, story{header.part.URI, appendStoryParagraphs(nil, header.bodyElements)})
+	}, func(footer *Footer) {
+		stories = append(stories, story{footer.part.URI, appendStoryParagraphs(nil, footer.bodyElements)})
+	})
+	replacedImage := false
+	for _, story := range stories {
+		for _, paragraph := range story.paragraphs {
+			fillTemplateParagraph(paragraph, resolve)
+			changed, err := fillTemplateImages(paragraph, story.partURI, resolve)
+			if err != nil {
+				return err
+			}
+			replacedImage = replacedImage || changed
+		}
+	}
+
+	d.updateStories()
+	if replacedImage {
+		d.pkg.pruneUnusedImages()
+	}
+	return nil
+}
+
+// lookupTemplateValue resolves a dotted key through nested maps
+func lookupTemplateValue(data map[string]any, key string) (any, bool) {
+	var value any = data
+	for _, name := range strings.Split(key, ".") {
+		switch fields := value.(type) {
+		case map[string]any:
+			next, ok := fields[name]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case map[string]string:
+			next, ok := fields[name]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// templateText returns the text a value is rendered as; ok is false for values that are
+// not rendered as text, such as slices and images
+func templateText(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	case TemplateImage, *TemplateImage, []any, []map[string]any, []map[string]string, map[string]any, map[string]string:
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// templateItems returns the elements of a slice value used to repeat a table row
+func templateItems(value any) ([]map[string]any, bool) {
+	switch v := value.(type) {
+	case []map[string]any:
+		return v, true
+	case []map[string]string:
+		items := make([]map[string]any, len(v))
+		for i, item := range v {
+			items[i] = make(map[string]any, len(item))
+			for key, text := range item {
+				items[i][key] = text
+			}
+		}
+		return items, true
+	case []any:
+		items := make([]map[string]any, 0, len(v))
+		for _, element := range v {
+			item, ok := element.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			items = append(items, item)
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// fillTemplateParagraph replaces the tokens of the paragraph that resolve to text. The value
+// takes the place of the token in the run where the token starts, keeping that run's
+// formatting; the rest of a token split over runs is removed from the following runs.
+func fillTemplateParagraph(paragraph *Paragraph, resolve func(string) (any, bool)) {
+	var full strings.Builder
+	starts := make([]int, len(paragraph.runs))
+	for i, run := range paragraph.runs {
+		starts[i] = full.Len()
+		full.WriteString(run.text)
+	}
+	text := full.String()
+	if !strings.Contains(text, "{{") {
+		return
+	}
+
+	// runAt returns the run holding the byte at offset and the offset within that run
+	runAt := func(offset int) (int, int) {
+		index := 0
+		for i := range paragraph.runs {
+			if starts[i] <= offset && len(paragraph.runs[i].text) > 0 {
+				index = i
+			}
+		}
+		return index, offset - starts[index]
+	}
+
+	matches := templateTokenPattern.FindAllStringSubmatchIndex(text, -1)
+	// replace from the end so offsets of earlier tokens stay valid
+	for m := len(matches) - 1; m >= 0; m-- {
+		match := matches[m]
+		value, ok := resolve(text[match[2]:match[3]])
+		if !ok {
+			continue
+		}
+		replacement, ok := templateText(value)
+		if !ok {
+			continue
+		}
+		first, from := runAt(match[0])
+		last, to := runAt(match[1] - 1)
+		to++
+		if first == last {
+			run := paragraph.runs[first]
+			run.SetText(run.text[:from] + replacement + run.text[to:])
+			continue
+		}
+		for i := first + 1; i < last; i++ {
+			paragraph.runs[i].text = ""
+		}
+		paragraph.runs[last].SetText(paragraph.runs[last].text[to:])
+		paragraph.runs[first].SetText(paragraph.runs[first].text[:from] + replacement)
+	}
+}
+
+// fillTemplateImages swaps the image of pictures whose alt text or name is a token
+// resolving to a TemplateImage. partURI is the part holding the paragraph.
+func fillTemplateImages(paragraph *Paragraph, partURI string, resolve func(string) (any, bool)) (bool, error) {
+	changed := false
+	for _, run := range paragraph.runs {
+		picture := run.picture
+		if picture == nil || picture.docPart == nil {
+			continue
+		}
+		var value any
+		found := false
+		for _, label := range []string{picture.description, picture.name} {
+			match := templateTokenPattern.FindStringSubmatch(strings.TrimSpace(label))
+			if match != nil && match[0] == strings.TrimSpace(label) {
+				if value, found = resolve(match[1]); found {
+					break
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+		var image TemplateImage
+		switch v := value.(type) {
+		case TemplateImage:
+			image = v
+		case *TemplateImage:
+			if v == nil {
+				continue
+			}
+			image = *v
+		default:
+			continue
+		}
+
+		data, err := os.ReadFile(image.Path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read image %s: %w", image.Path, err)
+		}
+		replacement, err := picture.docPart.addPictureToPart(partURI, data, filepath.Base(image.Path), picture.widthEMU, picture.heightEMU, PictureOptions{})
+		if err != nil {
+			return false, err
+		}
+		picture.relID = replacement.relID
+		picture.target = replacement.target
+		picture.linkRelID = ""
+		picture.linkTarget = ""
+		picture.description = replacement.description
+		changed = true
+	}
+	return changed, nil
+}
+
+// fillTemplateRows repeats each row of the table (and of nested tables) that holds a token
+// for a slice value, once per element
+func fillTemplateRows(table *Table, resolve func(string) (any, bool)) error {
+	for i := 0; i < len(table.rows); i++ {
+		row := table.rows[i]
+		if row == nil {
+			continue
+		}
+		key, items, ok := templateRowKey(row, resolve)
+		if !ok {
+			for _, cell := range row.cells {
+				for _, nested := range cell.tables {
+					if err := fillTemplateRows(nested, resolve); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		err := table.expandRow(i, len(items), func(n int) func(string) (any, bool) {
+			return func(name string) (any, bool) {
+				if strings.HasPrefix(name, key+".") {
+					return lookupTemplateValue(items[n], strings.TrimPrefix(name, key+"."))
+				}
+				return resolve(name)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		i += len(items) - 1
+	}
+	return nil
+}
+
+// ExpandRowTemplate replaces the row at rowIndex with one copy per record, filling the
+// {{field}} tokens of each copy from its record, so a single template row becomes one line
+// item per record. Copies keep the formatting of the template row, and tokens the record has
+// no value for are left in place. Only the first copy keeps the w14:paraId and w14:textId of
+// the template row's paragraphs. Passing no records removes the row.
+func (t *Table) ExpandRowTemplate(rowIndex int, records []map[string]string) error {
+	if rowIndex < 0 || rowIndex >= len(t.rows) || t.rows[rowIndex] == nil {
+		return fmt.Errorf("%w: row index %d out of range", ErrIndexOutOfRange, rowIndex)
+	}
+	return t.expandRow(rowIndex, len(records), func(n int) func(string) (any, bool) {
+		return func(name string) (any, bool) {
+			value, ok := records[n][name]
+			return value, ok
+		}
+	})
+}
+
+// expandRow replaces the row at index with count copies, filling the tokens of copy n with
+// the values resolved by resolver(n)
+func (t *Table) expandRow(index, count int, resolver func(n int) func(string) (any, bool)) error {
+	rows := make([]*TableRow, 0, count)
+	for n := 0; n < count; n++ {
+		clone, err := cloneTableRow(t.rows[index])
+		if err != nil {
+			return err
+		}
+		resolve := resolver(n)
+		for _, paragraph := range rowParagraphs(clone) {
+			// w14:paraId and w14:textId must be unique in the document, so only the first
+			// copy keeps the stamps of the template row
+			if n > 0 {
+				paragraph.paraID, paragraph.textID = "", ""
+			}
+			fillTemplateParagraph(paragraph, resolve)
+		}
+		rows = append(rows, clone)
+	}
+	t.rows = append(t.rows[:index], append(rows, t.rows[index+1:]...)...)
+	return nil
+}
+
+// templateRowKey returns the key of the first slice value referenced by a token in the row
+func templateRowKey(row *TableRow, resolve func(string) (any, bool)) (string, []map[string]any, bool) {
+	for _, paragraph := range rowParagraphs(row) {
+		for _, match := range templateTokenPattern.FindAllStringSubmatch(paragraph.Text(), -1) {
+			parts := strings.Split(match[1], ".")
+			for n := len(parts) - 1; n > 0; n-- {
+				key := strings.Join(parts[:n], ".")
+				value, ok := resolve(key)
+				if !ok {
+					continue
+				}
+				if items, ok := templateItems(value); ok {
+					return key, items, true
+				}
+			}
+		}
+	}
+	return "", nil, false
+}
+
+func rowParagraphs(row *TableRow) []*Paragraph {
+	var paragraphs []*Paragraph
+	for _, cell := range row.cells {
+		if cell == nil {
+			continue
+		}
+		paragraphs = append(paragraphs, cell.paragraphs...)
+		for _, nested := range cell.tables {
+			paragraphs = appendTableParagraphs(paragraphs, nested)
+		}
+	}
+	return paragraphs
+}
+
+// cloneTableRow returns a deep copy of the row by parsing its generated XML
+func cloneTableRow(row *TableRow) (*TableRow, error) {
+	var dp *DocumentPart
+	if row.table != nil {
+		dp = row.table.owner
+	}
+	root := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`
+	if dp != nil {
+		root = dp.rootStartElement()
+	}
+	decoder := xml.NewDecoder(strings.NewReader(root + row.ToXML() + "</w:document>"))
+	decoder.Strict = false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("failed to copy table row")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy table row: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "tr" {
+			return parseTableRow(decoder, start, row.table, dp)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const wordprocessingMLNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// ExtractText returns the plain text of the main document body without building the
+// document object model. It streams word/document.xml and keeps only w:t content, which
+// makes it considerably cheaper than OpenDocument when indexing many files.
+//
+// Paragraphs are separated by "\n". In tables, cells are separated by "\t" and rows by
+// "\n"; tabs and line breaks inside runs become "\t" and "\n". Deleted text, field codes
+// and fallback content of alternate content blocks are skipped.
+func ExtractText(path string) (string, error) {
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		if isCompoundFile(path) {
+			return "", ErrEncryptedDocument
+		}
+		if _, statErr := os.Stat(path); statErr != nil {
+			return "", fmt.Errorf("failed to open zip file: %w", err)
+		}
+		return "", fmt.Errorf("%w: %v", ErrNotADocx, err)
+	}
+	defer zipReader.Close()
+
+	files := make(map[string]*zip.File, len(zipReader.File))
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	documentURI := "word/document.xml"
+	if relsFile, ok := files["_rels/.rels"]; ok {
+		data, err := readZipFile(relsFile)
+		if err != nil {
+			return "", err
+		}
+		rels, err := parseRelationships(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse package relationships: %w", err)
+		}
+		for _, rel := range rels {
+			if rel.Type == RelTypeOfficeDocument {
+				documentURI = resolveRelationshipTarget("", rel.Target)
+				break
+			}
+		}
+	}
+
+	documentFile, ok := files[documentURI]
+	if !ok {
+		return "", fmt.Errorf("%w: missing main document part", ErrNotADocx)
+	}
+	rc, err := documentFile.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", documentURI, err)
+	}
+	defer rc.Close()
+
+	text, err := streamDocumentText(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read text from %s: %w", documentURI, err)
+	}
+	return text, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+	}
+	return data, nil
+}
+
+// textBlock tracks how many children have been written to the body, a table, a row or a
+// cell so separators are only written between siblings.
+type textBlock struct {
+	kind  string
+	count int
+}
+
+// streamDocumentText writes the text of a WordprocessingML story while decoding it. It reads
+// raw tokens, skipping namespace translation, and matches elements by the prefix the root
+// element binds to the WordprocessingML namespace.
+func streamDocumentText(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	prefix := ""
+	rootSeen := false
+
+	var builder strings.Builder
+	blocks := []textBlock{{kind: "body"}}
+	paragraphDepth, runDepth := 0, 0
+	inText := false
+
+	// separate starts a child of the innermost block of the given kind
+	separate := func(kind, separator string) {
+		top := &blocks[len(blocks)-1]
+		if top.kind == kind && top.count > 0 {
+			builder.WriteString(separator)
+		}
+		top.count++
+	}
+
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !rootSeen {
+				rootSeen = true
+				prefix = wordprocessingMLPrefix(t.Attr)
+			}
+			if t.Name.Local == "Fallback" {
+				if err := skipRawElement(decoder); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if t.Name.Space != prefix {
+				continue
+			}
+			switch t.Name.Local {
+			case "tbl":
+				if paragraphDepth == 0 {
+					separate("body", "\n")
+				}
+				blocks = append(blocks, textBlock{kind: "tbl"})
+			case "tr":
+				separate("tbl", "\n")
+				blocks = append(blocks, textBlock{kind: "tr"})
+			case "tc":
+				separate("tr", "\t")
+				blocks = append(blocks, textBlock{kind: "body"})
+			case "p":
+				if paragraphDepth > 0 {
+					builder.WriteString("\n")
+				} else {
+					separate("body", "\n")
+				}
+				paragraphDepth++
+			case "r":
+				runDepth++
+			case "t":
+				inText = runDepth > 0
+			case "tab":
+				if runDepth > 0 {
+					builder.WriteString("\t")
+				}
+			case "br", "cr":
+				if runDepth > 0 {
+					builder.WriteString("\n")
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != prefix {
+				continue
+			}
+			switch t.Name.Local {
+			case "tbl", "tr", "tc":
+				if len(blocks) > 1 {
+					blocks = blocks[:len(blocks)-1]
+				}
+			case "p":
+				if paragraphDepth > 0 {
+					paragraphDepth--
+				}
+			case "r":
+				if runDepth > 0 {
+					runDepth--
+				}
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// wordprocessingMLPrefix returns the prefix bound to the WordprocessingML namespace by the
+// root element attributes, defaulting to "w".
+func wordprocessingMLPrefix(attrs []xml.Attr) string {
+	for _, attr := range attrs {
+		if attr.Value != wordprocessingMLNamespace {
+			continue
+		}
+		if attr.Name.Space == "xmlns" {
+			return attr.Name.Local
+		}
+		if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			return ""
+		}
+	}
+	return "w"
+}
+
+// skipRawElement consumes raw tokens up to the end of the element just started
+func skipRawElement(decoder *xml.Decoder) error {
+	depth := 1
+	for depth > 0 {
+		tok, err := decoder.RawToken()
+		if err != nil {
+			return err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
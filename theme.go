@@ -0,0 +1,158 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Theme holds the color and font schemes of the document theme (word/theme/theme1.xml).
+type Theme struct {
+	Name            string
+	ColorSchemeName string
+	// Colors maps scheme slots (dk1, lt1, dk2, lt2, accent1-accent6, hlink, folHlink) to hex RGB values.
+	Colors         map[string]string
+	FontSchemeName string
+	MajorFont      ThemeFonts
+	MinorFont      ThemeFonts
+}
+
+// ThemeFonts lists the typefaces of a major (headings) or minor (body) theme font.
+type ThemeFonts struct {
+	Latin         string
+	EastAsian     string
+	ComplexScript string
+}
+
+// themeColorSlots maps w:themeColor values used by runs to color scheme slots.
+var themeColorSlots = map[string]string{
+	"dark1":             "dk1",
+	"light1":            "lt1",
+	"dark2":             "dk2",
+	"light2":            "lt2",
+	"text1":             "dk1",
+	"background1":       "lt1",
+	"text2":             "dk2",
+	"background2":       "lt2",
+	"hyperlink":         "hlink",
+	"followedHyperlink": "folHlink",
+}
+
+// Color resolves a theme color name to its hex RGB value. Both scheme slots ("dk1", "accent1")
+// and w:themeColor values ("text1", "background2", "hyperlink") are accepted.
+func (t *Theme) Color(name string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	if slot, ok := themeColorSlots[name]; ok {
+		name = slot
+	}
+	color, ok := t.Colors[name]
+	return color, ok
+}
+
+// Theme returns the document theme parsed from the theme part. It reports false when the
+// document has no theme or the theme cannot be parsed.
+func (d *Document) Theme() (*Theme, bool) {
+	if d.docPart == nil {
+		return nil, false
+	}
+	theme, err := d.pkg.theme(d.docPart.Part.URI)
+	if err != nil || theme == nil {
+		return nil, false
+	}
+	return theme, true
+}
+
+type themeXML struct {
+	Name     string `xml:"name,attr"`
+	Elements struct {
+		ColorScheme struct {
+			Name   string          `xml:"name,attr"`
+			Colors []themeColorXML `xml:",any"`
+		} `xml:"clrScheme"`
+		FontScheme struct {
+			Name      string        `xml:"name,attr"`
+			MajorFont themeFontsXML `xml:"majorFont"`
+			MinorFont themeFontsXML `xml:"minorFont"`
+		} `xml:"fontScheme"`
+	} `xml:"themeElements"`
+}
+
+type themeColorXML struct {
+	XMLName xml.Name
+	SRGB    *struct {
+		Val string `xml:"val,attr"`
+	} `xml:"srgbClr"`
+	System *struct {
+		Val     string `xml:"val,attr"`
+		LastClr string `xml:"lastClr,attr"`
+	} `xml:"sysClr"`
+}
+
+type themeFontsXML struct {
+	Latin struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"latin"`
+	EastAsian struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"ea"`
+	ComplexScript struct {
+		Typeface string `xml:"typeface,attr"`
+	} `xml:"cs"`
+}
+
+func (f themeFontsXML) fonts() ThemeFonts {
+	return ThemeFonts{
+		Latin:         f.Latin.Typeface,
+		EastAsian:     f.EastAsian.Typeface,
+		ComplexScript: f.ComplexScript.Typeface,
+	}
+}
+
+func (p *Package) themeURI(docURI string) string {
+	for _, rel := range p.relations[docURI] {
+		if rel.Type == RelTypeTheme {
+			return resolveRelationshipTarget(docURI, rel.Target)
+		}
+	}
+	return "word/theme/theme1.xml"
+}
+
+func (p *Package) theme(docURI string) (*Theme, error) {
+	part, ok := p.parts[p.themeURI(docURI)]
+	if !ok || len(part.Data) == 0 {
+		return nil, nil
+	}
+	return parseTheme(part.Data)
+}
+
+func parseTheme(data []byte) (*Theme, error) {
+	var parsed themeXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse theme: %w", err)
+	}
+
+	theme := &Theme{
+		Name:            parsed.Name,
+		ColorSchemeName: parsed.Elements.ColorScheme.Name,
+		Colors:          make(map[string]string),
+		FontSchemeName:  parsed.Elements.FontScheme.Name,
+		MajorFont:       parsed.Elements.FontScheme.MajorFont.fonts(),
+		MinorFont:       parsed.Elements.FontScheme.MinorFont.fonts(),
+	}
+	for _, color := range parsed.Elements.ColorScheme.Colors {
+		var value string
+		switch {
+		case color.SRGB != nil:
+			value = color.SRGB.Val
+		case color.System != nil:
+			// System colors carry the last resolved RGB value alongside the system name.
+			value = color.System.LastClr
+		}
+		if value != "" {
+			theme.Colors[color.XMLName.Local] = strings.ToUpper(value)
+		}
+	}
+	return theme, nil
+}
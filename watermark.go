@@ -0,0 +1,247 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WatermarkOptions controls the appearance of a text watermark.
+type WatermarkOptions struct {
+	Font       string  // font family, defaults to "Calibri"
+	Color      string  // hex fill color, defaults to "C0C0C0"
+	Opacity    float64 // fill opacity between 0 and 1, defaults to 0.5
+	Horizontal bool    // lay the text out horizontally instead of diagonally
+	Width      float64 // shape width in points, defaults to 468
+	Height     float64 // shape height in points, defaults to 117
+}
+
+// Watermark describes a watermark found in a section header.
+type Watermark struct {
+	Text        string // watermark text, empty for picture watermarks
+	ImageTarget string // relationship target of the watermark image, empty for text watermarks
+}
+
+const (
+	textWatermarkID    = "PowerPlusWaterMarkObject"
+	pictureWatermarkID = "WordPictureWatermark"
+)
+
+// SetTextWatermark places a text watermark (e.g. "DRAFT") behind the page by adding a VML
+// shape to the section's default header, creating the header if needed. Any existing
+// watermark in that header is replaced.
+func (s *Section) SetTextWatermark(text string, opts WatermarkOptions) error {
+	if text == "" {
+		return fmt.Errorf("%w: watermark text must not be empty", ErrInvalidArgument)
+	}
+	header, err := s.Header()
+	if err != nil {
+		return err
+	}
+
+	font := opts.Font
+	if font == "" {
+		font = "Calibri"
+	}
+	color := strings.TrimPrefix(opts.Color, "#")
+	if color == "" {
+		color = "C0C0C0"
+	}
+	opacity := opts.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 0.5
+	}
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = 468
+	}
+	if height <= 0 {
+		height = 117
+	}
+	rotation := ";rotation:315"
+	if opts.Horizontal {
+		rotation = ""
+	}
+
+	id := s.owner.nextDrawingID()
+	var builder strings.Builder
+	builder.WriteString(`<w:pict>`)
+	builder.WriteString(`<v:shapetype id="_x0000_t136" coordsize="21600,21600" o:spt="136" adj="10800" path="m@7,l@8,m@5,21600l@6,21600e">`)
+	builder.WriteString(`<v:formulas>`)
+	for _, eqn := range []string{"sum #0 0 10800", "prod #0 2 1", "sum 21600 0 @1", "sum 0 0 @2", "sum 21600 0 @3", "if @0 @3 0", "if @0 21600 @1", "if @0 0 @2", "if @0 @4 21600", "mid @5 @6", "mid @8 @5", "mid @7 @8", "mid @6 @7", "sum @6 0 @5"} {
+		builder.WriteString(fmt.Sprintf(`<v:f eqn="%s"/>`, eqn))
+	}
+	builder.WriteString(`</v:formulas>`)
+	builder.WriteString(`<v:path textpathok="t" o:connecttype="custom" o:connectlocs="@9,0;@10,10800;@11,21600;@12,10800" o:connectangles="270,180,90,0"/>`)
+	builder.WriteString(`<v:textpath on="t" fitshape="t"/>`)
+	builder.WriteString(`<v:handles><v:h position="#0,bottomRight" xrange="6629,14971"/></v:handles>`)
+	builder.WriteString(`<o:lock v:ext="edit" text="t" shapetype="t"/>`)
+	builder.WriteString(`</v:shapetype>`)
+	builder.WriteString(fmt.Sprintf(`<v:shape id="%s%d" o:spid="_x0000_s%d" type="#_x0000_t136" style="%s" o:allowincell="f" fillcolor="#%s" stroked="f">`,
+		textWatermarkID, id, 2048+id, watermarkStyle(width, height, rotation), xmlEscapeAttribute(color)))
+	builder.WriteString(fmt.Sprintf(`<v:fill opacity="%s"/>`, strconv.FormatFloat(opacity, 'f', -1, 64)))
+	builder.WriteString(fmt.Sprintf(`<v:textpath style="font-family:&quot;%s&quot;;font-size:1pt" string="%s"/>`, xmlEscapeAttribute(font), xmlEscapeAttribute(text)))
+	builder.WriteString(`<w10:wrap anchorx="margin" anchory="margin"/>`)
+	builder.WriteString(`</v:shape>`)
+	builder.WriteString(`</w:pict>`)
+
+	header.setWatermarkXML(builder.String())
+	return nil
+}
+
+// SetImageWatermark places a washed-out picture behind the page using the image at path.
+// The picture is added to the section's default header, replacing any existing watermark.
+func (s *Section) SetImageWatermark(imagePath string) error {
+	header, err := s.Header()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read image %s: %w", imagePath, err)
+	}
+	ext := strings.ToLower(filepath.Ext(imagePath))
+	contentType, ok := imageContentTypes[ext]
+	if !ok {
+		return fmt.Errorf("%w: unsupported image format: %s", ErrUnsupportedImage, ext)
+	}
+	widthEMU, heightEMU, err := decodeImageDimensionsEMU(data)
+	if err != nil {
+		return err
+	}
+
+	pkg := s.owner.pkg
+	partURI, err := pkg.addImagePart(data, ext, contentType)
+	if err != nil {
+		return err
+	}
+	target := strings.TrimPrefix(partURI, path.Dir(header.part.URI)+"/")
+	relID := pkg.ensureRelationship(header.part.URI, RelTypeImage, target)
+
+	width := float64(widthEMU) / EMUsPerPoint
+	height := float64(heightEMU) / EMUsPerPoint
+	id := s.owner.nextDrawingID()
+	title := strings.TrimSuffix(filepath.Base(imagePath), filepath.Ext(imagePath))
+
+	var builder strings.Builder
+	builder.WriteString(`<w:pict>`)
+	builder.WriteString(`<v:shapetype id="_x0000_t75" coordsize="21600,21600" o:spt="75" o:preferrelative="t" filled="f" stroked="f">`)
+	builder.WriteString(`<v:stroke joinstyle="miter"/><v:path o:extrusionok="f" gradientshapeok="t" o:connecttype="rect"/><o:lock v:ext="edit" aspectratio="t"/>`)
+	builder.WriteString(`</v:shapetype>`)
+	builder.WriteString(fmt.Sprintf(`<v:shape id="%s%d" o:spid="_x0000_s%d" type="#_x0000_t75" style="%s" o:allowincell="f">`,
+		pictureWatermarkID, id, 2048+id, watermarkStyle(width, height, "")))
+	builder.WriteString(fmt.Sprintf(`<v:imagedata r:id="%s" o:title="%s" gain="19661f" blacklevel="22938f"/>`, relID, xmlEscapeAttribute(title)))
+	builder.WriteString(`<w10:wrap anchorx="margin" anchory="margin"/>`)
+	builder.WriteString(`</v:shape>`)
+	builder.WriteString(`</w:pict>`)
+
+	header.setWatermarkXML(builder.String())
+	return nil
+}
+
+// Watermark returns the watermark in the section's default header, if any.
+func (s *Section) Watermark() (*Watermark, bool) {
+	header := s.defaultHeader()
+	if header == nil {
+		return nil, false
+	}
+	for _, paragraph := range header.paragraphs {
+		for _, run := range paragraph.runs {
+			if watermark := parseWatermark(run.pict); watermark != nil {
+				if watermark.ImageTarget != "" && s.owner != nil && s.owner.pkg != nil {
+					for _, rel := range s.owner.pkg.relations[header.part.URI] {
+						if rel.ID == watermark.ImageTarget {
+							watermark.ImageTarget = rel.Target
+							break
+						}
+					}
+				}
+				return watermark, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ClearWatermark removes the watermark from the section's default header.
+func (s *Section) ClearWatermark() {
+	if header := s.defaultHeader(); header != nil {
+		header.setWatermarkXML("")
+	}
+}
+
+func (s *Section) defaultHeader() *Header {
+	if s == nil {
+		return nil
+	}
+	ref, ok := s.headerRefs[HeaderTypeDefault]
+	if !ok || ref == nil {
+		return nil
+	}
+	return ref.header
+}
+
+func watermarkStyle(width, height float64, rotation string) string {
+	return fmt.Sprintf("position:absolute;margin-left:0;margin-top:0;width:%spt;height:%spt%s;z-index:-251657216;mso-position-horizontal:center;mso-position-horizontal-relative:margin;mso-position-vertical:center;mso-position-vertical-relative:margin",
+		strconv.FormatFloat(width, 'f', -1, 64), strconv.FormatFloat(height, 'f', -1, 64), rotation)
+}
+
+// setWatermarkXML drops existing watermark runs from the header and, when pict is not empty,
+// adds a run carrying it to the first header paragraph.
+func (h *Header) setWatermarkXML(pict string) {
+	for _, paragraph := range h.paragraphs {
+		runs := paragraph.runs[:0]
+		for _, run := range paragraph.runs {
+			if parseWatermark(run.pict) == nil {
+				runs = append(runs, run)
+			}
+		}
+		paragraph.runs = runs
+	}
+	if pict != "" {
+		var paragraph *Paragraph
+		if len(h.paragraphs) > 0 {
+			paragraph = h.paragraphs[0]
+		} else {
+			paragraph = h.AddParagraph()
+		}
+		run := paragraph.AddRun("")
+		run.pict = pict
+	}
+	h.updateXMLData()
+}
+
+// parseWatermark inspects raw w:pict XML and returns the watermark it describes, or nil.
+// For picture watermarks ImageTarget holds the relationship ID until resolved by the caller.
+func parseWatermark(pict string) *Watermark {
+	if !strings.Contains(pict, textWatermarkID) && !strings.Contains(pict, pictureWatermarkID) {
+		return nil
+	}
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(pict)))
+	decoder.Strict = false
+	watermark := &Watermark{}
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "textpath":
+			if text := attrValue(start.Attr, "string"); text != "" {
+				watermark.Text = text
+			}
+		case "imagedata":
+			watermark.ImageTarget = attrValue(start.Attr, "id")
+		}
+	}
+	return watermark
+}
@@ -0,0 +1,139 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xmlIndent is the indentation unit used when pretty-printing parts
+const xmlIndent = "  "
+
+// formatXML rewrites an XML part either indented or compact. Whitespace between elements
+// is dropped, while the content of text-bearing elements (e.g. a w:t holding a single
+// space) and elements with mixed content is kept verbatim, so the document renders the
+// same either way. Namespace prefixes are preserved as written.
+func formatXML(data []byte, indent bool) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var tokens []xml.Token
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+
+	// mark elements whose direct content includes text so their layout is left alone
+	mixed := make(map[int]bool)
+	var open []int
+	for i, tok := range tokens {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, i)
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case xml.CharData:
+			if len(open) > 0 && len(bytes.TrimSpace(t)) > 0 {
+				mixed[open[len(open)-1]] = true
+			}
+		}
+	}
+
+	var builder strings.Builder
+	depth := 0
+	hadChildren := []bool{false}
+	inMixed := []bool{false}
+	newline := func() {
+		if indent && !inMixed[len(inMixed)-1] && builder.Len() > 0 {
+			builder.WriteString("\n" + strings.Repeat(xmlIndent, depth))
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			hadChildren[len(hadChildren)-1] = true
+			newline()
+			writeRawStartElement(&builder, t)
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					builder.WriteString("/>")
+					i++
+					continue
+				}
+			}
+			builder.WriteByte('>')
+			depth++
+			hadChildren = append(hadChildren, false)
+			inMixed = append(inMixed, inMixed[len(inMixed)-1] || mixed[i])
+		case xml.EndElement:
+			depth--
+			if hadChildren[len(hadChildren)-1] {
+				newline()
+			}
+			hadChildren = hadChildren[:len(hadChildren)-1]
+			inMixed = inMixed[:len(inMixed)-1]
+			builder.WriteString("</" + rawName(t.Name) + ">")
+		case xml.CharData:
+			if depth == 0 {
+				continue
+			}
+			if len(bytes.TrimSpace(t)) == 0 && !inMixed[len(inMixed)-1] && !isLeafContent(tokens, i) {
+				continue
+			}
+			builder.WriteString(escapeCharData(string(t)))
+		case xml.ProcInst:
+			builder.WriteString("<?" + t.Target)
+			if len(t.Inst) > 0 {
+				builder.WriteString(" " + string(t.Inst))
+			}
+			builder.WriteString("?>")
+			if depth == 0 && t.Target == "xml" {
+				builder.WriteString("\n")
+			}
+		case xml.Comment:
+			newline()
+			builder.WriteString("<!--" + string(t) + "-->")
+		case xml.Directive:
+			builder.WriteString("<!" + string(t) + ">")
+		}
+	}
+	return []byte(builder.String()), nil
+}
+
+// isLeafContent reports whether the character data at i is the only content of its element
+func isLeafContent(tokens []xml.Token, i int) bool {
+	if i == 0 || i+1 >= len(tokens) {
+		return false
+	}
+	_, afterStart := tokens[i-1].(xml.StartElement)
+	_, beforeEnd := tokens[i+1].(xml.EndElement)
+	return afterStart && beforeEnd
+}
+
+func rawName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// writeRawStartElement writes a start tag read with RawToken, leaving it unclosed
+func writeRawStartElement(builder *strings.Builder, start xml.StartElement) {
+	builder.WriteString("<" + rawName(start.Name))
+	for _, attr := range start.Attr {
+		builder.WriteString(" " + rawName(attr.Name) + `="` + escapeAttribute(attr.Value) + `"`)
+	}
+}
+
+// isXMLContentType reports whether parts of the content type hold XML markup
+func isXMLContentType(contentType string) bool {
+	return strings.HasSuffix(contentType, "+xml") || strings.HasSuffix(contentType, "/xml")
+}